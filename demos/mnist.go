@@ -0,0 +1,95 @@
+package demos
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gonn/internal/acti"
+	"gonn/internal/data"
+	"gonn/internal/layer"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/metrics"
+	"gonn/internal/optim"
+	"gonn/internal/train"
+	"gonn/internal/weightinit"
+)
+
+// MNIST training files (http://yann.lecun.com/exdb/mnist/), gzipped or raw
+const (
+	MNISTImages = "data/mnist/train-images-idx3-ubyte.gz"
+	MNISTLabels = "data/mnist/train-labels-idx1-ubyte.gz"
+)
+
+func MNISTDemo() {
+	fmt.Println("MNIST Demo")
+
+	accuracy, err := TrainMNIST(MNISTImages, MNISTLabels, 5)
+	if err != nil {
+		log.Fatalf("Failed to train on MNIST, reason { %s }", err)
+	}
+
+	fmt.Printf("Training accuracy: %.2f%%\n", 100*accuracy)
+}
+
+/*
+* Trains a 2 layer classifier, pixels -> 64 ReLU -> 10 logits, with softmax
+* cross entropy and Adam over shuffled mini-batches. Returns the accuracy on
+* the training set after the last epoch.
+**/
+func TrainMNIST(imagesPath, labelsPath string, epochs int) (float64, error) {
+	const (
+		SEED    = 1
+		CLASSES = 10
+		HIDDEN  = 64
+		BATCH   = 32
+		ALPHA   = 0.01
+	)
+	mat.Seed(SEED)
+
+	ds, err := data.LoadMNIST[float32](imagesPath, labelsPath, CLASSES)
+	if err != nil {
+		return 0, err
+	}
+
+	relu, err := layer.NewALByName[float32](acti.NameReLU)
+	if err != nil {
+		return 0, err
+	}
+	model := layer.NewSequential[float32](
+		layer.NewLLWithInit(uint64(ds.X.Rows()), HIDDEN, weightinit.HeNormal[float32], weightinit.Zeros[float32], nil),
+		relu,
+		layer.NewLLWithInit(HIDDEN, CLASSES, weightinit.XavierUniform[float32], weightinit.Zeros[float32], nil),
+	)
+
+	trainer, err := train.NewTrainer(model, lossfuncs.SoftmaxCrossEntropyLoss[float32](), optim.NewAdam[float32](ALPHA))
+	if err != nil {
+		return 0, err
+	}
+	trainer.BatchSize = BATCH
+	trainer.Prefetch = 2
+	trainer.Metrics = []train.Metric[float32]{metrics.NewAccuracy[float32]()}
+
+	var accuracy float64
+	trainer.Callbacks = []train.Callback{train.CallbackFuncs{
+		EpochEnd: func(epoch int, logs train.Logs) error {
+			full, err := trainer.Evaluate(context.Background(), ds)
+			if err != nil {
+				return err
+			}
+
+			accuracy = full["accuracy"]
+			fmt.Printf("Epoch[%d] CE: %f, accuracy: %.2f%%\n", epoch, full["loss"], 100*accuracy)
+			return nil
+		},
+	}}
+
+	fmt.Printf("%d images of %d pixels, %d batches per epoch\n", ds.Len(), ds.X.Rows(), (ds.Len()+BATCH-1)/BATCH)
+
+	if _, err := trainer.Fit(context.Background(), ds, epochs); err != nil {
+		return 0, err
+	}
+
+	return accuracy, nil
+}
@@ -3,12 +3,12 @@ package demos
 import (
 	"fmt"
 	"log"
-	"slices"
 
 	"gonn/internal/acti"
 	"gonn/internal/layer"
 	"gonn/internal/lossfuncs"
 	"gonn/internal/mat"
+	"gonn/internal/optim"
 )
 
 func PerceptronDemo() {
@@ -27,15 +27,21 @@ func PerceptronDemo() {
 		mat.RS{0, 4}, mat.CS{2, 3},
 	).TP()
 
-	modelLayers := createModel()
+	// same seed, same weights, same training run
+	const SEED = 1
+	mat.Seed(SEED)
+
+	model := NewXORModel()
 
 	fmt.Println("Perceptron Demo (XOR)")
 	fmt.Printf("X:\n%s\n", X.MustStringify())
 	fmt.Printf("y:\n%s\n", y.MustStringify())
 
 	const ALPHA = 0.1
+	optimizer := optim.NewSGD[float32](ALPHA)
+
 	for step := range 100000 {
-		y_, err := forward(modelLayers, X)
+		y_, err := model.Forward(X)
 		if err != nil {
 			log.Fatalf("Failed to forward model, reason = { %s }", err)
 		}
@@ -53,18 +59,18 @@ func PerceptronDemo() {
 		if err != nil {
 			log.Fatalf("Failed to get DSquaredError, reason { %s }", err)
 		}
-		_, err = backward(modelLayers, dse)
+		_, err = model.Backward(dse)
 		if err != nil {
 			log.Fatalf("Failed to get BackProp, reason { %s }", err)
 		}
 
-		err = updateWeights(modelLayers, ALPHA)
+		err = optimizer.Step(model.Params()...)
 		if err != nil {
 			log.Fatalf("Failed to update weights, reason { %s }", err)
 		}
 	}
 
-	y_, err := forward(modelLayers, X)
+	y_, err := model.Forward(X)
 	if err != nil {
 		log.Fatalf("Failed to forward model, reason = { %s }", err)
 	}
@@ -72,109 +78,22 @@ func PerceptronDemo() {
 	stats(X, y, y_)
 }
 
-func updateWeights(model []layer.Layer[float32], alpha float32) error {
-	if model == nil || len(model) == 0 {
-		return fmt.Errorf("nil or zero length model provided")
-	}
-
-	weightUpdater := func(weights, grad *mat.Mat2DF32) (*mat.Mat2DF32, error) {
-		err := weights.Subtract(grad.Scale(alpha))
-		if err != nil {
-			return nil, err
-		}
-		return weights, nil
-	}
-
-	for _, layer := range model {
-		learnable, _ := layer.IsLearnable()
-		if !learnable {
-			continue
-		}
-
-		err := layer.Learn(&weightUpdater)
+// 2-2-1 sigmoid network for the XOR table, activations by name so it can be saved with layer.Save
+func NewXORModel() *layer.Sequential[float32] {
+	sigmoid := func() *layer.ActivationLayer[float32] {
+		al, err := layer.NewALByName[float32](acti.NameSigmoid)
 		if err != nil {
-			return err
+			log.Fatalf("Failed to create sigmoid layer, reason { %s }", err)
 		}
+		return al
 	}
 
-	return nil
-}
-
-func backward(model []layer.Layer[float32], L *mat.Mat2DF32) ([]*mat.Mat2DF32, error) {
-	if model == nil || len(model) == 0 {
-		return nil, fmt.Errorf("nil or zero length model provided")
-	}
-	if L == nil || L.Cols() == 0 {
-		return nil, fmt.Errorf("L is nil or zero length")
-	}
-
-	var loss *mat.Mat2DF32 = L
-	gradients := make([](*mat.Mat2DF32), len(model))
-
-	for i := len(model) - 1; i >= 0; i-- {
-		layer := model[i]
-
-		grad, err := layer.Backward(loss)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"model backprop failed at layer[%d of %d], reason: { %s }",
-				i+1, len(model), err,
-			)
-		}
-
-		gradients[i] = grad
-		loss = grad
-	}
-
-	slices.Reverse(gradients)
-	return gradients, nil
-}
-
-func forward(model []layer.Layer[float32], X *mat.Mat2DF32) (*mat.Mat2DF32, error) {
-	if model == nil || len(model) == 0 {
-		return nil, fmt.Errorf("nil or zero length model provided")
-	}
-	if X == nil || X.Cols() == 0 {
-		return nil, fmt.Errorf("X is nil or zero length")
-	}
-
-	var inp, out *mat.Mat2DF32 = X, nil
-	var err error
-
-	for i, layer := range model {
-		out, err = layer.Forward(inp)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"model forwarding failed at layer[%d], reason: { %s }",
-				i, err,
-			)
-		}
-		inp = out
-	}
-
-	return out, nil
-}
-
-func createModel() []layer.Layer[float32] {
-	// Instantiate Activation Functions
-	sigmoid := acti.NewAF[float32](acti.Sigmoid)
-	dSigmoid := acti.NewAF[float32](acti.DSigmoid)
-
-	// Instantiate Layers
-	modelLayers := []layer.Layer[float32]{
+	return layer.NewSequential[float32](
 		layer.NewLL[float32](2, 2),
-		layer.NewAL(
-			sigmoid,
-			dSigmoid,
-		),
+		sigmoid(),
 		layer.NewLL[float32](2, 1),
-		layer.NewAL(
-			sigmoid,
-			dSigmoid,
-		),
-	}
-
-	return modelLayers
+		sigmoid(),
+	)
 }
 
 func stats(X, y, y_ *mat.Mat2DF32) {
@@ -1,10 +1,21 @@
 package acti
 
 import (
+	"fmt"
 	"gonn/internal/mat"
 	"math"
 )
 
+// Names of the activation functions known to ByName
+const (
+	NameLinear   = "linear"
+	NameReLU     = "relu"
+	NameLReLU    = "lrelu"
+	NameSigmoid  = "sigmoid"
+	NameSoftPlus = "softplus"
+	NameTanh     = "tanh"
+)
+
 func NewAF[T mat.Float](
 	af func(x T) T,
 ) *(func(x T) T) {
@@ -12,10 +23,38 @@ func NewAF[T mat.Float](
 	return f
 }
 
+/*
+* ByName
+*
+* Returns the activation function and its derivative registered under name,
+* giving activation layers an identity that survives serialization.
+**/
+func ByName[T mat.Float](name string) (af, daf *(func(x T) T), err error) {
+	switch name {
+	case NameLinear:
+		return NewAF[T](Linear), NewAF[T](DLinear), nil
+	case NameReLU:
+		return NewAF[T](ReLU), NewAF[T](DReLU), nil
+	case NameLReLU:
+		return NewAF[T](LReLU), NewAF[T](DLReLU), nil
+	case NameSigmoid:
+		return NewAF[T](Sigmoid), NewAF[T](DSigmoid), nil
+	case NameSoftPlus:
+		return NewAF[T](SoftPlus), NewAF[T](DSoftPlus), nil
+	case NameTanh:
+		return NewAF[T](Tanh), NewAF[T](DTanh), nil
+	}
+	return nil, nil, fmt.Errorf("Unknown activation function %q", name)
+}
+
 func Linear[T mat.Float](x T) T {
 	return x
 }
 
+func DLinear[T mat.Float](x T) T {
+	return 1
+}
+
 func ReLU[T mat.Float](x T) T {
 	if x > 0 {
 		return x
@@ -41,7 +80,7 @@ func DLReLU[T mat.Float](x T) T { // Leaky RELU
 	if x > 0 {
 		return 1
 	}
-	return -0.01
+	return 0.01
 }
 
 func Sigmoid[T mat.Float](x T) T {
@@ -55,11 +94,22 @@ func DSigmoid[T mat.Float](x T) T {
 	return sm * (1 - sm)
 }
 
+// log(1 + e^x), as max(x, 0) + log(1 + e^-|x|) so large x cannot overflow
 func SoftPlus[T mat.Float](x T) T {
-	sp := math.Log(1 + float64(x))
+	X := float64(x)
+	sp := math.Max(X, 0) + math.Log1p(math.Exp(-math.Abs(X)))
 	return T(sp)
 }
 
 func DSoftPlus[T mat.Float](x T) T {
 	return Sigmoid(x)
 }
+
+func Tanh[T mat.Float](x T) T {
+	return T(math.Tanh(float64(x)))
+}
+
+func DTanh[T mat.Float](x T) T {
+	th := Tanh(x)
+	return 1 - th*th
+}
@@ -0,0 +1,29 @@
+package acti
+
+import (
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* Softmax
+*
+* Column-wise over a batch x[features, N]:
+* softmax(x)[i, j] = exp(x[i, j] - max_j) / sum_k exp(x[k, j] - max_j)
+*
+* Subtracting the column max keeps exp from overflowing.
+**/
+func Softmax[T mat.Float](x *mat.Mat2D[T]) *mat.Mat2D[T] {
+	return LogSoftmax(x).Apply(func(v T) T {
+		return T(math.Exp(float64(v)))
+	})
+}
+
+/*
+* Log Softmax
+*
+* logsoftmax(x)[i, j] = x[i, j] - logsumexp(x[:, j]), see mat.Mat2D.LogSumExp
+**/
+func LogSoftmax[T mat.Float](x *mat.Mat2D[T]) *mat.Mat2D[T] {
+	return mat.MustSubtract(x, x.MustLogSumExp(mat.AxisRows, true))
+}
@@ -0,0 +1,222 @@
+package autograd
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+)
+
+/*
+* Variable
+*
+* A Variable wraps a mat.Mat2D and records the operation that produced it.
+* Operations in this package build a graph of Variables as they are evaluated,
+* calling Backward on the final (scalar) Variable walks that graph in reverse
+* and accumulates dL/dV into the Grad of every leaf that requires a gradient.
+**/
+type Variable[T mat.Float] struct {
+	Value *mat.Mat2D[T]
+	Grad  *mat.Mat2D[T] // accumulated gradient, only populated for leaves
+
+	requiresGrad bool
+	parents      []*Variable[T]
+
+	// given dL/dV, returns dL/dP for each parent P (nil where not required)
+	backward func(grad *mat.Mat2D[T]) ([]*mat.Mat2D[T], error)
+}
+
+// Constructors
+
+// leaf variable that will have its gradient populated by Backward
+func NewVar[T mat.Float](value *mat.Mat2D[T]) *Variable[T] {
+	return &Variable[T]{
+		Value:        value,
+		Grad:         nil,
+		requiresGrad: true,
+	}
+}
+
+// leaf variable that does not require a gradient (inputs, labels, ...)
+func NewConst[T mat.Float](value *mat.Mat2D[T]) *Variable[T] {
+	return &Variable[T]{
+		Value:        value,
+		Grad:         nil,
+		requiresGrad: false,
+	}
+}
+
+// End Constructors
+
+func (v *Variable[T]) RequiresGrad() bool {
+	return v.requiresGrad
+}
+
+func (v *Variable[T]) IsLeaf() bool {
+	return v.backward == nil
+}
+
+func (v *Variable[T]) Rows() int64 {
+	return v.Value.Rows()
+}
+
+func (v *Variable[T]) Cols() int64 {
+	return v.Value.Cols()
+}
+
+func (v *Variable[T]) ZeroGrad() {
+	v.Grad = nil
+}
+
+/*
+* Backward
+*
+* v must be a scalar [1, 1] variable (for example the output of Sum),
+* dL/dv is seeded with 1 and propagated to every leaf in the graph.
+* Gradients accumulate across calls, use ZeroGrad to reset a leaf.
+**/
+func (v *Variable[T]) Backward() error {
+	if v.Rows() != 1 || v.Cols() != 1 {
+		return fmt.Errorf(
+			"Failed to Variable::Backward, reason { %s }",
+			fmt.Sprintf(
+				"expected scalar variable [1, 1], found [%d, %d], use BackwardWith",
+				v.Rows(), v.Cols(),
+			),
+		)
+	}
+	return v.BackwardWith(mat.Ones[T](1, 1))
+}
+
+// Backward seeded with an explicit dL/dv of the same shape as v
+func (v *Variable[T]) BackwardWith(grad *mat.Mat2D[T]) error {
+	if grad == nil {
+		return fmt.Errorf("Failed to Variable::Backward, reason { nil gradient provided }")
+	}
+	if !mat.DimsMatch(v.Value, grad) {
+		return fmt.Errorf(
+			"Failed to Variable::Backward, reason { gradient [%d, %d] does not match variable [%d, %d] }",
+			grad.Rows(), grad.Cols(),
+			v.Rows(), v.Cols(),
+		)
+	}
+	if !v.requiresGrad {
+		return nil
+	}
+
+	order := v.topoSort()
+	grads := map[*Variable[T]]*mat.Mat2D[T]{
+		v: grad.Clone(),
+	}
+
+	// order is parents before children, walk it from the output back
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		g, ok := grads[node]
+		if !ok {
+			continue // does not contribute to the output
+		}
+		delete(grads, node)
+
+		if node.IsLeaf() {
+			if err := node.accumulate(g); err != nil {
+				return err
+			}
+			continue
+		}
+
+		parentGrads, err := node.backward(g)
+		if err != nil {
+			return fmt.Errorf("Failed to Variable::Backward, reason { %s }", err)
+		}
+
+		for j, parent := range node.parents {
+			pg := parentGrads[j]
+			if pg == nil || !parent.requiresGrad {
+				continue
+			}
+
+			if acc, ok := grads[parent]; ok {
+				if err := acc.Add(pg); err != nil {
+					return fmt.Errorf("Failed to Variable::Backward, reason { %s }", err)
+				}
+			} else {
+				grads[parent] = pg.Clone()
+			}
+		}
+	}
+
+	return nil
+}
+
+// vvv PRIVATE vvv
+
+func (v *Variable[T]) accumulate(g *mat.Mat2D[T]) error {
+	if v.Grad == nil {
+		v.Grad = g
+		return nil
+	}
+	if err := v.Grad.Add(g); err != nil {
+		return fmt.Errorf("Failed to accumulate gradient, reason { %s }", err)
+	}
+	return nil
+}
+
+// returns every variable reachable from v that requires a gradient,
+// ordered so that parents always come before their children
+func (v *Variable[T]) topoSort() []*Variable[T] {
+	order := []*Variable[T]{}
+	visited := map[*Variable[T]]bool{}
+
+	type frame struct {
+		node *Variable[T]
+		next int
+	}
+	stack := []frame{{node: v}}
+	visited[v] = true
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+
+		if top.next < len(top.node.parents) {
+			parent := top.node.parents[top.next]
+			top.next++
+
+			if parent.requiresGrad && !visited[parent] {
+				visited[parent] = true
+				stack = append(stack, frame{node: parent})
+			}
+			continue
+		}
+
+		order = append(order, top.node)
+		stack = stack[:len(stack)-1]
+	}
+
+	return order
+}
+
+func newOp[T mat.Float](
+	value *mat.Mat2D[T],
+	backward func(grad *mat.Mat2D[T]) ([]*mat.Mat2D[T], error),
+	parents ...*Variable[T],
+) *Variable[T] {
+	requiresGrad := false
+	for _, p := range parents {
+		requiresGrad = requiresGrad || p.requiresGrad
+	}
+
+	v := &Variable[T]{
+		Value:        value,
+		requiresGrad: requiresGrad,
+	}
+
+	if requiresGrad {
+		v.parents = parents
+		v.backward = backward
+	}
+
+	return v
+}
+
+func wrapOpErr(op string, err error) error {
+	return fmt.Errorf("Failed to autograd::%s, reason { %s }", op, err)
+}
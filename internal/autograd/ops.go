@@ -0,0 +1,227 @@
+package autograd
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+)
+
+/*
+* C[M, P] = A[M, N] * B[N, P]
+*
+* dL/dA = dL/dC * B^T
+* dL/dB = A^T * dL/dC
+**/
+func MatMul[T mat.Float](a, b *Variable[T]) (*Variable[T], error) {
+	value, err := mat.MatMul(a.Value, b.Value)
+	if err != nil {
+		return nil, wrapOpErr("MatMul", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		grads := make([]*mat.Mat2D[T], 2)
+		var err error
+
+		if a.requiresGrad {
+			if grads[0], err = mat.MatMul(g, b.Value.TP()); err != nil {
+				return nil, wrapOpErr("MatMul::Backward", err)
+			}
+		}
+		if b.requiresGrad {
+			if grads[1], err = mat.MatMul(a.Value.TP(), g); err != nil {
+				return nil, wrapOpErr("MatMul::Backward", err)
+			}
+		}
+		return grads, nil
+	}
+
+	return newOp(value, backward, a, b), nil
+}
+
+// Add, Subtract and Mul broadcast like their mat counterparts, the gradient of
+// a broadcast operand is summed over the axes it was repeated along
+
+func Add[T mat.Float](a, b *Variable[T]) (*Variable[T], error) {
+	value, err := mat.Add(a.Value, b.Value)
+	if err != nil {
+		return nil, wrapOpErr("Add", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		return []*mat.Mat2D[T]{
+			unbroadcast(g, a.Value),
+			unbroadcast(g, b.Value),
+		}, nil
+	}
+
+	return newOp(value, backward, a, b), nil
+}
+
+func Subtract[T mat.Float](a, b *Variable[T]) (*Variable[T], error) {
+	value, err := mat.Subtract(a.Value, b.Value)
+	if err != nil {
+		return nil, wrapOpErr("Subtract", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		return []*mat.Mat2D[T]{
+			unbroadcast(g, a.Value),
+			unbroadcast(g, b.Value).Clone().Scale(-1),
+		}, nil
+	}
+
+	return newOp(value, backward, a, b), nil
+}
+
+/*
+* Element-wise (Hadamard) product C = A (.) B
+*
+* dL/dA = dL/dC (.) B
+* dL/dB = dL/dC (.) A
+**/
+func Mul[T mat.Float](a, b *Variable[T]) (*Variable[T], error) {
+	value, err := mat.Mul(a.Value, b.Value)
+	if err != nil {
+		return nil, wrapOpErr("Mul", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		grads := make([]*mat.Mat2D[T], 2)
+		var err error
+
+		if a.requiresGrad {
+			if grads[0], err = mat.Mul(g, b.Value); err != nil {
+				return nil, wrapOpErr("Mul::Backward", err)
+			}
+			grads[0] = unbroadcast(grads[0], a.Value)
+		}
+		if b.requiresGrad {
+			if grads[1], err = mat.Mul(g, a.Value); err != nil {
+				return nil, wrapOpErr("Mul::Backward", err)
+			}
+			grads[1] = unbroadcast(grads[1], b.Value)
+		}
+		return grads, nil
+	}
+
+	return newOp(value, backward, a, b), nil
+}
+
+/*
+* Element-wise function C = f(A)
+*
+* df is the derivative of f, (see acti for pairs such as Sigmoid, DSigmoid)
+* dL/dA = dL/dC (.) df(A)
+**/
+func Apply[T mat.Float](a *Variable[T], f, df func(T) T) (*Variable[T], error) {
+	if f == nil || df == nil {
+		return nil, wrapOpErr("Apply", fmt.Errorf("nil function provided"))
+	}
+
+	value := a.Value.Clone().Apply(f)
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		grad, err := mat.Mul(g, a.Value.Clone().Apply(df))
+		if err != nil {
+			return nil, wrapOpErr("Apply::Backward", err)
+		}
+		return []*mat.Mat2D[T]{grad}, nil
+	}
+
+	return newOp(value, backward, a), nil
+}
+
+func VCat[T mat.Float](vars ...*Variable[T]) (*Variable[T], error) {
+	if len(vars) == 0 {
+		return nil, wrapOpErr("VCat", fmt.Errorf("no variables provided"))
+	}
+
+	values := make([]*mat.Mat2D[T], len(vars))
+	for i, v := range vars {
+		values[i] = v.Value
+	}
+
+	value, err := mat.VCat(values...)
+	if err != nil {
+		return nil, wrapOpErr("VCat", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		grads := make([]*mat.Mat2D[T], len(vars))
+
+		currRow := int64(0)
+		for i, v := range vars {
+			rows := v.Rows()
+
+			if v.requiresGrad {
+				grad, err := g.Slice(
+					mat.RS{currRow, currRow + rows},
+					mat.CS{0, g.Cols()},
+				)
+				if err != nil {
+					return nil, wrapOpErr("VCat::Backward", err)
+				}
+				grads[i] = grad
+			}
+
+			currRow += rows
+		}
+		return grads, nil
+	}
+
+	return newOp(value, backward, vars...), nil
+}
+
+func Slice[T mat.Float](a *Variable[T], rs, cs mat.SliceRange) (*Variable[T], error) {
+	value, err := a.Value.Slice(rs, cs)
+	if err != nil {
+		return nil, wrapOpErr("Slice", err)
+	}
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		// scatter g back into a zero matrix of the parent's shape
+		grad := mat.New2D[T](uint64(a.Rows()), uint64(a.Cols()))
+
+		view, err := grad.Slice(rs, cs)
+		if err != nil {
+			return nil, wrapOpErr("Slice::Backward", err)
+		}
+		if err := view.Add(g); err != nil {
+			return nil, wrapOpErr("Slice::Backward", err)
+		}
+
+		return []*mat.Mat2D[T]{grad}, nil
+	}
+
+	return newOp(value, backward, a), nil
+}
+
+// Sum of all elements, returned as a [1, 1] variable
+func Sum[T mat.Float](a *Variable[T]) *Variable[T] {
+	value := mat.FromValues([]T{a.Value.Sum()})
+
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		grad := mat.Ones[T](uint64(a.Rows()), uint64(a.Cols())).Scale(g.MustGet(0, 0))
+		return []*mat.Mat2D[T]{grad}, nil
+	}
+
+	return newOp(value, backward, a)
+}
+
+func TP[T mat.Float](a *Variable[T]) *Variable[T] {
+	backward := func(g *mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+		return []*mat.Mat2D[T]{g.TP()}, nil
+	}
+
+	return newOp(a.Value.TP(), backward, a)
+}
+
+// sums g over the axes along which shape was broadcast
+func unbroadcast[T mat.Float](g, shape *mat.Mat2D[T]) *mat.Mat2D[T] {
+	if shape.Rows() == 1 && g.Rows() != 1 {
+		g = g.SumRows()
+	}
+	if shape.Cols() == 1 && g.Cols() != 1 {
+		g = g.SumCols()
+	}
+	return g
+}
@@ -0,0 +1,355 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"gonn/internal/mat"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type HeaderMode int
+
+const (
+	HeaderAuto    HeaderMode = iota // header if no cell of the first row is a number
+	HeaderPresent                   // the first row names the columns
+	HeaderAbsent                    // columns are named by index, "0", "1", ...
+)
+
+type MissingPolicy int
+
+const (
+	MissingError MissingPolicy = iota // fail on the first missing cell
+	MissingDrop                       // skip rows with a missing cell in a selected column
+	MissingZero                       // 0, or no category (an all zero one-hot)
+	MissingMean                       // column mean, or the most frequent category
+)
+
+// a CSV column, by header name or by 0-based index
+type ColumnRef struct {
+	name  string
+	index int
+}
+
+func ByName(name string) ColumnRef {
+	return ColumnRef{name: name, index: -1}
+}
+
+func ByIndex(index int) ColumnRef {
+	return ColumnRef{index: index}
+}
+
+func (c ColumnRef) String() string {
+	if c.index < 0 {
+		return strconv.Quote(c.name)
+	}
+	return fmt.Sprintf("#%d", c.index)
+}
+
+/*
+* CSV Options
+*
+* Features defaults to every column that is not a label. Columns with any
+* non numeric value are one-hot encoded automatically, Categorical forces
+* numeric codes to be treated the same way. Categories are ordered by value.
+**/
+type CSVOptions struct {
+	Header HeaderMode
+	Comma  rune // default ','
+
+	Features    []ColumnRef
+	Labels      []ColumnRef
+	Categorical []ColumnRef
+
+	Missing       MissingPolicy
+	MissingValues []string // default "", "NA", "N/A", "NaN", "null", "?"
+}
+
+/*
+* CSV Data
+*
+* X[features, N] and Y[labels, N] (nil without label columns), one column per
+* kept CSV row. A numeric column becomes one row, a categorical column one
+* row per category, named "column=category" in FeatureNames/LabelNames.
+**/
+type CSVData[T mat.Float] struct {
+	X, Y *mat.Mat2D[T]
+
+	FeatureNames []string
+	LabelNames   []string
+	Categories   map[string][]string // categorical column name -> categories in one-hot order
+}
+
+func ReadCSV[T mat.Float](r io.Reader, opts CSVOptions) (*CSVData[T], error) {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, wrapCSVErr(err)
+	}
+	if len(records) == 0 {
+		return nil, wrapCSVErr(fmt.Errorf("empty file"))
+	}
+
+	missing := opts.MissingValues
+	if missing == nil {
+		missing = []string{"", "NA", "N/A", "NaN", "null", "?"}
+	}
+	isMissing := func(cell string) bool {
+		return slices.Contains(missing, strings.TrimSpace(cell))
+	}
+
+	names := make([]string, len(records[0]))
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	if hasHeader(records[0], opts.Header, isMissing) {
+		for i, name := range records[0] {
+			names[i] = strings.TrimSpace(name)
+		}
+		records = records[1:]
+	}
+
+	labels, err := resolveColumns(opts.Labels, names)
+	if err != nil {
+		return nil, wrapCSVErr(err)
+	}
+	features, err := resolveColumns(opts.Features, names)
+	if err != nil {
+		return nil, wrapCSVErr(err)
+	}
+	if opts.Features == nil {
+		features = nil
+		for i := range names {
+			if !slices.Contains(labels, i) {
+				features = append(features, i)
+			}
+		}
+	}
+	categorical, err := resolveColumns(opts.Categorical, names)
+	if err != nil {
+		return nil, wrapCSVErr(err)
+	}
+
+	// rows to keep
+	selected := append(slices.Clone(features), labels...)
+	rows := make([][]string, 0, len(records))
+	for r, record := range records {
+		drop := false
+		for _, c := range selected {
+			if !isMissing(record[c]) {
+				continue
+			}
+			switch opts.Missing {
+			case MissingError:
+				return nil, wrapCSVErr(fmt.Errorf("missing value in row %d, column %q", r+1, names[c]))
+			case MissingDrop:
+				drop = true
+			}
+		}
+		if !drop {
+			rows = append(rows, record)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, wrapCSVErr(fmt.Errorf("no rows left"))
+	}
+
+	res := &CSVData[T]{Categories: map[string][]string{}}
+
+	encode := func(cols []int) (*mat.Mat2D[T], []string, error) {
+		var encoded []encodedColumn
+		var rowNames []string
+		for _, c := range cols {
+			col, err := encodeColumn(rows, c, names[c], slices.Contains(categorical, c), opts.Missing, isMissing)
+			if err != nil {
+				return nil, nil, err
+			}
+			if col.categories != nil {
+				res.Categories[names[c]] = col.categories
+			}
+			encoded = append(encoded, col)
+			rowNames = append(rowNames, col.names...)
+		}
+
+		m := mat.New2D[T](uint64(len(rowNames)), uint64(len(rows)))
+		i := int64(0)
+		for _, col := range encoded {
+			for _, values := range col.values {
+				for j, v := range values {
+					m.MustSet(i, int64(j), T(v))
+				}
+				i++
+			}
+		}
+		return m, rowNames, nil
+	}
+
+	if res.X, res.FeatureNames, err = encode(features); err != nil {
+		return nil, wrapCSVErr(err)
+	}
+	if len(labels) > 0 {
+		if res.Y, res.LabelNames, err = encode(labels); err != nil {
+			return nil, wrapCSVErr(err)
+		}
+	}
+
+	return res, nil
+}
+
+func LoadCSV[T mat.Float](path string, opts CSVOptions) (*CSVData[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, wrapCSVErr(err)
+	}
+	defer f.Close()
+
+	return ReadCSV[T](f, opts)
+}
+
+// the features and labels as a Dataset for a DataLoader
+func (d *CSVData[T]) Dataset() (*MatDataset[T], error) {
+	if d.Y == nil {
+		return nil, fmt.Errorf("Failed to create MatDataset, reason { CSV has no label columns }")
+	}
+	return NewMatDataset(d.X, d.Y)
+}
+
+// ## private ##
+
+type encodedColumn struct {
+	names      []string    // one per output row
+	values     [][]float64 // one slice per output row, one value per CSV row
+	categories []string    // nil for numeric columns
+}
+
+func encodeColumn(
+	rows [][]string, c int, name string, forceCategorical bool,
+	policy MissingPolicy, isMissing func(string) bool,
+) (encodedColumn, error) {
+	numbers := make([]float64, len(rows))
+	present := make([]bool, len(rows))
+	categorical := forceCategorical
+
+	for r, row := range rows {
+		if isMissing(row[c]) {
+			continue
+		}
+		present[r] = true
+		if v, err := strconv.ParseFloat(strings.TrimSpace(row[c]), 64); err == nil {
+			numbers[r] = v
+		} else {
+			categorical = true
+		}
+	}
+
+	if !categorical {
+		fill := 0.0
+		if policy == MissingMean {
+			count := 0
+			for r, v := range numbers {
+				if present[r] {
+					fill += v
+					count++
+				}
+			}
+			if count > 0 {
+				fill /= float64(count)
+			}
+		}
+		for r := range numbers {
+			if !present[r] {
+				numbers[r] = fill
+			}
+		}
+		return encodedColumn{names: []string{name}, values: [][]float64{numbers}}, nil
+	}
+
+	counts := map[string]int{}
+	for r, row := range rows {
+		if present[r] {
+			counts[strings.TrimSpace(row[c])]++
+		}
+	}
+	if len(counts) == 0 {
+		return encodedColumn{}, fmt.Errorf("categorical column %q has no values", name)
+	}
+
+	categories := make([]string, 0, len(counts))
+	for category := range counts {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+
+	// the most frequent category, first by order on ties
+	fill := ""
+	if policy == MissingMean {
+		for _, category := range categories {
+			if counts[category] > counts[fill] {
+				fill = category
+			}
+		}
+	}
+
+	col := encodedColumn{categories: categories}
+	for k, category := range categories {
+		col.names = append(col.names, name+"="+category)
+		col.values = append(col.values, make([]float64, len(rows)))
+
+		for r, row := range rows {
+			value := fill
+			if present[r] {
+				value = strings.TrimSpace(row[c])
+			}
+			if value == category {
+				col.values[k][r] = 1
+			}
+		}
+	}
+
+	return col, nil
+}
+
+func hasHeader(first []string, mode HeaderMode, isMissing func(string) bool) bool {
+	switch mode {
+	case HeaderPresent:
+		return true
+	case HeaderAbsent:
+		return false
+	}
+
+	for _, cell := range first {
+		if isMissing(cell) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(cell), 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func resolveColumns(refs []ColumnRef, names []string) ([]int, error) {
+	indexes := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		index := ref.index
+		if index < 0 {
+			index = slices.Index(names, ref.name)
+		}
+		if index < 0 || index >= len(names) {
+			return nil, fmt.Errorf("unknown column %s", ref)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func wrapCSVErr(err error) error {
+	return fmt.Errorf("Failed to read CSV, reason { %s }", err)
+}
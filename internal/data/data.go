@@ -0,0 +1,86 @@
+package data
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+	"math/rand"
+)
+
+/*
+* Dataset
+*
+* Indexed samples, Get returns the features and label of sample i as
+* columns x[features, 1] and y[labels, 1], so a DataLoader can HCat them into
+* the [features, N] batches layers expect.
+**/
+type Dataset[T mat.Float] interface {
+	Len() int
+	Get(i int) (x, y *mat.Mat2D[T], err error)
+}
+
+// A Dataset over in memory X[features, N] and Y[labels, N], sample i is column i
+type MatDataset[T mat.Float] struct {
+	X, Y *mat.Mat2D[T]
+}
+
+func NewMatDataset[T mat.Float](X, Y *mat.Mat2D[T]) (*MatDataset[T], error) {
+	if X == nil || Y == nil || X.Cols() != Y.Cols() {
+		return nil, fmt.Errorf("Failed to create MatDataset, reason { X and Y must have one column per sample }")
+	}
+	return &MatDataset[T]{X: X, Y: Y}, nil
+}
+
+func (ds *MatDataset[T]) Len() int {
+	return int(ds.X.Cols())
+}
+
+func (ds *MatDataset[T]) Get(i int) (x, y *mat.Mat2D[T], err error) {
+	if i < 0 || i >= ds.Len() {
+		return nil, nil, fmt.Errorf("Index %d out of range [0, %d)", i, ds.Len())
+	}
+
+	col := mat.CS{int64(i), int64(i) + 1}
+	return ds.X.MustSlice(mat.RS{0, ds.X.Rows()}, col), ds.Y.MustSlice(mat.RS{0, ds.Y.Rows()}, col), nil
+}
+
+// The samples of Dataset at Indexes, in that order
+type Subset[T mat.Float] struct {
+	Dataset Dataset[T]
+	Indexes []int
+}
+
+func (s *Subset[T]) Len() int {
+	return len(s.Indexes)
+}
+
+func (s *Subset[T]) Get(i int) (x, y *mat.Mat2D[T], err error) {
+	if i < 0 || i >= s.Len() {
+		return nil, nil, fmt.Errorf("Index %d out of range [0, %d)", i, s.Len())
+	}
+	return s.Dataset.Get(s.Indexes[i])
+}
+
+/*
+* Randomly splits ds into disjoint train and validation subsets, validation
+* holding round(fraction * Len) samples. Draws from rng, nil for
+* mat.DefaultRNG. Both subsets must be non empty.
+**/
+func Split[T mat.Float](ds Dataset[T], fraction float64, rng *rand.Rand) (train, validation *Subset[T], err error) {
+	if ds == nil || !(fraction > 0 && fraction < 1) {
+		return nil, nil, fmt.Errorf("Failed to Split, reason { need a Dataset and a fraction in (0, 1), found %v }", fraction)
+	}
+
+	n := ds.Len()
+	nVal := int(math.Round(fraction * float64(n)))
+	if nVal == 0 || nVal == n {
+		return nil, nil, fmt.Errorf(
+			"Failed to Split, reason { fraction %v of %d samples leaves an empty subset }", fraction, n,
+		)
+	}
+
+	order := mat.Perm(rng, n)
+	return &Subset[T]{Dataset: ds, Indexes: order[:n-nVal]},
+		&Subset[T]{Dataset: ds, Indexes: order[n-nVal:]},
+		nil
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"gonn/internal/mat"
+	"io"
+	"math"
+	"os"
+)
+
+/*
+* IDX format (MNIST), big endian
+*
+*	magic   [4]byte  0, 0, dtype, ndims
+*	dims    ndims x uint32
+*	values  product(dims) x dtype
+*
+* dtype is one of 0x08 uint8, 0x09 int8, 0x0B int16, 0x0C int32,
+* 0x0D float32 and 0x0E float64. Gzipped files are detected and
+* decompressed transparently.
+**/
+const (
+	idxUint8   = 0x08
+	idxInt8    = 0x09
+	idxInt16   = 0x0B
+	idxInt32   = 0x0C
+	idxFloat32 = 0x0D
+	idxFloat64 = 0x0E
+
+	maxIDXValues = 1 << 30 // refuse absurd headers before allocating
+)
+
+// reads an IDX file into a tensor of its dims, values converted to T
+func ReadIDX[T mat.Float](r io.Reader) (*mat.Tensor[T], error) {
+	br := bufio.NewReader(r)
+
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, wrapIDXErr(err)
+		}
+		defer gz.Close()
+		br = bufio.NewReader(gz)
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return nil, wrapIDXErr(err)
+	}
+	if magic[0] != 0 || magic[1] != 0 {
+		return nil, wrapIDXErr(fmt.Errorf("bad magic %x", magic))
+	}
+
+	dtype, ndims := magic[2], int(magic[3])
+	size, ok := idxSize(dtype)
+	if !ok {
+		return nil, wrapIDXErr(fmt.Errorf("unknown dtype 0x%02x", dtype))
+	}
+
+	shape := make([]uint64, ndims)
+	count := uint64(1)
+	for i := range shape {
+		var dim uint32
+		if err := binary.Read(br, binary.BigEndian, &dim); err != nil {
+			return nil, wrapIDXErr(err)
+		}
+		shape[i] = uint64(dim)
+		count *= shape[i]
+		if count > maxIDXValues {
+			return nil, wrapIDXErr(fmt.Errorf("dims %v too large", shape[:i+1]))
+		}
+	}
+
+	raw := make([]byte, count*size)
+	if _, err := io.ReadFull(br, raw); err != nil {
+		return nil, wrapIDXErr(fmt.Errorf("truncated values, %s", err))
+	}
+
+	values := make([]T, count)
+	for i := range values {
+		b := raw[uint64(i)*size:]
+		switch dtype {
+		case idxUint8:
+			values[i] = T(b[0])
+		case idxInt8:
+			values[i] = T(int8(b[0]))
+		case idxInt16:
+			values[i] = T(int16(binary.BigEndian.Uint16(b)))
+		case idxInt32:
+			values[i] = T(int32(binary.BigEndian.Uint32(b)))
+		case idxFloat32:
+			values[i] = T(math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case idxFloat64:
+			values[i] = T(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		}
+	}
+
+	tensor, err := mat.TensorFromValues(values, shape...)
+	if err != nil {
+		return nil, wrapIDXErr(err)
+	}
+	return tensor, nil
+}
+
+func LoadIDX[T mat.Float](path string) (*mat.Tensor[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, wrapIDXErr(err)
+	}
+	defer f.Close()
+
+	return ReadIDX[T](f)
+}
+
+/*
+* MNIST
+*
+* Images [N, rows, cols] of bytes become X[rows*cols, N] scaled to [0, 1],
+* labels [N] become one-hot Y[classes, N].
+**/
+func LoadMNIST[T mat.Float](imagesPath, labelsPath string, classes uint64) (*MatDataset[T], error) {
+	images, err := LoadIDX[T](imagesPath)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := LoadIDX[T](labelsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return MNISTDataset(images, labels, classes)
+}
+
+func MNISTDataset[T mat.Float](images, labels *mat.Tensor[T], classes uint64) (*MatDataset[T], error) {
+	if images.Rank() < 2 || labels.Rank() != 1 || images.Shape()[0] != labels.Shape()[0] {
+		return nil, fmt.Errorf(
+			"Failed to create MNIST dataset, reason { images%v do not match labels%v }",
+			images.Shape(), labels.Shape(),
+		)
+	}
+
+	N := images.Shape()[0]
+	X, err := images.Reshape(N, images.Size()/max(N, 1))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create MNIST dataset, reason { %s }", err)
+	}
+	pixels := X.MustToMat2D().TP().Clone().Scale(1.0 / 255)
+
+	Y := mat.New2D[T](classes, N)
+	for j := range int64(N) {
+		label := labels.MustGet(j)
+		if label < 0 || uint64(label) >= classes || label != T(math.Trunc(float64(label))) {
+			return nil, fmt.Errorf(
+				"Failed to create MNIST dataset, reason { label %v of image %d outside [0, %d) }",
+				label, j, classes,
+			)
+		}
+		Y.MustSet(int64(label), j, 1)
+	}
+
+	return NewMatDataset(pixels, Y)
+}
+
+// ## private ##
+
+func idxSize(dtype byte) (uint64, bool) {
+	switch dtype {
+	case idxUint8, idxInt8:
+		return 1, true
+	case idxInt16:
+		return 2, true
+	case idxInt32, idxFloat32:
+		return 4, true
+	case idxFloat64:
+		return 8, true
+	}
+	return 0, false
+}
+
+func wrapIDXErr(err error) error {
+	return fmt.Errorf("Failed to read IDX, reason { %s }", err)
+}
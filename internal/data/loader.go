@@ -0,0 +1,203 @@
+package data
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math/rand"
+)
+
+/*
+* DataLoader
+*
+* Splits a Dataset into mini-batches X[features, BatchSize], Y[labels, BatchSize].
+* Each Epoch visits every sample once, in a fresh random order if Shuffle
+* (drawn from RNG, nil for mat.DefaultRNG). The last batch holds the remainder
+* unless DropLast. With Prefetch > 0 up to that many batches are collated
+* ahead in a background goroutine.
+*
+*	it := loader.Epoch()
+*	defer it.Close()
+*	for it.Next() {
+*		X, Y := it.Batch()
+*		...
+*	}
+*	if err := it.Err(); err != nil { ... }
+**/
+type DataLoader[T mat.Float] struct {
+	Dataset   Dataset[T]
+	BatchSize int
+	Shuffle   bool
+	DropLast  bool
+	Prefetch  int
+	RNG       *rand.Rand
+}
+
+func NewDataLoader[T mat.Float](ds Dataset[T], batchSize int) (*DataLoader[T], error) {
+	if ds == nil || batchSize <= 0 {
+		return nil, fmt.Errorf("Failed to create DataLoader, reason { need a Dataset and a positive batch size }")
+	}
+
+	return &DataLoader[T]{
+		Dataset:   ds,
+		BatchSize: batchSize,
+		Shuffle:   true,
+		DropLast:  false,
+		Prefetch:  0,
+		RNG:       nil,
+	}, nil
+}
+
+// number of batches in an epoch
+func (dl *DataLoader[T]) Batches() int {
+	n := dl.Dataset.Len()
+	if dl.DropLast {
+		return n / dl.BatchSize
+	}
+	return (n + dl.BatchSize - 1) / dl.BatchSize
+}
+
+func (dl *DataLoader[T]) Epoch() *BatchIter[T] {
+	order := make([]int, dl.Dataset.Len())
+	if dl.Shuffle {
+		order = mat.Perm(dl.RNG, len(order))
+	} else {
+		for i := range order {
+			order[i] = i
+		}
+	}
+
+	it := &BatchIter[T]{
+		loader: dl,
+		order:  order,
+		done:   make(chan struct{}),
+	}
+
+	if dl.Prefetch > 0 {
+		it.batches = make(chan batch[T], dl.Prefetch)
+		go it.prefetch()
+	}
+
+	return it
+}
+
+// Iterates the batches of one epoch, see DataLoader
+type BatchIter[T mat.Float] struct {
+	loader *DataLoader[T]
+	order  []int
+
+	next    int // index of the next batch to collate
+	current batch[T]
+	err     error
+
+	batches chan batch[T] // nil without prefetching
+	done    chan struct{}
+	closed  bool
+}
+
+type batch[T mat.Float] struct {
+	X, Y *mat.Mat2D[T]
+	err  error
+}
+
+// advances to the next batch, false at the end of the epoch or on error
+func (it *BatchIter[T]) Next() bool {
+	if it.err != nil || it.closed {
+		return false
+	}
+
+	var b batch[T]
+	var ok bool
+	if it.batches != nil {
+		b, ok = <-it.batches
+	} else {
+		b, ok = it.collate()
+	}
+
+	if !ok {
+		return false
+	}
+	if b.err != nil {
+		it.err = b.err
+		return false
+	}
+
+	it.current = b
+	return true
+}
+
+func (it *BatchIter[T]) Batch() (X, Y *mat.Mat2D[T]) {
+	return it.current.X, it.current.Y
+}
+
+func (it *BatchIter[T]) Err() error {
+	return it.err
+}
+
+// stops the prefetching goroutine when leaving an epoch early, safe to call more than once
+func (it *BatchIter[T]) Close() {
+	if it.closed {
+		return
+	}
+	it.closed = true
+	close(it.done)
+
+	if it.batches != nil {
+		for range it.batches { // let the producer finish
+		}
+	}
+}
+
+// ## private ##
+
+func (it *BatchIter[T]) prefetch() {
+	defer close(it.batches)
+
+	for {
+		b, ok := it.collate()
+		if !ok {
+			return
+		}
+
+		select {
+		case it.batches <- b:
+		case <-it.done:
+			return
+		}
+		if b.err != nil {
+			return
+		}
+	}
+}
+
+// collates the next batch, false once the epoch is exhausted
+func (it *BatchIter[T]) collate() (batch[T], bool) {
+	size, n := it.loader.BatchSize, len(it.order)
+
+	start := it.next * size
+	if start >= n || (it.loader.DropLast && start+size > n) {
+		return batch[T]{}, false
+	}
+	end := min(start+size, n)
+	it.next++
+
+	xs := make([]*mat.Mat2D[T], 0, end-start)
+	ys := make([]*mat.Mat2D[T], 0, end-start)
+	for _, i := range it.order[start:end] {
+		x, y, err := it.loader.Dataset.Get(i)
+		if err != nil {
+			return batch[T]{err: fmt.Errorf("Failed to load sample %d, reason { %s }", i, err)}, true
+		}
+		xs, ys = append(xs, x), append(ys, y)
+	}
+
+	X, err := mat.HCat(xs...)
+	if err != nil {
+		return batch[T]{err: fmt.Errorf("Failed to collate features, reason { %s }", err)}, true
+	}
+	Y, err := mat.HCat(ys...)
+	if err != nil {
+		return batch[T]{err: fmt.Errorf("Failed to collate labels, reason { %s }", err)}, true
+	}
+
+	return batch[T]{X: X, Y: Y}, true
+}
@@ -0,0 +1,274 @@
+package gradcheck
+
+import (
+	"fmt"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+	"strings"
+)
+
+/*
+* Gradient Check
+*
+* Compares a layer's hand-written gradients against central finite
+* differences of the scalar
+*
+*	L(x, W) = sum(Forward(x) (.) R)
+*
+* for a fixed pseudo random R, so that dL/dOut = R is what Backward gets:
+*
+*	numeric[i, j] = (L(m[i, j] + Eps) - L(m[i, j] - Eps)) / (2 * Eps)
+*
+* for every element of the input x (against Backward's result) and of every
+* learnable param's weights (against the gradient IsLearnable reports).
+* Weights are read and perturbed through Learn, so any Layer works without
+* knowing its fields, containers are checked through their Params.
+*
+* Layers must be deterministic for this to hold, put Dropout in inference
+* mode first. Inputs near a kink (ReLU at 0, max pooling ties) can fail
+* legitimately.
+**/
+type Options struct {
+	Eps     float64 // finite difference step
+	Tol     float64 // largest accepted relative error
+	Weights bool    // also check the learnable weights
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Eps:     1e-6,
+		Tol:     1e-5,
+		Weights: true,
+	}
+}
+
+/*
+* One checked element, Param is "x" for the input or "param[k]" for the
+* weights of the kth learnable param. The relative error is
+*
+*	|Numeric - Analytic| / max(1, |Numeric|, |Analytic|)
+*
+* so it is an absolute error for gradients smaller than 1.
+**/
+type Element struct {
+	Param    string
+	Row, Col int64
+	Numeric  float64
+	Analytic float64
+	RelErr   float64
+}
+
+func (e Element) String() string {
+	return fmt.Sprintf(
+		"%s[%d, %d]: numeric %g, analytic %g, relative error %.3g",
+		e.Param, e.Row, e.Col, e.Numeric, e.Analytic, e.RelErr,
+	)
+}
+
+// Every checked element, in order: x then each param row major
+type Report struct {
+	Tol      float64
+	Elements []Element
+}
+
+func (r *Report) MaxRelErr() float64 {
+	worst := 0.0
+	for _, e := range r.Elements {
+		worst = max(worst, e.RelErr)
+	}
+	return worst
+}
+
+// the elements over Tol
+func (r *Report) Failures() []Element {
+	failures := []Element{}
+	for _, e := range r.Elements {
+		if !(e.RelErr <= r.Tol) { // NaN fails too
+			failures = append(failures, e)
+		}
+	}
+	return failures
+}
+
+func (r *Report) OK() bool {
+	return len(r.Failures()) == 0
+}
+
+func (r *Report) String() string {
+	failures := r.Failures()
+	lines := []string{fmt.Sprintf(
+		"%d of %d elements over relative error %g, max %.3g",
+		len(failures), len(r.Elements), r.Tol, r.MaxRelErr(),
+	)}
+	for _, e := range failures {
+		lines = append(lines, "\t"+e.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Checks l at x, x is perturbed in place and restored. See Options
+func Check(l layer.Layer[float64], x *mat.Mat2DF64, opts Options) (*Report, error) {
+	if l == nil || x == nil {
+		return nil, fmt.Errorf("Failed to gradcheck, reason { nil layer or input }")
+	}
+	if !(opts.Eps > 0) {
+		return nil, fmt.Errorf("Failed to gradcheck, reason { non positive Eps %g }", opts.Eps)
+	}
+
+	out, err := l.Forward(x)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	R := probe(out.Rows(), out.Cols())
+
+	dx, err := l.Backward(R)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	loss := func() (float64, error) {
+		o, err := l.Forward(x)
+		if err != nil {
+			return 0, err
+		}
+		prod, err := mat.Mul(o, R)
+		if err != nil {
+			return 0, err
+		}
+		return prod.Sum(), nil
+	}
+
+	report := &Report{Tol: opts.Tol, Elements: []Element{}}
+
+	var params []layer.Learnable[float64]
+	var grads []*mat.Mat2DF64
+	if opts.Weights {
+		// gradients are read before any perturbation runs Forward again
+		params = learnables(l)
+		for k, p := range params {
+			_, grad := p.IsLearnable()
+			if grad == nil {
+				return nil, wrapErr(fmt.Errorf("param[%d] is learnable but has no gradient after Backward", k))
+			}
+			grads = append(grads, grad.Clone())
+		}
+	}
+
+	err = checkElements(report, "x", x, dx, opts.Eps, loss, func() error { return nil })
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	for k, p := range params {
+		W, err := weightsOf(p)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+
+		name := fmt.Sprintf("param[%d]", k)
+		err = checkElements(report, name, W, grads[k], opts.Eps, loss, func() error { return setWeights(p, W) })
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+	}
+
+	return report, nil
+}
+
+// ## private ##
+
+/*
+* perturbs every element of m by +-eps, calling apply after each change so
+* the layer sees it, and records the finite difference against grad
+**/
+func checkElements(
+	report *Report, name string, m, grad *mat.Mat2DF64, eps float64,
+	loss func() (float64, error), apply func() error,
+) error {
+	if grad == nil || !mat.DimsMatch(m, grad) {
+		return fmt.Errorf("%s gradient does not match the dims of %s[%d, %d]", name, name, m.Rows(), m.Cols())
+	}
+
+	lossAt := func(i, j int64, v float64) (float64, error) {
+		m.MustSet(i, j, v)
+		if err := apply(); err != nil {
+			return 0, err
+		}
+		return loss()
+	}
+
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			orig := m.MustGet(i, j)
+
+			lp, err := lossAt(i, j, orig+eps)
+			if err != nil {
+				return err
+			}
+			lm, err := lossAt(i, j, orig-eps)
+			if err != nil {
+				return err
+			}
+			m.MustSet(i, j, orig)
+			if err := apply(); err != nil {
+				return err
+			}
+
+			numeric := (lp - lm) / (2 * eps)
+			analytic := grad.MustGet(i, j)
+			report.Elements = append(report.Elements, Element{
+				Param:    name,
+				Row:      i,
+				Col:      j,
+				Numeric:  numeric,
+				Analytic: analytic,
+				RelErr:   math.Abs(numeric-analytic) / max(1, math.Abs(numeric), math.Abs(analytic)),
+			})
+		}
+	}
+
+	return nil
+}
+
+// the layers holding weights, the children of containers
+func learnables(l layer.Layer[float64]) []layer.Learnable[float64] {
+	if c, ok := l.(layer.Container[float64]); ok {
+		return c.Params()
+	}
+	if learnable, _ := l.IsLearnable(); learnable {
+		return []layer.Learnable[float64]{l}
+	}
+	return nil
+}
+
+// a copy of p's weights, as Learn hands them to the update
+func weightsOf(p layer.Learnable[float64]) (*mat.Mat2DF64, error) {
+	var W *mat.Mat2DF64
+	read := func(weights, grad *mat.Mat2DF64) (*mat.Mat2DF64, error) {
+		W = weights
+		return weights.Clone(), nil
+	}
+	if err := p.Learn(&read); err != nil {
+		return nil, err
+	}
+	return W, nil
+}
+
+func setWeights(p layer.Learnable[float64], W *mat.Mat2DF64) error {
+	write := func(weights, grad *mat.Mat2DF64) (*mat.Mat2DF64, error) {
+		return W.Clone(), nil
+	}
+	return p.Learn(&write)
+}
+
+// R[rows, cols] = cos(0.37 * k) for the kth element, fixed and without structure a bug could hide in
+func probe(rows, cols int64) *mat.Mat2DF64 {
+	return mat.ARange[float64](uint64(rows*cols)).
+		MustReshape(uint64(rows), uint64(cols)).
+		Apply(func(v float64) float64 { return math.Cos(v * 0.37) })
+}
+
+func wrapErr(err error) error {
+	return fmt.Errorf("Failed to gradcheck, reason { %s }", err)
+}
@@ -0,0 +1,29 @@
+package gradcheck
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"testing"
+)
+
+/*
+* Fails tb with every element whose gradient is off, for use in tests:
+*
+*	gradcheck.Expect(t, layer.NewLL[float64](3, 2), mat.RandF64(3, 4))
+**/
+func Expect(tb testing.TB, l layer.Layer[float64], x *mat.Mat2DF64) {
+	tb.Helper()
+	ExpectWith(tb, l, x, DefaultOptions())
+}
+
+func ExpectWith(tb testing.TB, l layer.Layer[float64], x *mat.Mat2DF64, opts Options) {
+	tb.Helper()
+
+	report, err := Check(l, x, opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, e := range report.Failures() {
+		tb.Errorf("%s", e)
+	}
+}
@@ -2,6 +2,7 @@ package layer
 
 import (
 	"fmt"
+	"gonn/internal/acti"
 	"gonn/internal/mat"
 )
 
@@ -10,6 +11,8 @@ type ActivationLayer[T mat.Float] struct {
 
 	AF  *(func(X T) T) // activation function
 	DAF *(func(X T) T) // derivative of activation function
+
+	Name string // acti name of AF, empty for custom functions
 }
 
 func NewAL[T mat.Float](AF, DAF *(func(X T) T)) *ActivationLayer[T] {
@@ -24,6 +27,18 @@ func NewAL[T mat.Float](AF, DAF *(func(X T) T)) *ActivationLayer[T] {
 	}
 }
 
+// activation layer for a function known to acti.ByName, these can be saved
+func NewALByName[T mat.Float](name string) (*ActivationLayer[T], error) {
+	af, daf, err := acti.ByName[T](name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create ActivationLayer, reason { %s }", err)
+	}
+
+	al := NewAL(af, daf)
+	al.Name = name
+	return al, nil
+}
+
 func (al *ActivationLayer[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
 	if x == nil {
 		return nil, fmt.Errorf(
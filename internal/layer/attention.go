@@ -0,0 +1,425 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math"
+	"math/rand"
+)
+
+// reports whether query position q may attend to key position k, nil allows every pair
+type Mask func(q, k int) bool
+
+// each query only sees itself and earlier keys
+func CausalMask() Mask {
+	return func(q, k int) bool { return k <= q }
+}
+
+// only the first length keys are real tokens, the rest is padding
+func PaddingMask(length int) Mask {
+	return func(q, k int) bool { return k < length }
+}
+
+func (m Mask) And(other Mask) Mask {
+	if m == nil {
+		return other
+	}
+	if other == nil {
+		return m
+	}
+	return func(q, k int) bool { return m(q, k) && other(q, k) }
+}
+
+/*
+* Scaled Dot-Product Attention
+*
+* q[dk, Lq], k[dk, Lk] and v[dv, Lk] hold one token per column, returns
+*
+*   weights[Lk, Lq] = softmax(k^T * q / sqrt(dk)), column-wise over the keys
+*   out[dv, Lq]     = v * weights
+*
+* Masked keys get exactly zero weight, a query with every key masked
+* attends to nothing and its output column is zero.
+**/
+func ScaledDotProductAttention[T mat.Float](q, k, v *mat.Mat2D[T], mask Mask) (out, weights *mat.Mat2D[T], err error) {
+	if q.Rows() != k.Rows() || k.Cols() != v.Cols() {
+		return nil, nil, fmt.Errorf(
+			"Failed to ScaledDotProductAttention, reason { mismatched q[%d, %d], k[%d, %d], v[%d, %d] }",
+			q.Rows(), q.Cols(), k.Rows(), k.Cols(), v.Rows(), v.Cols(),
+		)
+	}
+
+	scores, err := mat.MatMul(k.TP(), q)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to ScaledDotProductAttention, reason { %s }", err)
+	}
+	scores.Scale(T(1 / math.Sqrt(float64(q.Rows()))))
+
+	weights = maskedSoftmax(scores, mask)
+
+	out, err = mat.MatMul(v, weights)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to ScaledDotProductAttention, reason { %s }", err)
+	}
+
+	return out, weights, nil
+}
+
+/*
+* Multi-Head Attention
+*
+* Self-attention over sequences laid out like the recurrent layers,
+* X[steps*dModel, N] with token t of sample n in rows [t*dModel, (t+1)*dModel).
+*
+* W[4*dModel, 1 + dModel] stacks the query, key, value and output
+* projections (bias in column 0), in that order. Queries, keys and values are
+* split into heads of dModel/heads features, attended independently and
+* concatenated before the output projection.
+*
+* Causal restricts every token to earlier positions, Lengths (one per
+* sample, nil for none) masks the padding at the end of shorter sequences.
+**/
+type MultiHeadAttention[T mat.Float] struct {
+	LayerIO[T]
+
+	W     *mat.Mat2D[T] // weights
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	Causal  bool
+	Lengths []int
+
+	dModel, heads uint64
+
+	// forward cache
+	seqLen int
+	z      *mat.Mat2D[T]     // [1; X] with one token per column
+	qkv    *mat.Mat2D[T]     // projected queries, keys and values
+	zc     *mat.Mat2D[T]     // [1; concatenated heads]
+	attn   [][]*mat.Mat2D[T] // attention weights per sample, per head
+}
+
+func NewMultiHeadAttention[T mat.Float](dModel, heads uint64) (*MultiHeadAttention[T], error) {
+	if heads == 0 || dModel%heads != 0 {
+		return nil, fmt.Errorf(
+			"Failed to create MultiHeadAttention, reason { dModel %d is not divisible into %d heads }",
+			dModel, heads,
+		)
+	}
+
+	return &MultiHeadAttention[T]{
+		W:     mat.Rand[T](4*dModel, 1+dModel),
+		WGrad: nil,
+
+		dModel: dModel,
+		heads:  heads,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (al *MultiHeadAttention[T]) ISize() int64 {
+	return int64(al.dModel)
+}
+
+func (al *MultiHeadAttention[T]) OSize() int64 {
+	return int64(al.dModel)
+}
+
+func (al *MultiHeadAttention[T]) Heads() int64 {
+	return int64(al.heads)
+}
+
+// each projection (Q, K, V and O) is its own [dModel, 1 + dModel] layer, initialized on its own
+func (al *MultiHeadAttention[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	for k := range uint64(4) {
+		initBiased(rowsOf(al.W, k*al.dModel, (k+1)*al.dModel), 1, weights, bias, rng)
+	}
+}
+
+func (al *MultiHeadAttention[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, al.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+
+	xc, seqLen, err := tokensToCols(x, al.dModel)
+	if err != nil {
+		return nil, al.wrapForwardErr(err)
+	}
+
+	yc, err := al.forwardCols(xc, seqLen)
+	if err != nil {
+		return nil, err
+	}
+
+	al.I = x
+	al.O = colsToTokens(yc, seqLen)
+
+	return al.O, nil
+}
+
+func (al *MultiHeadAttention[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, al.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+
+	dyc, seqLen, err := tokensToCols(loss, al.dModel)
+	if err != nil {
+		return nil, al.wrapBackwardErr(err)
+	}
+	if seqLen != al.seqLen {
+		return nil, al.wrapBackwardErr(fmt.Errorf(
+			"loss has %d steps, last Forward had %d", seqLen, al.seqLen,
+		))
+	}
+
+	dxc, err := al.backwardCols(dyc)
+	if err != nil {
+		return nil, err
+	}
+
+	return colsToTokens(dxc, seqLen), nil
+}
+
+func (al *MultiHeadAttention[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, al.WGrad
+}
+
+func (al *MultiHeadAttention[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(al.W, al.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", al.shapeRep(), err)
+	}
+	al.W = W
+	return nil
+}
+
+// ## private ##
+
+// attention over xc[dModel, N*seqLen], sample n in columns [n*seqLen, (n+1)*seqLen)
+func (al *MultiHeadAttention[T]) forwardCols(xc *mat.Mat2D[T], seqLen int) (*mat.Mat2D[T], error) {
+	D, L := al.dModel, int64(seqLen)
+	N := xc.Cols() / L
+
+	if al.Lengths != nil && int64(len(al.Lengths)) != N {
+		return nil, al.wrapForwardErr(fmt.Errorf(
+			"expected %d Lengths, one per sample, found %d", N, len(al.Lengths),
+		))
+	}
+
+	al.seqLen = seqLen
+	al.z = mat.MustVCat(mat.Ones[T](1, uint64(xc.Cols())), xc)
+	al.qkv = mat.MustMatMul(rowsOf(al.W, 0, 3*D), al.z)
+
+	dk := D / al.heads
+	concat := mat.New2D[T](D, uint64(xc.Cols()))
+	al.attn = make([][]*mat.Mat2D[T], N)
+
+	for n := range N {
+		cs := mat.CS{n * L, (n + 1) * L}
+		mask := al.mask(int(n))
+
+		al.attn[n] = make([]*mat.Mat2D[T], al.heads)
+		for h := range al.heads {
+			q := al.qkv.MustSlice(mat.RS{int64(h * dk), int64((h + 1) * dk)}, cs)
+			k := al.qkv.MustSlice(mat.RS{int64(D + h*dk), int64(D + (h+1)*dk)}, cs)
+			v := al.qkv.MustSlice(mat.RS{int64(2*D + h*dk), int64(2*D + (h+1)*dk)}, cs)
+
+			out, weights, err := ScaledDotProductAttention(q, k, v, mask)
+			if err != nil {
+				return nil, al.wrapForwardErr(err)
+			}
+
+			al.attn[n][h] = weights
+			concat.MustSlice(mat.RS{int64(h * dk), int64((h + 1) * dk)}, cs).MustCopy(out)
+		}
+	}
+
+	al.zc = mat.MustVCat(mat.Ones[T](1, uint64(xc.Cols())), concat)
+
+	return mat.MustMatMul(rowsOf(al.W, 3*D, 4*D), al.zc), nil
+}
+
+func (al *MultiHeadAttention[T]) backwardCols(dyc *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if al.z == nil {
+		return nil, al.wrapBackwardErr(fmt.Errorf("Backward called before Forward"))
+	}
+	if dyc.Cols() != al.z.Cols() {
+		return nil, al.wrapBackwardErr(fmt.Errorf(
+			"loss has %d tokens, last Forward had %d", dyc.Cols(), al.z.Cols(),
+		))
+	}
+
+	/*
+		Y    = Wo * [1; C]          dWo = dY * [1; C]^T    dC = (Wo^T * dY) without bias row
+		QKV  = Wqkv * [1; X]        dWqkv = dQKV * [1; X]^T
+		dX   = (Wqkv^T * dQKV) without bias row
+	*/
+	D, L := al.dModel, int64(al.seqLen)
+	N := dyc.Cols() / L
+	dk := D / al.heads
+
+	Wqkv, Wo := rowsOf(al.W, 0, 3*D), rowsOf(al.W, 3*D, 4*D)
+
+	dWo := mat.MustMatMul(dyc, al.zc.TP())
+	dzc := mat.MustMatMul(Wo.TP(), dyc)
+	dConcat := rowsOf(dzc, 1, uint64(dzc.Rows()))
+
+	dqkv := mat.New2D[T](3*D, uint64(dyc.Cols()))
+	for n := range N {
+		cs := mat.CS{n * L, (n + 1) * L}
+
+		for h := range al.heads {
+			rq := mat.RS{int64(h * dk), int64((h + 1) * dk)}
+			rk := mat.RS{int64(D + h*dk), int64(D + (h+1)*dk)}
+			rv := mat.RS{int64(2*D + h*dk), int64(2*D + (h+1)*dk)}
+
+			dQ, dK, dV := attentionBackward(
+				al.qkv.MustSlice(rq, cs),
+				al.qkv.MustSlice(rk, cs),
+				al.qkv.MustSlice(rv, cs),
+				al.attn[n][h],
+				dConcat.MustSlice(rq, cs),
+			)
+
+			dqkv.MustSlice(rq, cs).MustCopy(dQ)
+			dqkv.MustSlice(rk, cs).MustCopy(dK)
+			dqkv.MustSlice(rv, cs).MustCopy(dV)
+		}
+	}
+
+	dWqkv := mat.MustMatMul(dqkv, al.z.TP())
+	al.WGrad = mat.MustVCat(dWqkv, dWo)
+
+	dz := mat.MustMatMul(Wqkv.TP(), dqkv)
+
+	return rowsOf(dz, 1, uint64(dz.Rows())), nil
+}
+
+func (al *MultiHeadAttention[T]) mask(n int) Mask {
+	var mask Mask
+	if al.Causal {
+		mask = CausalMask()
+	}
+	if al.Lengths != nil {
+		mask = mask.And(PaddingMask(al.Lengths[n]))
+	}
+	return mask
+}
+
+func (al *MultiHeadAttention[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		al.shapeRep(),
+		err,
+	)
+}
+
+func (al *MultiHeadAttention[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		al.shapeRep(),
+		err,
+	)
+}
+
+func (al *MultiHeadAttention[T]) shapeRep() string {
+	return fmt.Sprintf(
+		"<MultiHeadAttention([%d, N] x steps, %d heads) -> [%d, N] x steps>",
+		al.dModel, al.heads, al.dModel,
+	)
+}
+
+/*
+* dS      = A * (dA - colsum(A * dA))     softmax backward, per column
+* dq      = k * dS / sqrt(dk)
+* dk      = q * dS^T / sqrt(dk)
+* dv      = dOut * A^T
+**/
+func attentionBackward[T mat.Float](q, k, v, weights, dOut *mat.Mat2D[T]) (dq, dk, dv *mat.Mat2D[T]) {
+	dv = mat.MustMatMul(dOut, weights.TP())
+	dA := mat.MustMatMul(v.TP(), dOut)
+
+	dS := mat.MustMul(weights, dA)
+	dS.MustSubtract(mat.MustMul(weights, dS.SumRows()))
+
+	scale := T(1 / math.Sqrt(float64(q.Rows())))
+	dq = mat.MustMatMul(k, dS).Scale(scale)
+	dk = mat.MustMatMul(q, dS.TP()).Scale(scale)
+
+	return dq, dk, dv
+}
+
+// softmax down each column of scores[Lk, Lq] over the keys the mask allows, in place
+func maskedSoftmax[T mat.Float](scores *mat.Mat2D[T], mask Mask) *mat.Mat2D[T] {
+	for j := range scores.Cols() {
+		maxVal := math.Inf(-1)
+		for i := range scores.Rows() {
+			if mask == nil || mask(int(j), int(i)) {
+				maxVal = math.Max(maxVal, float64(scores.MustGet(i, j)))
+			}
+		}
+
+		sum := 0.0
+		for i := range scores.Rows() {
+			e := 0.0
+			if mask == nil || mask(int(j), int(i)) {
+				e = math.Exp(float64(scores.MustGet(i, j)) - maxVal)
+			}
+			scores.MustSet(i, j, T(e))
+			sum += e
+		}
+
+		if sum > 0 {
+			for i := range scores.Rows() {
+				scores.MustSet(i, j, T(float64(scores.MustGet(i, j))/sum))
+			}
+		}
+	}
+	return scores
+}
+
+// X[steps*size, N] -> [size, N*steps], token t of sample n in column n*steps + t
+func tokensToCols[T mat.Float](x *mat.Mat2D[T], size uint64) (*mat.Mat2D[T], int, error) {
+	if size == 0 || x.Rows() == 0 || x.Rows()%int64(size) != 0 {
+		return nil, 0, fmt.Errorf(
+			"Invalid sequence shape [%d, %d], rows must be a multiple of %d",
+			x.Rows(), x.Cols(), size,
+		)
+	}
+
+	steps := x.Rows() / int64(size)
+	cols := mat.New2D[T](size, uint64(steps*x.Cols()))
+
+	for n := range x.Cols() {
+		for t := range steps {
+			for f := range int64(size) {
+				cols.MustSet(f, n*steps+t, x.MustGet(t*int64(size)+f, n))
+			}
+		}
+	}
+
+	return cols, int(steps), nil
+}
+
+// inverse of tokensToCols
+func colsToTokens[T mat.Float](cols *mat.Mat2D[T], steps int) *mat.Mat2D[T] {
+	L, size := int64(steps), cols.Rows()
+	N := cols.Cols() / L
+	x := mat.New2D[T](uint64(L*size), uint64(N))
+
+	for n := range N {
+		for t := range L {
+			for f := range size {
+				x.MustSet(t*size+f, n, cols.MustGet(f, n*L+t))
+			}
+		}
+	}
+
+	return x
+}
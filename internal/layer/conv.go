@@ -0,0 +1,200 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
+)
+
+/*
+* Conv2D
+*
+* 2D convolution over a batch of images X[C*H*W, N] (see mat.ConvGeom for the layout),
+* producing Y[OC*OH*OW, N]. Like LinearLayer the bias lives in column 0 of W:
+*
+*	I[1 + C*KH*KW, OH*OW*N] = [ones; Im2Col(X)]
+*	Y                       = W[OC, 1 + C*KH*KW] * I
+**/
+type Conv2D[T mat.Float] struct {
+	LayerIO[T]
+
+	W     *mat.Mat2D[T] // weights
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	Geom        mat.ConvGeom
+	OutChannels uint64
+}
+
+func NewConv2D[T mat.Float](geom mat.ConvGeom, outChannels uint64) (*Conv2D[T], error) {
+	if err := geom.Validate(); err != nil {
+		return nil, fmt.Errorf("Failed to create Conv2D, reason { %s }", err)
+	}
+	if outChannels == 0 {
+		return nil, fmt.Errorf("Failed to create Conv2D, reason { zero output channels }")
+	}
+
+	geom = geom.Normalized()
+	W := mat.Rand[T](outChannels, 1+geom.PatchSize())
+
+	return &Conv2D[T]{
+		W:     W,
+		WGrad: nil,
+
+		Geom:        geom,
+		OutChannels: outChannels,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func NewConv2DWithInit[T mat.Float](
+	geom mat.ConvGeom, outChannels uint64, weights, bias weightinit.Initializer[T], rng *rand.Rand,
+) (*Conv2D[T], error) {
+	cl, err := NewConv2D[T](geom, outChannels)
+	if err != nil {
+		return nil, err
+	}
+	cl.Init(weights, bias, rng)
+	return cl, nil
+}
+
+// every kernel weight is shared over the KH*KW window, so it counts towards fanOut that many times
+func (cl *Conv2D[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	initBiased(cl.W, int64(cl.Geom.KernelH*cl.Geom.KernelW), weights, bias, rng)
+}
+
+func (cl *Conv2D[T]) ISize() int64 {
+	return int64(cl.Geom.InSize())
+}
+
+func (cl *Conv2D[T]) OSize() int64 {
+	return int64(cl.OutChannels * cl.Geom.OutH() * cl.Geom.OutW())
+}
+
+func (cl *Conv2D[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, cl.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+
+	cols, err := mat.Im2Col(x, cl.Geom)
+	if err != nil {
+		return nil, cl.wrapForwardErr(err)
+	}
+
+	I, err := mat.VCat(mat.Ones[T](1, uint64(cols.Cols())), cols)
+	if err != nil {
+		return nil, cl.wrapForwardErr(err)
+	}
+
+	out, err := mat.MatMul(cl.W, I)
+	if err != nil {
+		return nil, cl.wrapForwardErr(err)
+	}
+
+	// out[OC, OH*OW*N] is laid out as [OC*OH*OW, N]
+	O, err := out.Reshape(uint64(cl.OSize()), uint64(x.Cols()))
+	if err != nil {
+		return nil, cl.wrapForwardErr(err)
+	}
+
+	cl.I = I
+	cl.O = O
+
+	return O, nil
+}
+
+func (cl *Conv2D[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, cl.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+	if cl.I == nil {
+		return nil, cl.wrapBackwardErr(fmt.Errorf("Backward called before Forward"))
+	}
+	if loss.Rows() != cl.OSize() || loss.Cols()*int64(cl.Geom.OutH()*cl.Geom.OutW()) != cl.I.Cols() {
+		return nil, cl.wrapBackwardErr(fmt.Errorf(
+			"loss[%d, %d] does not match output[%d, %d]",
+			loss.Rows(), loss.Cols(),
+			cl.OSize(), cl.I.Cols()/int64(cl.Geom.OutH()*cl.Geom.OutW()),
+		))
+	}
+
+	/*
+		dL/dO   = loss[OC*OH*OW, N] viewed as dOut[OC, OH*OW*N]
+
+		dL/dW   = dOut * I^T                 [OC, 1 + C*KH*KW]
+		dL/dI   = W^T * dOut                 [1 + C*KH*KW, OH*OW*N]
+		dL/dX   = Col2Im(dL/dI without bias row)
+	*/
+	dOut, err := loss.Clone().Reshape(cl.OutChannels, uint64(cl.I.Cols()))
+	if err != nil {
+		return nil, cl.wrapBackwardErr(err)
+	}
+
+	Wgrad, err := mat.MatMul(dOut, cl.I.TP())
+	if err != nil {
+		return nil, cl.wrapBackwardErr(err)
+	}
+	cl.WGrad = Wgrad
+
+	dI, err := mat.MatMul(cl.W.TP(), dOut)
+	if err != nil {
+		return nil, cl.wrapBackwardErr(err)
+	}
+
+	// Chop off bias
+	dCols, err := dI.Slice(mat.RS{1, dI.Rows()}, mat.CS{0, dI.Cols()})
+	if err != nil {
+		return nil, cl.wrapBackwardErr(err)
+	}
+
+	back, err := mat.Col2Im(dCols, cl.Geom)
+	if err != nil {
+		return nil, cl.wrapBackwardErr(err)
+	}
+
+	return back, nil
+}
+
+func (cl *Conv2D[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, cl.WGrad
+}
+
+func (cl *Conv2D[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(cl.W, cl.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", cl.shapeRep(), err)
+	}
+	cl.W = W
+	return nil
+}
+
+// ## private ##
+func (cl *Conv2D[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		cl.shapeRep(),
+		err,
+	)
+}
+
+func (cl *Conv2D[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		cl.shapeRep(),
+		err,
+	)
+}
+
+func (cl *Conv2D[T]) shapeRep() string {
+	return fmt.Sprintf(
+		"<Conv2D([%d, %d, %d]) -> [%d, %d, %d]>",
+		cl.Geom.Channels, cl.Geom.Height, cl.Geom.Width,
+		cl.OutChannels, cl.Geom.OutH(), cl.Geom.OutW(),
+	)
+}
@@ -0,0 +1,94 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math/rand"
+)
+
+/*
+* Dropout (inverted)
+*
+* While training every element is zeroed with probability Rate and the
+* survivors are scaled by 1 / (1 - Rate), so the expected activation is
+* unchanged and inference (SetTraining(false)) is the identity.
+**/
+type Dropout[T mat.Float] struct {
+	LayerIO[T]
+	Mode
+
+	Rate T
+	RNG  *rand.Rand // source of the masks, may be replaced to share one seeded source
+
+	seed int64
+	mask *mat.Mat2D[T] // nil when the last Forward was at inference
+}
+
+func NewDropout[T mat.Float](rate T, seed int64) (*Dropout[T], error) {
+	if rate < 0 || rate >= 1 {
+		return nil, fmt.Errorf(
+			"Failed to create Dropout, reason { rate %f outside [0, 1) }", float64(rate),
+		)
+	}
+
+	return &Dropout[T]{
+		Rate: rate,
+		RNG:  rand.New(rand.NewSource(seed)),
+
+		seed: seed,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (dl *Dropout[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, fmt.Errorf("Failed to Dropout::Forward, reason { nil input }")
+	}
+
+	dl.I = x
+	if !dl.IsTraining() {
+		dl.mask = nil
+		dl.O = x
+		return x, nil
+	}
+
+	keep := 1 - float64(dl.Rate)
+	dl.mask = mat.New2D[T](uint64(x.Rows()), uint64(x.Cols())).Apply(func(T) T {
+		if dl.RNG.Float64() < keep {
+			return T(1 / keep)
+		}
+		return 0
+	})
+	dl.O = mat.MustMul(x, dl.mask)
+
+	return dl.O, nil
+}
+
+func (dl *Dropout[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, fmt.Errorf("Failed to Dropout::Backward, reason { nil loss }")
+	}
+	if dl.mask == nil {
+		return loss.Clone(), nil
+	}
+
+	back, err := mat.Mul(loss, dl.mask)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Dropout::Backward, reason { %s }", err)
+	}
+	return back, nil
+}
+
+func (dl *Dropout[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return false, nil
+}
+
+func (dl *Dropout[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	return fmt.Errorf("Error! Dropout is unlearnable! ")
+}
@@ -0,0 +1,100 @@
+package layer
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/mat"
+)
+
+/*
+* GRU
+*
+* W[3*hSize, 1 + iSize + hSize] stacks the update, reset and candidate
+* weights, in that order. The candidate sees the reset hidden state:
+*
+*   u, r = sigmoid(W_u,r * [1; x[t]; h[t-1]])
+*   n    = tanh(W_n * [1; x[t]; r * h[t-1]])
+*   h[t] = (1 - u) * n + u * h[t-1]
+**/
+type GRU[T mat.Float] struct {
+	recurrent[T]
+}
+
+func NewGRU[T mat.Float](iSize, hSize uint64) *GRU[T] {
+	return &GRU[T]{
+		recurrent: newRecurrent[T](iSize, hSize, &gruCell[T]{iSize: iSize, hSize: hSize}),
+	}
+}
+
+type gruCell[T mat.Float] struct {
+	iSize, hSize uint64
+}
+
+type gruCache[T mat.Float] struct {
+	z     *mat.Mat2D[T] // [1; x; h[t-1]]
+	zr    *mat.Mat2D[T] // [1; x; r * h[t-1]]
+	u, r  *mat.Mat2D[T] // gate activations
+	n     *mat.Mat2D[T] // candidate
+	hPrev *mat.Mat2D[T] // h[t-1]
+}
+
+func (c *gruCell[T]) name() string    { return "GRU" }
+func (c *gruCell[T]) stateCount() int { return 1 }
+func (c *gruCell[T]) gates() uint64   { return 3 }
+
+func (c *gruCell[T]) forward(W, x *mat.Mat2D[T], state []*mat.Mat2D[T]) ([]*mat.Mat2D[T], any) {
+	H, hPrev := c.hSize, state[0]
+	ones := mat.Ones[T](1, uint64(x.Cols()))
+
+	z := mat.MustVCat(ones, x, hPrev)
+	a := mat.MustMatMul(rowsOf(W, 0, 2*H), z)
+
+	cache := &gruCache[T]{
+		z:     z,
+		u:     rowsOf(a, 0, H).Apply(acti.Sigmoid[T]),
+		r:     rowsOf(a, H, 2*H).Apply(acti.Sigmoid[T]),
+		hPrev: hPrev,
+	}
+
+	cache.zr = mat.MustVCat(ones, x, mat.MustMul(cache.r, hPrev))
+	cache.n = mat.MustMatMul(rowsOf(W, 2*H, 3*H), cache.zr).Apply(acti.Tanh[T])
+
+	// h = n + u * (h[t-1] - n)
+	hNext := mat.MustAdd(cache.n, mat.MustMul(cache.u, mat.MustSubtract(hPrev, cache.n)))
+
+	return []*mat.Mat2D[T]{hNext}, cache
+}
+
+func (c *gruCell[T]) backward(
+	W *mat.Mat2D[T], cache any, dNext []*mat.Mat2D[T],
+) (*mat.Mat2D[T], []*mat.Mat2D[T], *mat.Mat2D[T]) {
+	H, gc, dh := c.hSize, cache.(*gruCache[T]), dNext[0]
+
+	// candidate branch
+	dn := mat.MustMul(dh, applied(gc.u, func(u T) T { return 1 - u }))
+	dan := mat.MustMul(dn, applied(gc.n, func(n T) T { return 1 - n*n }))
+
+	dWn := mat.MustMatMul(dan, gc.zr.TP())
+	dzr := mat.MustMatMul(rowsOf(W, 2*H, 3*H).TP(), dan)
+	drh := rowsOf(dzr, 1+c.iSize, uint64(dzr.Rows()))
+
+	// gates
+	dSigmoid := func(s T) T { return s * (1 - s) }
+
+	du := mat.MustMul(dh, mat.MustSubtract(gc.hPrev, gc.n))
+	dr := mat.MustMul(drh, gc.hPrev)
+	da := mat.MustVCat(
+		mat.MustMul(du, applied(gc.u, dSigmoid)),
+		mat.MustMul(dr, applied(gc.r, dSigmoid)),
+	)
+
+	dWur := mat.MustMatMul(da, gc.z.TP())
+	dz := mat.MustMatMul(rowsOf(W, 0, 2*H).TP(), da)
+
+	dx := mat.MustAdd(rowsOf(dz, 1, 1+c.iSize), rowsOf(dzr, 1, 1+c.iSize))
+
+	dhPrev := mat.MustMul(dh, gc.u)
+	dhPrev.MustAdd(mat.MustMul(drh, gc.r))
+	dhPrev.MustAdd(rowsOf(dz, 1+c.iSize, uint64(dz.Rows())))
+
+	return dx, []*mat.Mat2D[T]{dhPrev}, mat.MustVCat(dWur, dWn)
+}
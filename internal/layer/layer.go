@@ -1,7 +1,10 @@
 package layer
 
 import (
+	"fmt"
 	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
 )
 
 type LayerIO[T mat.Float] struct {
@@ -25,3 +28,122 @@ type Layer[T mat.Float] interface {
 	Propagatable[T]
 	Learnable[T]
 }
+
+// Layers over sequences, xs[t] is the [features, N] batch at timestep t
+type SequencePropagatable[T mat.Float] interface {
+	ForwardSeq(xs []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error)
+	BackwardSeq(losses []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error)
+}
+
+type SequenceLayer[T mat.Float] interface {
+	SequencePropagatable[T]
+	Layer[T]
+	ISize() int64 // features per timestep in
+	OSize() int64 // features per timestep out
+}
+
+/*
+* Layers that behave differently while training and at inference (BatchNorm,
+* Dropout). Containers implement it by forwarding to their children, so
+* model.SetTraining(false) switches a whole model, layers without a mode
+* (LinearLayer, ActivationLayer, ...) are simply skipped.
+**/
+type ModeSetter interface {
+	SetTraining(training bool)
+	IsTraining() bool
+}
+
+// Mode implements ModeSetter for embedding, the zero value is training mode
+type Mode struct {
+	inference bool
+}
+
+func (m *Mode) SetTraining(training bool) {
+	m.inference = !training
+}
+
+func (m *Mode) IsTraining() bool {
+	return !m.inference
+}
+
+/*
+* Layers with random initial weights can be re-initialized with any
+* weightinit.Initializer, the bias column separately, drawing from rng (nil
+* for mat.DefaultRNG). Containers initialize all their children.
+**/
+type Initializable[T mat.Float] interface {
+	Init(weights, bias weightinit.Initializer[T], rng *rand.Rand)
+}
+
+// Layers made of other layers (such as Sequential) expose their learnable children
+type Container[T mat.Float] interface {
+	Params() []Learnable[T]
+}
+
+// ## private ##
+
+/*
+* initializes W[out, 1 + in] as weights W[:, 1:] and bias W[:, 0], both with
+* the fans of the weights, shared over a receptive field of the given size
+* (1 for dense weights). A nil initializer keeps the current values.
+**/
+func initBiased[T mat.Float](W *mat.Mat2D[T], field int64, weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	fans := weightinit.Fans{In: float64(max(W.Cols()-1, 1)), Out: float64(max(W.Rows(), 1))}.Receptive(field)
+
+	if weights != nil && W.Cols() > 1 {
+		weights(W.MustSlice(mat.RS{0, W.Rows()}, mat.CS{1, W.Cols()}), fans, rng)
+	}
+	if bias != nil {
+		bias(W.MustSlice(mat.RS{0, W.Rows()}, mat.CS{0, 1}), fans, rng)
+	}
+}
+
+// initializes every layer that can be, skipping the rest
+func initAll[T mat.Float](weights, bias weightinit.Initializer[T], rng *rand.Rand, layers ...Layer[T]) {
+	for _, layer := range layers {
+		if il, ok := layer.(Initializable[T]); ok {
+			il.Init(weights, bias, rng)
+		}
+	}
+}
+
+// switches every layer that has a mode, skipping the rest
+func setTraining[T mat.Float](training bool, layers ...Layer[T]) {
+	for _, layer := range layers {
+		if ms, ok := layer.(ModeSetter); ok {
+			ms.SetTraining(training)
+		}
+	}
+}
+
+// runs updateWeights on copies of weights and grad, returning the validated new weights
+func learnWeights[T mat.Float](
+	weights, grad *mat.Mat2D[T],
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) (*mat.Mat2D[T], error) {
+	if grad == nil {
+		return nil, fmt.Errorf("layer is learnable but gradient is nil")
+	}
+
+	newWeights, err := (*updateWeights)(
+		weights.Clone(),
+		grad.Clone(),
+	) // Cloning is expensive :(
+
+	if err != nil {
+		return nil, fmt.Errorf("Learning Error occured getting newWeights, reason = { %s }", err)
+	}
+	if newWeights == nil {
+		return nil, fmt.Errorf("Learning Error updating weights, newWeights are nil")
+	}
+
+	if !mat.DimsMatch(weights, newWeights) {
+		return nil, fmt.Errorf(
+			"Learning Error updating weights, newWeights[%d, %d] does not match dimensions of oldWeights[%d, %d]",
+			newWeights.Rows(), newWeights.Cols(),
+			weights.Rows(), weights.Cols(),
+		)
+	}
+
+	return newWeights, nil
+}
@@ -3,6 +3,8 @@ package layer
 import (
 	"fmt"
 	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
 )
 
 type LinearLayer[T mat.Float] struct {
@@ -13,6 +15,7 @@ type LinearLayer[T mat.Float] struct {
 	iSize, oSize uint64
 }
 
+// weights and bias start uniform in [0, 1), see NewLLWithInit for better scaled starts
 func NewLL[T mat.Float](iSize, oSize uint64) *LinearLayer[T] {
 	wrows, wcols := oSize, 1+iSize // for bias
 
@@ -32,6 +35,19 @@ func NewLL[T mat.Float](iSize, oSize uint64) *LinearLayer[T] {
 	}
 }
 
+// e.g. NewLLWithInit(3, 4, weightinit.HeNormal[float32], weightinit.Zeros[float32], nil), see Init for rng
+func NewLLWithInit[T mat.Float](
+	iSize, oSize uint64, weights, bias weightinit.Initializer[T], rng *rand.Rand,
+) *LinearLayer[T] {
+	ll := NewLL[T](iSize, oSize)
+	ll.Init(weights, bias, rng)
+	return ll
+}
+
+func (ll *LinearLayer[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	initBiased(ll.W, 1, weights, bias, rng)
+}
+
 func (ll *LinearLayer[T]) ISize() int64 {
 	return int64(ll.iSize)
 }
@@ -107,31 +123,11 @@ func (ll *LinearLayer[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T])
 func (ll *LinearLayer[T]) Learn(
 	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
 ) error {
-	if ll.WGrad == nil {
-		return fmt.Errorf("linearlayer is learnable but gradient is nil")
-	}
-
-	newWeights, err := (*updateWeights)(
-		ll.W.Clone(),
-		ll.WGrad.Clone(),
-	) // Cloning is expensive :(
-
+	W, err := learnWeights(ll.W, ll.WGrad, updateWeights)
 	if err != nil {
-		return fmt.Errorf("Learning Error occured getting newWeights, reason = { %s }", err)
-	}
-	if newWeights == nil {
-		return fmt.Errorf("Learning Error updating weights, newWeights are nil")
-	}
-
-	if !mat.DimsMatch(ll.W, newWeights) {
-		return fmt.Errorf(
-			"Learning Error updating weights, newWeights[%d, %d] does not match dimensions of oldWeights[%d, %d]",
-			newWeights.Rows(), newWeights.Cols(),
-			ll.W.Rows(), ll.W.Cols(),
-		)
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", ll.shapeRep(), err)
 	}
-
-	ll.W = newWeights
+	ll.W = W
 
 	return nil
 }
@@ -0,0 +1,94 @@
+package layer
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/mat"
+)
+
+/*
+* LSTM
+*
+* W[4*hSize, 1 + iSize + hSize] stacks the input, forget, cell and output
+* gates, in that order, acting on [1; x[t]; h[t-1]]:
+*
+*   i, f, o = sigmoid(W_i,f,o * z)    g = tanh(W_g * z)
+*   c[t]    = f * c[t-1] + i * g
+*   h[t]    = o * tanh(c[t])
+**/
+type LSTM[T mat.Float] struct {
+	recurrent[T]
+}
+
+func NewLSTM[T mat.Float](iSize, hSize uint64) *LSTM[T] {
+	return &LSTM[T]{
+		recurrent: newRecurrent[T](iSize, hSize, &lstmCell[T]{iSize: iSize, hSize: hSize}),
+	}
+}
+
+type lstmCell[T mat.Float] struct {
+	iSize, hSize uint64
+}
+
+type lstmCache[T mat.Float] struct {
+	z          *mat.Mat2D[T] // [1; x; h[t-1]]
+	i, f, g, o *mat.Mat2D[T] // gate activations
+	cPrev      *mat.Mat2D[T] // c[t-1]
+	tc         *mat.Mat2D[T] // tanh(c[t])
+}
+
+func (c *lstmCell[T]) name() string    { return "LSTM" }
+func (c *lstmCell[T]) stateCount() int { return 2 }
+func (c *lstmCell[T]) gates() uint64   { return 4 }
+
+func (c *lstmCell[T]) forward(W, x *mat.Mat2D[T], state []*mat.Mat2D[T]) ([]*mat.Mat2D[T], any) {
+	H := c.hSize
+	z := mat.MustVCat(mat.Ones[T](1, uint64(x.Cols())), x, state[0])
+	a := mat.MustMatMul(W, z)
+
+	cache := &lstmCache[T]{
+		z:     z,
+		i:     rowsOf(a, 0, H).Apply(acti.Sigmoid[T]),
+		f:     rowsOf(a, H, 2*H).Apply(acti.Sigmoid[T]),
+		g:     rowsOf(a, 2*H, 3*H).Apply(acti.Tanh[T]),
+		o:     rowsOf(a, 3*H, 4*H).Apply(acti.Sigmoid[T]),
+		cPrev: state[1],
+	}
+
+	cNext := mat.MustAdd(mat.MustMul(cache.f, state[1]), mat.MustMul(cache.i, cache.g))
+	cache.tc = applied(cNext, acti.Tanh[T])
+	hNext := mat.MustMul(cache.o, cache.tc)
+
+	return []*mat.Mat2D[T]{hNext, cNext}, cache
+}
+
+func (c *lstmCell[T]) backward(
+	W *mat.Mat2D[T], cache any, dNext []*mat.Mat2D[T],
+) (*mat.Mat2D[T], []*mat.Mat2D[T], *mat.Mat2D[T]) {
+	lc := cache.(*lstmCache[T])
+	dh, dc := dNext[0], dNext[1]
+
+	// dc += dh * o * (1 - tanh(c)^2)
+	dc = mat.MustAdd(dc, mat.MustMul(
+		mat.MustMul(dh, lc.o),
+		applied(lc.tc, func(tc T) T { return 1 - tc*tc }),
+	))
+
+	dSigmoid := func(s T) T { return s * (1 - s) }
+	dTanh := func(t T) T { return 1 - t*t }
+
+	da := mat.MustVCat(
+		mat.MustMul(mat.MustMul(dc, lc.g), applied(lc.i, dSigmoid)),
+		mat.MustMul(mat.MustMul(dc, lc.cPrev), applied(lc.f, dSigmoid)),
+		mat.MustMul(mat.MustMul(dc, lc.i), applied(lc.g, dTanh)),
+		mat.MustMul(mat.MustMul(dh, lc.tc), applied(lc.o, dSigmoid)),
+	)
+
+	dW := mat.MustMatMul(da, lc.z.TP())
+	dz := mat.MustMatMul(W.TP(), da)
+
+	dx := rowsOf(dz, 1, 1+c.iSize)
+	dhPrev := rowsOf(dz, 1+c.iSize, uint64(dz.Rows()))
+	dcPrev := mat.MustMul(dc, lc.f)
+
+	return dx, []*mat.Mat2D[T]{dhPrev, dcPrev}, dW
+}
@@ -0,0 +1,420 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* Layer Normalization
+*
+* Normalizes every sample (column) of x[features, N] over its features:
+*
+*   xhat[i, j] = (x[i, j] - mean_j) / sqrt(var_j + Eps)
+*   y[i, j]    = gamma[i] * xhat[i, j] + beta[i]
+*
+* W[features, 2] holds beta in column 0 (the bias column, like LinearLayer)
+* and gamma in column 1, initialized to 0 and 1.
+**/
+type LayerNorm[T mat.Float] struct {
+	LayerIO[T]
+
+	W     *mat.Mat2D[T] // [beta, gamma]
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	Eps T
+
+	features uint64
+
+	xhat   *mat.Mat2D[T]
+	invStd []T
+}
+
+func NewLayerNorm[T mat.Float](features uint64) *LayerNorm[T] {
+	W := mat.New2D[T](features, 2)
+	W.MustSlice(mat.RS{0, int64(features)}, mat.CS{1, 2}).Fill(1)
+
+	return &LayerNorm[T]{
+		W:     W,
+		WGrad: nil,
+
+		Eps: 1e-5,
+
+		features: features,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+func (nl *LayerNorm[T]) ISize() int64 {
+	return int64(nl.features)
+}
+
+func (nl *LayerNorm[T]) OSize() int64 {
+	return int64(nl.features)
+}
+
+func (nl *LayerNorm[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, nl.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+	if x.Rows() != int64(nl.features) {
+		return nil, nl.wrapForwardErr(fmt.Errorf(
+			"Invalid input[%d, %d], expected %d features", x.Rows(), x.Cols(), nl.features,
+		))
+	}
+
+	mean, variance := colMoments(x)
+	xhat, invStd := standardize(x, mean, variance, float64(nl.Eps))
+
+	O := mat.MustMul(xhat, nl.gamma())
+	O.MustAdd(nl.beta())
+
+	nl.xhat = xhat
+	nl.invStd = invStd
+	nl.I = x
+	nl.O = O
+
+	return O, nil
+}
+
+func (nl *LayerNorm[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, nl.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+	if nl.xhat == nil {
+		return nil, nl.wrapBackwardErr(fmt.Errorf("Backward called before Forward"))
+	}
+	if !mat.DimsMatch(loss, nl.xhat) {
+		return nil, nl.wrapBackwardErr(fmt.Errorf(
+			"loss[%d, %d] does not match output[%d, %d]",
+			loss.Rows(), loss.Cols(), nl.xhat.Rows(), nl.xhat.Cols(),
+		))
+	}
+
+	/*
+		dbeta   = sum_j dy
+		dgamma  = sum_j dy * xhat
+		dxhat   = dy * gamma
+		dx      = see standardizeBackward
+	*/
+	WGrad, err := mat.HCat(loss.SumCols(), mat.MustMul(loss, nl.xhat).SumCols())
+	if err != nil {
+		return nil, nl.wrapBackwardErr(err)
+	}
+	nl.WGrad = WGrad
+
+	dxhat := mat.MustMul(loss, nl.gamma())
+
+	return standardizeBackward(dxhat, nl.xhat, nl.invStd), nil
+}
+
+func (nl *LayerNorm[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, nl.WGrad
+}
+
+func (nl *LayerNorm[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(nl.W, nl.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", nl.shapeRep(), err)
+	}
+	nl.W = W
+	return nil
+}
+
+func (nl *LayerNorm[T]) beta() *mat.Mat2D[T] {
+	return nl.W.MustSlice(mat.RS{0, nl.W.Rows()}, mat.CS{0, 1})
+}
+
+func (nl *LayerNorm[T]) gamma() *mat.Mat2D[T] {
+	return nl.W.MustSlice(mat.RS{0, nl.W.Rows()}, mat.CS{1, 2})
+}
+
+/*
+* Batch Normalization
+*
+* Normalizes every feature (row) of x[features, N] over the batch:
+*
+*   xhat[i, j] = (x[i, j] - mean_i) / sqrt(var_i + Eps)
+*   y[i, j]    = gamma[i] * xhat[i, j] + beta[i]
+*
+* While training the batch statistics are used and folded into
+* RunningMean/RunningVar (exponential average with Momentum), in inference
+* mode (SetTraining(false)) the running statistics are used instead.
+* W[features, 2] holds beta and gamma like LayerNorm.
+**/
+type BatchNorm[T mat.Float] struct {
+	LayerIO[T]
+	Mode
+
+	W     *mat.Mat2D[T] // [beta, gamma]
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	RunningMean *mat.Mat2D[T] // [features, 1]
+	RunningVar  *mat.Mat2D[T] // [features, 1]
+
+	Eps      T
+	Momentum T
+
+	features uint64
+
+	xhat     *mat.Mat2D[T]
+	invStd   []T
+	training bool // mode of the last Forward
+}
+
+func NewBatchNorm[T mat.Float](features uint64) *BatchNorm[T] {
+	W := mat.New2D[T](features, 2)
+	W.MustSlice(mat.RS{0, int64(features)}, mat.CS{1, 2}).Fill(1)
+
+	return &BatchNorm[T]{
+		W:     W,
+		WGrad: nil,
+
+		RunningMean: mat.New2D[T](features, 1),
+		RunningVar:  mat.Ones[T](features, 1),
+
+		Eps:      1e-5,
+		Momentum: 0.1,
+
+		features: features,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+func (bl *BatchNorm[T]) ISize() int64 {
+	return int64(bl.features)
+}
+
+func (bl *BatchNorm[T]) OSize() int64 {
+	return int64(bl.features)
+}
+
+func (bl *BatchNorm[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, bl.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+	if x.Rows() != int64(bl.features) {
+		return nil, bl.wrapForwardErr(fmt.Errorf(
+			"Invalid input[%d, %d], expected %d features", x.Rows(), x.Cols(), bl.features,
+		))
+	}
+
+	// statistics per row of x are per column of x^T
+	var mean, variance []float64
+	if bl.IsTraining() {
+		mean, variance = colMoments(x.TP())
+		bl.updateRunningStats(mean, variance, x.Cols())
+	} else {
+		mean, variance = make([]float64, bl.features), make([]float64, bl.features)
+		for i := range int64(bl.features) {
+			mean[i] = float64(bl.RunningMean.MustGet(i, 0))
+			variance[i] = float64(bl.RunningVar.MustGet(i, 0))
+		}
+	}
+
+	xhatT, invStd := standardize(x.TP(), mean, variance, float64(bl.Eps))
+	xhat := xhatT.TP()
+
+	O := mat.MustMul(xhat, bl.gamma())
+	O.MustAdd(bl.beta())
+
+	bl.xhat = xhat
+	bl.invStd = invStd
+	bl.training = bl.IsTraining()
+	bl.I = x
+	bl.O = O
+
+	return O, nil
+}
+
+func (bl *BatchNorm[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, bl.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+	if bl.xhat == nil {
+		return nil, bl.wrapBackwardErr(fmt.Errorf("Backward called before Forward"))
+	}
+	if !mat.DimsMatch(loss, bl.xhat) {
+		return nil, bl.wrapBackwardErr(fmt.Errorf(
+			"loss[%d, %d] does not match output[%d, %d]",
+			loss.Rows(), loss.Cols(), bl.xhat.Rows(), bl.xhat.Cols(),
+		))
+	}
+
+	/*
+		dbeta   = sum_j dy
+		dgamma  = sum_j dy * xhat
+		dxhat   = dy * gamma
+		dx      = dxhat * invStd                     in inference, the statistics are constants
+		        = see standardizeBackward            while training, over x^T
+	*/
+	WGrad, err := mat.HCat(loss.SumCols(), mat.MustMul(loss, bl.xhat).SumCols())
+	if err != nil {
+		return nil, bl.wrapBackwardErr(err)
+	}
+	bl.WGrad = WGrad
+
+	dxhat := mat.MustMul(loss, bl.gamma())
+
+	if !bl.training {
+		invStd := mat.FromValues(bl.invStd).TP()
+		return dxhat.MustMul(invStd), nil
+	}
+
+	return standardizeBackward(dxhat.TP(), bl.xhat.TP(), bl.invStd).TP(), nil
+}
+
+func (bl *BatchNorm[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, bl.WGrad
+}
+
+func (bl *BatchNorm[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(bl.W, bl.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", bl.shapeRep(), err)
+	}
+	bl.W = W
+	return nil
+}
+
+// ## private ##
+
+func (nl *LayerNorm[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		nl.shapeRep(),
+		err,
+	)
+}
+
+func (nl *LayerNorm[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		nl.shapeRep(),
+		err,
+	)
+}
+
+func (nl *LayerNorm[T]) shapeRep() string {
+	return fmt.Sprintf("<LayerNorm([%d, N])>", nl.features)
+}
+
+// running = (1 - momentum) * running + momentum * batch, with the unbiased batch variance
+func (bl *BatchNorm[T]) updateRunningStats(mean, variance []float64, N int64) {
+	correction := 1.0
+	if N > 1 {
+		correction = float64(N) / float64(N-1)
+	}
+
+	m := float64(bl.Momentum)
+	for i := range int64(bl.features) {
+		rm := float64(bl.RunningMean.MustGet(i, 0))
+		rv := float64(bl.RunningVar.MustGet(i, 0))
+
+		bl.RunningMean.MustSet(i, 0, T((1-m)*rm+m*mean[i]))
+		bl.RunningVar.MustSet(i, 0, T((1-m)*rv+m*variance[i]*correction))
+	}
+}
+
+func (bl *BatchNorm[T]) beta() *mat.Mat2D[T] {
+	return bl.W.MustSlice(mat.RS{0, bl.W.Rows()}, mat.CS{0, 1})
+}
+
+func (bl *BatchNorm[T]) gamma() *mat.Mat2D[T] {
+	return bl.W.MustSlice(mat.RS{0, bl.W.Rows()}, mat.CS{1, 2})
+}
+
+func (bl *BatchNorm[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		bl.shapeRep(),
+		err,
+	)
+}
+
+func (bl *BatchNorm[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		bl.shapeRep(),
+		err,
+	)
+}
+
+func (bl *BatchNorm[T]) shapeRep() string {
+	return fmt.Sprintf("<BatchNorm([%d, N])>", bl.features)
+}
+
+// mean and biased variance of every column of x
+func colMoments[T mat.Float](x *mat.Mat2D[T]) (mean, variance []float64) {
+	mean, variance = make([]float64, x.Cols()), make([]float64, x.Cols())
+	D := float64(x.Rows())
+
+	for j := range x.Cols() {
+		for i := range x.Rows() {
+			mean[j] += float64(x.MustGet(i, j))
+		}
+		mean[j] /= D
+
+		for i := range x.Rows() {
+			d := float64(x.MustGet(i, j)) - mean[j]
+			variance[j] += d * d
+		}
+		variance[j] /= D
+	}
+
+	return mean, variance
+}
+
+// xhat = (x - mean) / sqrt(variance + eps) column-wise, also returns 1 / sqrt(variance + eps)
+func standardize[T mat.Float](x *mat.Mat2D[T], mean, variance []float64, eps float64) (*mat.Mat2D[T], []T) {
+	xhat := mat.New2D[T](uint64(x.Rows()), uint64(x.Cols()))
+	invStd := make([]T, x.Cols())
+
+	for j := range x.Cols() {
+		inv := 1 / math.Sqrt(variance[j]+eps)
+		invStd[j] = T(inv)
+		for i := range x.Rows() {
+			xhat.MustSet(i, j, T((float64(x.MustGet(i, j))-mean[j])*inv))
+		}
+	}
+
+	return xhat, invStd
+}
+
+/*
+* Backward of standardize when the statistics were computed from x itself,
+* per column with D rows:
+*
+*   dx = invStd / D * (D * dxhat - sum_i dxhat - xhat * sum_i (dxhat * xhat))
+**/
+func standardizeBackward[T mat.Float](dxhat, xhat *mat.Mat2D[T], invStd []T) *mat.Mat2D[T] {
+	sumD := dxhat.SumRows()
+	sumDX := mat.MustMul(dxhat, xhat).SumRows()
+
+	D := T(dxhat.Rows())
+	back := dxhat.Clone().Scale(D)
+	back.MustSubtract(sumD)
+	back.MustSubtract(mat.MustMul(xhat, sumDX))
+
+	for j := range back.Cols() {
+		scale := invStd[j] / D
+		for i := range back.Rows() {
+			back.MustSet(i, j, back.MustGet(i, j)*scale)
+		}
+	}
+
+	return back
+}
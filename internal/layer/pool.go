@@ -0,0 +1,258 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* MaxPool2D
+*
+* Max over each kernel window of X[C*H*W, N], per channel, producing Y[C*OH*OW, N].
+* Padding taps never win the max, the gradient is routed back to the
+* input pixel that did.
+**/
+type MaxPool2D[T mat.Float] struct {
+	LayerIO[T]
+
+	Geom mat.ConvGeom
+
+	argmax []int64 // input row of the max of each output element, -1 if none
+}
+
+func NewMaxPool2D[T mat.Float](geom mat.ConvGeom) (*MaxPool2D[T], error) {
+	if err := geom.Validate(); err != nil {
+		return nil, fmt.Errorf("Failed to create MaxPool2D, reason { %s }", err)
+	}
+
+	return &MaxPool2D[T]{
+		Geom: geom.Normalized(),
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (pl *MaxPool2D[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validatePoolInput(x, pl.Geom); err != nil {
+		return nil, wrapPoolErr("MaxPool2D", "Forward", pl.Geom, err)
+	}
+
+	g := pl.Geom
+	OH, OW, N := g.OutH(), g.OutW(), x.Cols()
+	P := OH * OW
+
+	O := mat.New2D[T](g.Channels*P, uint64(N))
+	pl.argmax = make([]int64, O.Rows()*N)
+
+	for c := range g.Channels {
+		for oh := range OH {
+			for ow := range OW {
+				outRow := int64(c*P + oh*OW + ow)
+
+				for n := range N {
+					best, arg := math.Inf(-1), int64(-1)
+
+					for kh := range g.KernelH {
+						for kw := range g.KernelW {
+							row, ok := g.InputRow(c, oh, ow, kh, kw)
+							if !ok {
+								continue
+							}
+							if v := float64(x.MustGet(int64(row), n)); v > best || arg < 0 {
+								best, arg = v, int64(row)
+							}
+						}
+					}
+
+					pl.argmax[outRow*N+n] = arg
+					if arg >= 0 {
+						O.MustSet(outRow, n, T(best))
+					}
+				}
+			}
+		}
+	}
+
+	pl.I = x
+	pl.O = O
+
+	return O, nil
+}
+
+func (pl *MaxPool2D[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validatePoolLoss(loss, pl.O); err != nil {
+		return nil, wrapPoolErr("MaxPool2D", "Backward", pl.Geom, err)
+	}
+
+	back := mat.New2D[T](uint64(pl.I.Rows()), uint64(pl.I.Cols()))
+	N := loss.Cols()
+
+	for i := range loss.Rows() {
+		for n := range N {
+			if arg := pl.argmax[i*N+n]; arg >= 0 {
+				back.MustSet(arg, n, back.MustGet(arg, n)+loss.MustGet(i, n))
+			}
+		}
+	}
+
+	return back, nil
+}
+
+func (pl *MaxPool2D[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return false, nil
+}
+
+func (pl *MaxPool2D[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	return fmt.Errorf("Error! MaxPool2D is unlearnable! ")
+}
+
+/*
+* AvgPool2D
+*
+* Mean over each kernel window of X[C*H*W, N], per channel, producing Y[C*OH*OW, N].
+* Padding taps count as zeros, so every window is divided by KH*KW.
+* Built on mat.Im2Col: window k of channel c is row c*KH*KW + k of the patch matrix.
+**/
+type AvgPool2D[T mat.Float] struct {
+	LayerIO[T]
+
+	Geom mat.ConvGeom
+}
+
+func NewAvgPool2D[T mat.Float](geom mat.ConvGeom) (*AvgPool2D[T], error) {
+	if err := geom.Validate(); err != nil {
+		return nil, fmt.Errorf("Failed to create AvgPool2D, reason { %s }", err)
+	}
+
+	return &AvgPool2D[T]{
+		Geom: geom.Normalized(),
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (pl *AvgPool2D[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validatePoolInput(x, pl.Geom); err != nil {
+		return nil, wrapPoolErr("AvgPool2D", "Forward", pl.Geom, err)
+	}
+
+	cols, err := mat.Im2Col(x, pl.Geom)
+	if err != nil {
+		return nil, wrapPoolErr("AvgPool2D", "Forward", pl.Geom, err)
+	}
+
+	g := pl.Geom
+	K := int64(g.KernelH * g.KernelW)
+	P := int64(g.OutH() * g.OutW())
+	N := x.Cols()
+	scale := 1 / T(K)
+
+	O := mat.New2D[T](g.Channels*uint64(P), uint64(N))
+
+	for c := range int64(g.Channels) {
+		for k := range K {
+			for p := range P {
+				for n := range N {
+					i := c*P + p
+					O.MustSet(i, n, O.MustGet(i, n)+scale*cols.MustGet(c*K+k, p*N+n))
+				}
+			}
+		}
+	}
+
+	pl.I = x
+	pl.O = O
+
+	return O, nil
+}
+
+func (pl *AvgPool2D[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validatePoolLoss(loss, pl.O); err != nil {
+		return nil, wrapPoolErr("AvgPool2D", "Backward", pl.Geom, err)
+	}
+
+	g := pl.Geom
+	K := int64(g.KernelH * g.KernelW)
+	P := int64(g.OutH() * g.OutW())
+	N := loss.Cols()
+	scale := 1 / T(K)
+
+	// every tap of a window receives an equal share of its output gradient
+	dCols := mat.New2D[T](g.PatchSize(), uint64(P*N))
+	for c := range int64(g.Channels) {
+		for k := range K {
+			for p := range P {
+				for n := range N {
+					dCols.MustSet(c*K+k, p*N+n, scale*loss.MustGet(c*P+p, n))
+				}
+			}
+		}
+	}
+
+	back, err := mat.Col2Im(dCols, g)
+	if err != nil {
+		return nil, wrapPoolErr("AvgPool2D", "Backward", pl.Geom, err)
+	}
+
+	return back, nil
+}
+
+func (pl *AvgPool2D[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return false, nil
+}
+
+func (pl *AvgPool2D[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	return fmt.Errorf("Error! AvgPool2D is unlearnable! ")
+}
+
+// ## private ##
+
+func validatePoolInput[T mat.Float](x *mat.Mat2D[T], g mat.ConvGeom) error {
+	if x == nil {
+		return fmt.Errorf("nil input")
+	}
+	if x.Rows() != int64(g.InSize()) {
+		return fmt.Errorf(
+			"Invalid input shape X[%d, %d], expected [%d, N]",
+			x.Rows(), x.Cols(), g.InSize(),
+		)
+	}
+	return nil
+}
+
+func validatePoolLoss[T mat.Float](loss, O *mat.Mat2D[T]) error {
+	if loss == nil {
+		return fmt.Errorf("nil loss")
+	}
+	if O == nil {
+		return fmt.Errorf("Backward called before Forward")
+	}
+	if !mat.DimsMatch(loss, O) {
+		return fmt.Errorf(
+			"loss[%d, %d] does not match output[%d, %d]",
+			loss.Rows(), loss.Cols(), O.Rows(), O.Cols(),
+		)
+	}
+	return nil
+}
+
+func wrapPoolErr(name, method string, g mat.ConvGeom, err error) error {
+	return fmt.Errorf(
+		"Failed to <%s([%d, %d, %d]) -> [%d, %d, %d]>::%s, reason: { %s }",
+		name,
+		g.Channels, g.Height, g.Width,
+		g.Channels, g.OutH(), g.OutW(),
+		method, err,
+	)
+}
@@ -0,0 +1,188 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math"
+	"math/rand"
+)
+
+/*
+* Sinusoidal Positional Encoding
+*
+* Adds the fixed encoding of "Attention Is All You Need" to every token of
+* X[steps*dModel, N]:
+*
+*   PE[t, 2i]   = sin(t / 10000^(2i / dModel))
+*   PE[t, 2i+1] = cos(t / 10000^(2i / dModel))
+**/
+type SinusoidalPE[T mat.Float] struct {
+	LayerIO[T]
+
+	dModel uint64
+}
+
+func NewSinusoidalPE[T mat.Float](dModel uint64) *SinusoidalPE[T] {
+	return &SinusoidalPE[T]{
+		dModel: dModel,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+// the encoding of steps tokens, laid out as a [steps*dModel, 1] column
+func SinusoidalEncoding[T mat.Float](steps, dModel uint64) *mat.Mat2D[T] {
+	pe := mat.New2D[T](steps*dModel, 1)
+
+	for t := range steps {
+		for i := uint64(0); i < dModel; i += 2 {
+			angle := float64(t) / math.Pow(10000, float64(i)/float64(dModel))
+
+			pe.MustSet(int64(t*dModel+i), 0, T(math.Sin(angle)))
+			if i+1 < dModel {
+				pe.MustSet(int64(t*dModel+i+1), 0, T(math.Cos(angle)))
+			}
+		}
+	}
+
+	return pe
+}
+
+func (pl *SinusoidalPE[T]) ISize() int64 {
+	return int64(pl.dModel)
+}
+
+func (pl *SinusoidalPE[T]) OSize() int64 {
+	return int64(pl.dModel)
+}
+
+func (pl *SinusoidalPE[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil || pl.dModel == 0 || x.Rows()%int64(pl.dModel) != 0 {
+		return nil, fmt.Errorf(
+			"Failed to SinusoidalPE::Forward, reason { input rows must be a multiple of dModel %d }",
+			pl.dModel,
+		)
+	}
+
+	steps := uint64(x.Rows()) / pl.dModel
+	O := mat.MustAdd(x, SinusoidalEncoding[T](steps, pl.dModel))
+
+	pl.I = x
+	pl.O = O
+
+	return O, nil
+}
+
+func (pl *SinusoidalPE[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, fmt.Errorf("Failed to SinusoidalPE::Backward, reason { nil loss }")
+	}
+	return loss.Clone(), nil
+}
+
+func (pl *SinusoidalPE[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return false, nil
+}
+
+func (pl *SinusoidalPE[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	return fmt.Errorf("Error! SinusoidalPE is unlearnable! ")
+}
+
+/*
+* Learned Positional Encoding
+*
+* W[maxSteps*dModel, 1] holds one learned vector per position and is added
+* to the tokens of X[steps*dModel, N], steps <= maxSteps.
+**/
+type LearnedPE[T mat.Float] struct {
+	LayerIO[T]
+
+	W     *mat.Mat2D[T] // weights
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	dModel, maxSteps uint64
+}
+
+func NewLearnedPE[T mat.Float](dModel, maxSteps uint64) *LearnedPE[T] {
+	return &LearnedPE[T]{
+		W:     mat.Rand[T](maxSteps*dModel, 1),
+		WGrad: nil,
+
+		dModel:   dModel,
+		maxSteps: maxSteps,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+func (pl *LearnedPE[T]) ISize() int64 {
+	return int64(pl.dModel)
+}
+
+func (pl *LearnedPE[T]) OSize() int64 {
+	return int64(pl.dModel)
+}
+
+func (pl *LearnedPE[T]) MaxSteps() int64 {
+	return int64(pl.maxSteps)
+}
+
+// there is no bias, all of W[maxSteps*dModel, 1] goes to weights
+func (pl *LearnedPE[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	if weights != nil {
+		weights(pl.W, weightinit.FansOf(pl.W), rng)
+	}
+}
+
+func (pl *LearnedPE[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil || pl.dModel == 0 || x.Rows()%int64(pl.dModel) != 0 || x.Rows() > pl.W.Rows() {
+		return nil, fmt.Errorf(
+			"Failed to LearnedPE::Forward, reason { input rows must be a multiple of dModel %d, up to %d steps }",
+			pl.dModel, pl.maxSteps,
+		)
+	}
+
+	O := mat.MustAdd(x, rowsOf(pl.W, 0, uint64(x.Rows())))
+
+	pl.I = x
+	pl.O = O
+
+	return O, nil
+}
+
+func (pl *LearnedPE[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil || loss.Rows() > pl.W.Rows() {
+		return nil, fmt.Errorf("Failed to LearnedPE::Backward, reason { invalid loss }")
+	}
+
+	// positions beyond the sequence got no gradient
+	WGrad := mat.New2D[T](uint64(pl.W.Rows()), 1)
+	rowsOf(WGrad, 0, uint64(loss.Rows())).MustCopy(loss.SumCols())
+	pl.WGrad = WGrad
+
+	return loss.Clone(), nil
+}
+
+func (pl *LearnedPE[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, pl.WGrad
+}
+
+func (pl *LearnedPE[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(pl.W, pl.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to LearnedPE::Learn, reason: { %s }", err)
+	}
+	pl.W = W
+	return nil
+}
@@ -0,0 +1,502 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
+	"slices"
+)
+
+/*
+* Recurrent layers (RNN, LSTM, GRU)
+*
+* ForwardSeq runs a cell over xs[t][iSize, N] and returns the hidden state
+* h[t][hSize, N] of every timestep, caching what BackwardSeq needs for
+* backpropagation through time. As a plain Layer, Forward takes the timesteps
+* stacked vertically, X[steps*iSize, N], and returns H[steps*hSize, N].
+*
+* All gates and the bias share a single weight matrix W (bias in column 0,
+* like LinearLayer) acting on [1; x[t]; h[t-1]], so the usual
+* IsLearnable/Learn pair covers the whole layer.
+*
+* TBPTT > 0 truncates backpropagation: the sequence is processed in chunks of
+* TBPTT steps and no state gradient flows across chunk boundaries.
+*
+* When Stateful, the final state of one ForwardSeq is the initial state of the
+* next (detached, no gradient flows into the previous batch), until ResetState.
+**/
+type recurrent[T mat.Float] struct {
+	LayerIO[T]
+
+	W     *mat.Mat2D[T] // weights
+	WGrad *mat.Mat2D[T] // weights gradient
+
+	TBPTT    int
+	Stateful bool
+
+	iSize, hSize uint64
+
+	cell   recurrentCell[T]
+	state  []*mat.Mat2D[T] // carried state when Stateful
+	caches []any           // per timestep cell caches of the last ForwardSeq
+}
+
+// a single timestep of a recurrent layer
+type recurrentCell[T mat.Float] interface {
+	name() string
+	stateCount() int // 1 for [h], 2 for [h, c]
+	gates() uint64   // W has gates * hSize rows
+
+	forward(W, x *mat.Mat2D[T], state []*mat.Mat2D[T]) (next []*mat.Mat2D[T], cache any)
+	backward(W *mat.Mat2D[T], cache any, dNext []*mat.Mat2D[T]) (dx *mat.Mat2D[T], dPrev []*mat.Mat2D[T], dW *mat.Mat2D[T])
+}
+
+func newRecurrent[T mat.Float](iSize, hSize uint64, cell recurrentCell[T]) recurrent[T] {
+	W := mat.Rand[T](cell.gates()*hSize, 1+iSize+hSize)
+
+	return recurrent[T]{
+		W:     W,
+		WGrad: nil,
+
+		TBPTT:    0,
+		Stateful: false,
+
+		iSize: iSize,
+		hSize: hSize,
+
+		cell: cell,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+func (rl *recurrent[T]) ISize() int64 {
+	return int64(rl.iSize)
+}
+
+func (rl *recurrent[T]) OSize() int64 {
+	return int64(rl.hSize)
+}
+
+// each gate is its own [hSize, 1 + iSize + hSize] layer, initialized on its own
+func (rl *recurrent[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	for g := range rl.cell.gates() {
+		initBiased(rowsOf(rl.W, g*rl.hSize, (g+1)*rl.hSize), 1, weights, bias, rng)
+	}
+}
+
+// clears the state carried between batches of a Stateful layer
+func (rl *recurrent[T]) ResetState() {
+	rl.state = nil
+}
+
+func (rl *recurrent[T]) ForwardSeq(xs []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+	if len(xs) == 0 {
+		return nil, rl.wrapForwardErr(fmt.Errorf("empty sequence"))
+	}
+
+	N := xs[0].Cols()
+	for t, x := range xs {
+		if x == nil || x.Rows() != int64(rl.iSize) || x.Cols() != N {
+			return nil, rl.wrapForwardErr(fmt.Errorf(
+				"Invalid input at timestep %d, expected x[%d, %d]",
+				t, rl.iSize, N,
+			))
+		}
+	}
+
+	state, err := rl.initialState(N)
+	if err != nil {
+		return nil, rl.wrapForwardErr(err)
+	}
+
+	hs := make([]*mat.Mat2D[T], len(xs))
+	rl.caches = make([]any, len(xs))
+
+	for t, x := range xs {
+		state, rl.caches[t] = rl.cell.forward(rl.W, x, state)
+		hs[t] = state[0]
+	}
+
+	if rl.Stateful {
+		rl.state = make([]*mat.Mat2D[T], len(state))
+		for i, s := range state {
+			rl.state[i] = s.Clone()
+		}
+	}
+
+	return hs, nil
+}
+
+func (rl *recurrent[T]) BackwardSeq(losses []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+	if rl.caches == nil {
+		return nil, rl.wrapBackwardErr(fmt.Errorf("BackwardSeq called before ForwardSeq"))
+	}
+	if len(losses) != len(rl.caches) {
+		return nil, rl.wrapBackwardErr(fmt.Errorf(
+			"expected %d timestep losses, found %d",
+			len(rl.caches), len(losses),
+		))
+	}
+
+	N := losses[0].Cols()
+	for t, loss := range losses {
+		if loss == nil || loss.Rows() != int64(rl.hSize) || loss.Cols() != N {
+			return nil, rl.wrapBackwardErr(fmt.Errorf(
+				"Invalid loss at timestep %d, expected [%d, %d]",
+				t, rl.hSize, N,
+			))
+		}
+	}
+
+	dxs := make([]*mat.Mat2D[T], len(losses))
+	WGrad := mat.New2D[T](uint64(rl.W.Rows()), uint64(rl.W.Cols()))
+
+	dState := rl.zeroState(N)
+	for t := len(losses) - 1; t >= 0; t-- {
+		if rl.TBPTT > 0 && (t+1)%rl.TBPTT == 0 && t != len(losses)-1 {
+			// chunk boundary, drop the gradient from the future
+			dState = rl.zeroState(N)
+		}
+
+		dState[0] = mat.MustAdd(dState[0], losses[t])
+
+		dx, dPrev, dW := rl.cell.backward(rl.W, rl.caches[t], dState)
+		WGrad.MustAdd(dW)
+
+		dxs[t] = dx
+		dState = dPrev
+	}
+
+	rl.WGrad = WGrad
+
+	return dxs, nil
+}
+
+func (rl *recurrent[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, rl.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+
+	xs, err := splitSteps(x, rl.iSize)
+	if err != nil {
+		return nil, rl.wrapForwardErr(err)
+	}
+
+	hs, err := rl.ForwardSeq(xs)
+	if err != nil {
+		return nil, err
+	}
+
+	O, err := mat.VCat(hs...)
+	if err != nil {
+		return nil, rl.wrapForwardErr(err)
+	}
+
+	rl.I = x
+	rl.O = O
+
+	return O, nil
+}
+
+func (rl *recurrent[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, rl.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+
+	losses, err := splitSteps(loss, rl.hSize)
+	if err != nil {
+		return nil, rl.wrapBackwardErr(err)
+	}
+
+	dxs, err := rl.BackwardSeq(losses)
+	if err != nil {
+		return nil, err
+	}
+
+	back, err := mat.VCat(dxs...)
+	if err != nil {
+		return nil, rl.wrapBackwardErr(err)
+	}
+
+	return back, nil
+}
+
+func (rl *recurrent[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, rl.WGrad
+}
+
+func (rl *recurrent[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	W, err := learnWeights(rl.W, rl.WGrad, updateWeights)
+	if err != nil {
+		return fmt.Errorf("Failed to %s::Learn, reason: { %s }", rl.shapeRep(), err)
+	}
+	rl.W = W
+	return nil
+}
+
+/*
+* Bidirectional
+*
+* Runs Fwd over the sequence and Bwd over the reversed sequence,
+* output t is [Fwd h[t]; Bwd h[t]] of size Fwd.OSize() + Bwd.OSize().
+**/
+type Bidirectional[T mat.Float] struct {
+	LayerIO[T]
+	Mode
+
+	Fwd SequenceLayer[T]
+	Bwd SequenceLayer[T]
+}
+
+func NewBidirectional[T mat.Float](fwd, bwd SequenceLayer[T]) (*Bidirectional[T], error) {
+	if fwd.ISize() != bwd.ISize() {
+		return nil, fmt.Errorf(
+			"Failed to create Bidirectional, reason { mismatched input sizes %d != %d }",
+			fwd.ISize(), bwd.ISize(),
+		)
+	}
+
+	return &Bidirectional[T]{
+		Fwd: fwd,
+		Bwd: bwd,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (bl *Bidirectional[T]) ISize() int64 {
+	return bl.Fwd.ISize()
+}
+
+func (bl *Bidirectional[T]) OSize() int64 {
+	return bl.Fwd.OSize() + bl.Bwd.OSize()
+}
+
+func (bl *Bidirectional[T]) ForwardSeq(xs []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+	fwd, err := bl.Fwd.ForwardSeq(xs)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { %s }", err)
+	}
+
+	bwd, err := bl.Bwd.ForwardSeq(reversed(xs))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { %s }", err)
+	}
+	bwd = reversed(bwd)
+
+	out := make([]*mat.Mat2D[T], len(xs))
+	for t := range xs {
+		if out[t], err = mat.VCat(fwd[t], bwd[t]); err != nil {
+			return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { %s }", err)
+		}
+	}
+
+	return out, nil
+}
+
+func (bl *Bidirectional[T]) BackwardSeq(losses []*mat.Mat2D[T]) ([]*mat.Mat2D[T], error) {
+	fwdLosses := make([]*mat.Mat2D[T], len(losses))
+	bwdLosses := make([]*mat.Mat2D[T], len(losses))
+
+	split := bl.Fwd.OSize()
+	for t, loss := range losses {
+		if loss == nil || loss.Rows() != bl.OSize() {
+			return nil, fmt.Errorf(
+				"Failed to Bidirectional::Backward, reason { Invalid loss at timestep %d, expected %d rows }",
+				t, bl.OSize(),
+			)
+		}
+		fwdLosses[t] = loss.MustSlice(mat.RS{0, split}, mat.CS{0, loss.Cols()})
+		bwdLosses[t] = loss.MustSlice(mat.RS{split, loss.Rows()}, mat.CS{0, loss.Cols()})
+	}
+
+	dFwd, err := bl.Fwd.BackwardSeq(fwdLosses)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Backward, reason { %s }", err)
+	}
+
+	dBwd, err := bl.Bwd.BackwardSeq(reversed(bwdLosses))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Backward, reason { %s }", err)
+	}
+	dBwd = reversed(dBwd)
+
+	dxs := make([]*mat.Mat2D[T], len(losses))
+	for t := range losses {
+		dxs[t] = mat.MustAdd(dFwd[t], dBwd[t])
+	}
+
+	return dxs, nil
+}
+
+func (bl *Bidirectional[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { nil input }")
+	}
+
+	xs, err := splitSteps(x, uint64(bl.ISize()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { %s }", err)
+	}
+
+	out, err := bl.ForwardSeq(xs)
+	if err != nil {
+		return nil, err
+	}
+
+	O, err := mat.VCat(out...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Forward, reason { %s }", err)
+	}
+
+	bl.I = x
+	bl.O = O
+
+	return O, nil
+}
+
+func (bl *Bidirectional[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Backward, reason { nil loss }")
+	}
+
+	losses, err := splitSteps(loss, uint64(bl.OSize()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Backward, reason { %s }", err)
+	}
+
+	dxs, err := bl.BackwardSeq(losses)
+	if err != nil {
+		return nil, err
+	}
+
+	back, err := mat.VCat(dxs...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Bidirectional::Backward, reason { %s }", err)
+	}
+
+	return back, nil
+}
+
+func (bl *Bidirectional[T]) SetTraining(training bool) {
+	bl.Mode.SetTraining(training)
+	setTraining[T](training, bl.Fwd, bl.Bwd)
+}
+
+func (bl *Bidirectional[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	initAll[T](weights, bias, rng, bl.Fwd, bl.Bwd)
+}
+
+func (bl *Bidirectional[T]) Params() []Learnable[T] {
+	return []Learnable[T]{bl.Fwd, bl.Bwd}
+}
+
+func (bl *Bidirectional[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, nil
+}
+
+func (bl *Bidirectional[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	if err := bl.Fwd.Learn(updateWeights); err != nil {
+		return fmt.Errorf("Failed to Bidirectional::Learn, reason { %s }", err)
+	}
+	if err := bl.Bwd.Learn(updateWeights); err != nil {
+		return fmt.Errorf("Failed to Bidirectional::Learn, reason { %s }", err)
+	}
+	return nil
+}
+
+// ## private ##
+
+func (rl *recurrent[T]) initialState(N int64) ([]*mat.Mat2D[T], error) {
+	if !rl.Stateful || rl.state == nil {
+		return rl.zeroState(N), nil
+	}
+
+	if rl.state[0].Cols() != N {
+		return nil, fmt.Errorf(
+			"stateful batch size changed from %d to %d, call ResetState first",
+			rl.state[0].Cols(), N,
+		)
+	}
+	return rl.state, nil
+}
+
+func (rl *recurrent[T]) zeroState(N int64) []*mat.Mat2D[T] {
+	state := make([]*mat.Mat2D[T], rl.cell.stateCount())
+	for i := range state {
+		state[i] = mat.New2D[T](rl.hSize, uint64(N))
+	}
+	return state
+}
+
+func (rl *recurrent[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		rl.shapeRep(),
+		err,
+	)
+}
+
+func (rl *recurrent[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		rl.shapeRep(),
+		err,
+	)
+}
+
+func (rl *recurrent[T]) shapeRep() string {
+	return fmt.Sprintf(
+		"<%s([%d, N] x steps) -> [%d, N] x steps>",
+		rl.cell.name(), rl.iSize, rl.hSize,
+	)
+}
+
+// splits X[steps*size, N] into steps views of [size, N]
+func splitSteps[T mat.Float](x *mat.Mat2D[T], size uint64) ([]*mat.Mat2D[T], error) {
+	if size == 0 || x.Rows() == 0 || x.Rows()%int64(size) != 0 {
+		return nil, fmt.Errorf(
+			"Invalid sequence shape [%d, %d], rows must be a multiple of %d",
+			x.Rows(), x.Cols(), size,
+		)
+	}
+
+	steps := make([]*mat.Mat2D[T], x.Rows()/int64(size))
+	for t := range steps {
+		start := int64(t) * int64(size)
+		step, err := x.Slice(mat.RS{start, start + int64(size)}, mat.CS{0, x.Cols()})
+		if err != nil {
+			return nil, err
+		}
+		steps[t] = step
+	}
+
+	return steps, nil
+}
+
+func reversed[T mat.Float](xs []*mat.Mat2D[T]) []*mat.Mat2D[T] {
+	rev := slices.Clone(xs)
+	slices.Reverse(rev)
+	return rev
+}
+
+// rows [from, to) of m
+func rowsOf[T mat.Float](m *mat.Mat2D[T], from, to uint64) *mat.Mat2D[T] {
+	return m.MustSlice(mat.RS{int64(from), int64(to)}, mat.CS{0, m.Cols()})
+}
+
+func applied[T mat.Float](m *mat.Mat2D[T], f func(T) T) *mat.Mat2D[T] {
+	return m.Clone().Apply(f)
+}
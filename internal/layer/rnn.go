@@ -0,0 +1,58 @@
+package layer
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/mat"
+)
+
+/*
+* RNN (Elman)
+*
+* h[t] = tanh(W * [1; x[t]; h[t-1]]), W[hSize, 1 + iSize + hSize]
+**/
+type RNN[T mat.Float] struct {
+	recurrent[T]
+}
+
+func NewRNN[T mat.Float](iSize, hSize uint64) *RNN[T] {
+	return &RNN[T]{
+		recurrent: newRecurrent[T](iSize, hSize, &rnnCell[T]{iSize: iSize}),
+	}
+}
+
+type rnnCell[T mat.Float] struct {
+	iSize uint64
+}
+
+type rnnCache[T mat.Float] struct {
+	z *mat.Mat2D[T] // [1; x; h[t-1]]
+	h *mat.Mat2D[T] // h[t]
+}
+
+func (c *rnnCell[T]) name() string    { return "RNN" }
+func (c *rnnCell[T]) stateCount() int { return 1 }
+func (c *rnnCell[T]) gates() uint64   { return 1 }
+
+func (c *rnnCell[T]) forward(W, x *mat.Mat2D[T], state []*mat.Mat2D[T]) ([]*mat.Mat2D[T], any) {
+	z := mat.MustVCat(mat.Ones[T](1, uint64(x.Cols())), x, state[0])
+	h := mat.MustMatMul(W, z).Apply(acti.Tanh[T])
+
+	return []*mat.Mat2D[T]{h}, &rnnCache[T]{z: z, h: h}
+}
+
+func (c *rnnCell[T]) backward(
+	W *mat.Mat2D[T], cache any, dNext []*mat.Mat2D[T],
+) (*mat.Mat2D[T], []*mat.Mat2D[T], *mat.Mat2D[T]) {
+	rc := cache.(*rnnCache[T])
+
+	// da = dh * (1 - h^2)
+	da := mat.MustMul(dNext[0], applied(rc.h, func(h T) T { return 1 - h*h }))
+
+	dW := mat.MustMatMul(da, rc.z.TP())
+	dz := mat.MustMatMul(W.TP(), da)
+
+	dx := rowsOf(dz, 1, 1+c.iSize)
+	dh := rowsOf(dz, 1+c.iSize, uint64(dz.Rows()))
+
+	return dx, []*mat.Mat2D[T]{dh}, dW
+}
@@ -0,0 +1,157 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
+)
+
+/*
+* Sequential
+*
+* Chains Forward through its children in order and Backward in reverse,
+* so a whole model can be used anywhere a single Layer is expected.
+**/
+type Sequential[T mat.Float] struct {
+	LayerIO[T]
+	Mode
+
+	Layers []Layer[T]
+}
+
+func NewSequential[T mat.Float](layers ...Layer[T]) *Sequential[T] {
+	return &Sequential[T]{
+		Layers: layers,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}
+}
+
+func (s *Sequential[T]) Len() int {
+	return len(s.Layers)
+}
+
+// appended layers are switched to the mode of the model
+func (s *Sequential[T]) Append(layers ...Layer[T]) *Sequential[T] {
+	setTraining(s.IsTraining(), layers...)
+	s.Layers = append(s.Layers, layers...)
+	return s
+}
+
+// switches the model and every child that has a mode (see ModeSetter)
+func (s *Sequential[T]) SetTraining(training bool) {
+	s.Mode.SetTraining(training)
+	setTraining(training, s.Layers...)
+}
+
+func (s *Sequential[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if len(s.Layers) == 0 {
+		return nil, fmt.Errorf("Failed to Sequential::Forward, reason { %s }", "zero length model")
+	}
+	if x == nil || x.Cols() == 0 {
+		return nil, fmt.Errorf("Failed to Sequential::Forward, reason { %s }", "x is nil or zero length")
+	}
+
+	var inp, out *mat.Mat2D[T] = x, nil
+	var err error
+
+	for i, layer := range s.Layers {
+		out, err = layer.Forward(inp)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"model forwarding failed at layer[%d of %d] with input[%d, %d], reason: { %s }",
+				i+1, len(s.Layers),
+				inp.Rows(), inp.Cols(),
+				err,
+			)
+		}
+		inp = out
+	}
+
+	s.I = x
+	s.O = out
+
+	return out, nil
+}
+
+func (s *Sequential[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if len(s.Layers) == 0 {
+		return nil, fmt.Errorf("Failed to Sequential::Backward, reason { %s }", "zero length model")
+	}
+	if loss == nil || loss.Cols() == 0 {
+		return nil, fmt.Errorf("Failed to Sequential::Backward, reason { %s }", "loss is nil or zero length")
+	}
+
+	grad := loss
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		back, err := s.Layers[i].Backward(grad)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"model backprop failed at layer[%d of %d] with loss[%d, %d], reason: { %s }",
+				i+1, len(s.Layers),
+				grad.Rows(), grad.Cols(),
+				err,
+			)
+		}
+		grad = back
+	}
+
+	return grad, nil
+}
+
+// initializes every child that is Initializable, in order, so a seeded rng gives the same model
+func (s *Sequential[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	initAll(weights, bias, rng, s.Layers...)
+}
+
+/*
+* Params
+*
+* Returns every learnable child, flattening nested containers,
+* in the order they appear in the model.
+**/
+func (s *Sequential[T]) Params() []Learnable[T] {
+	params := []Learnable[T]{}
+
+	for _, layer := range s.Layers {
+		if container, ok := layer.(Container[T]); ok {
+			params = append(params, container.Params()...)
+			continue
+		}
+
+		if learnable, _ := layer.IsLearnable(); learnable {
+			params = append(params, layer)
+		}
+	}
+
+	return params
+}
+
+// A Sequential is learnable if any of its children are, its gradients live
+// on the children (see Params), so no single gradient is returned.
+func (s *Sequential[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return len(s.Params()) > 0, nil
+}
+
+func (s *Sequential[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	for i, layer := range s.Layers {
+		if learnable, _ := layer.IsLearnable(); !learnable {
+			continue
+		}
+
+		if err := layer.Learn(updateWeights); err != nil {
+			return fmt.Errorf(
+				"model learning failed at layer[%d of %d], reason: { %s }",
+				i+1, len(s.Layers), err,
+			)
+		}
+	}
+
+	return nil
+}
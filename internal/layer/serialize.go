@@ -0,0 +1,630 @@
+package layer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"gonn/internal/mat"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+/*
+* Binary model format (little endian)
+*
+*	magic    [4]byte  "GONN"
+*	version  uint16
+*	dtype    uint8    (mat.DTypeF32 | mat.DTypeF64)
+*	layers   uint32 count, then per layer:
+*	           tag     string (uint16 length + bytes)
+*	           payload layer specific hyperparameters and mat.Mat2D weights
+*	checksum uint32   CRC-32 (IEEE) of every preceding byte
+*
+* Readers accept any version up to ModelFormatVersion, so files keep
+* loading after upgrades, new layer types only add new tags.
+*
+* Only weights and hyperparameters are saved, no random state: a loaded
+* Dropout draws its masks from its construction seed again, not from where
+* the saved one's RNG had got to.
+**/
+
+const ModelFormatVersion uint16 = 1
+
+var modelMagic = [4]byte{'G', 'O', 'N', 'N'}
+
+const (
+	tagLinear     = "linear"
+	tagActivation = "activation"
+	tagSequential = "sequential"
+	tagConv2D     = "conv2d"
+	tagMaxPool2D  = "maxpool2d"
+	tagAvgPool2D  = "avgpool2d"
+
+	tagRNN           = "rnn"
+	tagLSTM          = "lstm"
+	tagGRU           = "gru"
+	tagBidirectional = "bidirectional"
+
+	tagMultiHeadAttention = "mha"
+	tagLayerNorm          = "layernorm"
+	tagBatchNorm          = "batchnorm"
+	tagDropout            = "dropout"
+	tagSinusoidalPE       = "sinusoidalpe"
+	tagLearnedPE          = "learnedpe"
+	tagTransformerEncoder = "transformerencoder"
+)
+
+func Save[T mat.Float](w io.Writer, layers ...Layer[T]) error {
+	crc := crc32.NewIEEE()
+	e := &encoder{w: io.MultiWriter(w, crc)}
+
+	e.write(modelMagic)
+	e.write(ModelFormatVersion)
+	e.write(mat.DType[T]())
+	if err := encodeLayers(e, layers); err != nil {
+		return fmt.Errorf("Failed to save model, reason { %s }", err)
+	}
+	if e.err != nil {
+		return fmt.Errorf("Failed to save model, reason { %s }", e.err)
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, crc.Sum32()); err != nil {
+		return fmt.Errorf("Failed to save model checksum, reason { %s }", err)
+	}
+	return nil
+}
+
+/*
+* Load
+*
+* Reads layers written by Save, weights stored with a
+* different dtype than T are converted.
+**/
+func Load[T mat.Float](r io.Reader) ([]Layer[T], error) {
+	crc := crc32.NewIEEE()
+	d := &decoder{r: io.TeeReader(r, crc)}
+
+	var magic [4]byte
+	var version uint16
+	var dtype uint8
+	d.read(&magic)
+	d.read(&version)
+	d.read(&dtype)
+	if d.err != nil {
+		return nil, fmt.Errorf("Failed to load model header, reason { %s }", d.err)
+	}
+
+	if magic != modelMagic {
+		return nil, fmt.Errorf("Failed to load model, reason { bad magic %q }", magic[:])
+	}
+	if version == 0 || version > ModelFormatVersion {
+		return nil, fmt.Errorf(
+			"Failed to load model, reason { unsupported version %d, latest is %d }",
+			version, ModelFormatVersion,
+		)
+	}
+
+	layers, err := decodeLayers[T](d)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load model, reason { %s }", err)
+	}
+	expected := crc.Sum32()
+
+	var checksum uint32
+	if err := binary.Read(r, binary.LittleEndian, &checksum); err != nil {
+		return nil, fmt.Errorf("Failed to load model checksum, reason { %s }", err)
+	}
+	if checksum != expected {
+		return nil, fmt.Errorf(
+			"Failed to load model, reason { checksum mismatch %08x != %08x }",
+			checksum, expected,
+		)
+	}
+
+	return layers, nil
+}
+
+func SaveFile[T mat.Float](path string, layers ...Layer[T]) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Failed to save model, reason { %s }", err)
+	}
+
+	if err := Save(f, layers...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func LoadFile[T mat.Float](path string) ([]Layer[T], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load model, reason { %s }", err)
+	}
+	defer f.Close()
+
+	return Load[T](f)
+}
+
+// vvv PRIVATE vvv
+
+func encodeLayers[T mat.Float](e *encoder, layers []Layer[T]) error {
+	e.write(uint32(len(layers)))
+
+	for i, layer := range layers {
+		if err := encodeLayer(e, layer); err != nil {
+			return fmt.Errorf("layer[%d of %d]: %s", i+1, len(layers), err)
+		}
+	}
+	return e.err
+}
+
+func encodeLayer[T mat.Float](e *encoder, layer Layer[T]) error {
+	switch l := layer.(type) {
+	case *LinearLayer[T]:
+		e.str(tagLinear)
+		e.write(l.iSize)
+		e.write(l.oSize)
+		writeMat(e, l.W)
+
+	case *ActivationLayer[T]:
+		if l.Name == "" {
+			return fmt.Errorf(
+				"ActivationLayer with custom functions has no identity to save, use NewALByName",
+			)
+		}
+		e.str(tagActivation)
+		e.str(l.Name)
+
+	case *Sequential[T]:
+		e.str(tagSequential)
+		return encodeLayers(e, l.Layers)
+
+	case *Conv2D[T]:
+		e.str(tagConv2D)
+		e.write(l.Geom)
+		e.write(l.OutChannels)
+		writeMat(e, l.W)
+
+	case *MaxPool2D[T]:
+		e.str(tagMaxPool2D)
+		e.write(l.Geom)
+
+	case *AvgPool2D[T]:
+		e.str(tagAvgPool2D)
+		e.write(l.Geom)
+
+	case *RNN[T]:
+		encodeRecurrent(e, tagRNN, &l.recurrent)
+
+	case *LSTM[T]:
+		encodeRecurrent(e, tagLSTM, &l.recurrent)
+
+	case *GRU[T]:
+		encodeRecurrent(e, tagGRU, &l.recurrent)
+
+	case *Bidirectional[T]:
+		e.str(tagBidirectional)
+		if err := encodeLayer(e, l.Fwd); err != nil {
+			return err
+		}
+		return encodeLayer(e, l.Bwd)
+
+	case *MultiHeadAttention[T]:
+		e.str(tagMultiHeadAttention)
+		e.write(l.dModel)
+		e.write(l.heads)
+		e.write(l.Causal)
+		writeMat(e, l.W)
+
+	case *LayerNorm[T]:
+		e.str(tagLayerNorm)
+		e.write(l.features)
+		e.write(float64(l.Eps))
+		writeMat(e, l.W)
+
+	case *BatchNorm[T]:
+		e.str(tagBatchNorm)
+		e.write(l.features)
+		e.write(float64(l.Eps))
+		e.write(float64(l.Momentum))
+		writeMat(e, l.W)
+		writeMat(e, l.RunningMean)
+		writeMat(e, l.RunningVar)
+
+	case *Dropout[T]:
+		// the seed restarts the mask stream on load, see the format doc above
+		e.str(tagDropout)
+		e.write(float64(l.Rate))
+		e.write(l.seed)
+
+	case *SinusoidalPE[T]:
+		e.str(tagSinusoidalPE)
+		e.write(l.dModel)
+
+	case *LearnedPE[T]:
+		e.str(tagLearnedPE)
+		e.write(l.dModel)
+		e.write(l.maxSteps)
+		writeMat(e, l.W)
+
+	case *TransformerEncoderBlock[T]:
+		e.str(tagTransformerEncoder)
+		e.write(l.dModel)
+		e.write(l.Attention.heads)
+		e.write(l.ffSize)
+		for _, sub := range []Layer[T]{l.Attention, l.Norm1, l.FFN, l.Norm2} {
+			if err := encodeLayer(e, sub); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported layer type %T", layer)
+	}
+
+	return e.err
+}
+
+func decodeLayers[T mat.Float](d *decoder) ([]Layer[T], error) {
+	var count uint32
+	d.read(&count)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	layers := make([]Layer[T], 0, min(count, 1024))
+	for i := range count {
+		layer, err := decodeLayer[T](d)
+		if err != nil {
+			return nil, fmt.Errorf("layer[%d of %d]: %s", i+1, count, err)
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+func decodeLayer[T mat.Float](d *decoder) (Layer[T], error) {
+	tag := d.str()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	switch tag {
+	case tagLinear:
+		var iSize, oSize uint64
+		d.read(&iSize)
+		d.read(&oSize)
+		W := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		ll := NewLL[T](iSize, oSize)
+		if err := matchWeights("LinearLayer", ll.W, W); err != nil {
+			return nil, err
+		}
+		ll.W = W
+		return ll, nil
+
+	case tagActivation:
+		name := d.str()
+		if d.err != nil {
+			return nil, d.err
+		}
+		return NewALByName[T](name)
+
+	case tagSequential:
+		layers, err := decodeLayers[T](d)
+		if err != nil {
+			return nil, err
+		}
+		return NewSequential(layers...), nil
+
+	case tagConv2D:
+		var geom mat.ConvGeom
+		var outChannels uint64
+		d.read(&geom)
+		d.read(&outChannels)
+		W := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		cl, err := NewConv2D[T](geom, outChannels)
+		if err != nil {
+			return nil, err
+		}
+		if err := matchWeights("Conv2D", cl.W, W); err != nil {
+			return nil, err
+		}
+		cl.W = W
+		return cl, nil
+
+	case tagMaxPool2D, tagAvgPool2D:
+		var geom mat.ConvGeom
+		d.read(&geom)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		if tag == tagMaxPool2D {
+			return NewMaxPool2D[T](geom)
+		}
+		return NewAvgPool2D[T](geom)
+
+	case tagRNN, tagLSTM, tagGRU:
+		return decodeRecurrent[T](d, tag)
+
+	case tagBidirectional:
+		var dirs [2]SequenceLayer[T]
+		for i := range dirs {
+			layer, err := decodeLayer[T](d)
+			if err != nil {
+				return nil, err
+			}
+			seq, ok := layer.(SequenceLayer[T])
+			if !ok {
+				return nil, fmt.Errorf("Bidirectional direction %T is not a SequenceLayer", layer)
+			}
+			dirs[i] = seq
+		}
+		return NewBidirectional(dirs[0], dirs[1])
+
+	case tagMultiHeadAttention:
+		var dModel, heads uint64
+		var causal bool
+		d.read(&dModel)
+		d.read(&heads)
+		d.read(&causal)
+		W := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		al, err := NewMultiHeadAttention[T](dModel, heads)
+		if err != nil {
+			return nil, err
+		}
+		if err := matchWeights("MultiHeadAttention", al.W, W); err != nil {
+			return nil, err
+		}
+		al.W = W
+		al.Causal = causal
+		return al, nil
+
+	case tagLayerNorm:
+		var features uint64
+		var eps float64
+		d.read(&features)
+		d.read(&eps)
+		W := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		nl := NewLayerNorm[T](features)
+		if err := matchWeights("LayerNorm", nl.W, W); err != nil {
+			return nil, err
+		}
+		nl.W = W
+		nl.Eps = T(eps)
+		return nl, nil
+
+	case tagBatchNorm:
+		var features uint64
+		var eps, momentum float64
+		d.read(&features)
+		d.read(&eps)
+		d.read(&momentum)
+		W := readMat[T](d)
+		runningMean := readMat[T](d)
+		runningVar := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		bl := NewBatchNorm[T](features)
+		for _, pair := range [][2]*mat.Mat2D[T]{
+			{bl.W, W}, {bl.RunningMean, runningMean}, {bl.RunningVar, runningVar},
+		} {
+			if err := matchWeights("BatchNorm", pair[0], pair[1]); err != nil {
+				return nil, err
+			}
+		}
+		bl.W, bl.RunningMean, bl.RunningVar = W, runningMean, runningVar
+		bl.Eps, bl.Momentum = T(eps), T(momentum)
+		return bl, nil
+
+	case tagDropout:
+		var rate float64
+		var seed int64
+		d.read(&rate)
+		d.read(&seed)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return NewDropout(T(rate), seed)
+
+	case tagSinusoidalPE:
+		var dModel uint64
+		d.read(&dModel)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return NewSinusoidalPE[T](dModel), nil
+
+	case tagLearnedPE:
+		var dModel, maxSteps uint64
+		d.read(&dModel)
+		d.read(&maxSteps)
+		W := readMat[T](d)
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		pl := NewLearnedPE[T](dModel, maxSteps)
+		if err := matchWeights("LearnedPE", pl.W, W); err != nil {
+			return nil, err
+		}
+		pl.W = W
+		return pl, nil
+
+	case tagTransformerEncoder:
+		return decodeTransformerEncoder[T](d)
+	}
+
+	return nil, fmt.Errorf("unknown layer tag %q", tag)
+}
+
+func encodeRecurrent[T mat.Float](e *encoder, tag string, rl *recurrent[T]) {
+	e.str(tag)
+	e.write(rl.iSize)
+	e.write(rl.hSize)
+	e.write(int64(rl.TBPTT))
+	e.write(rl.Stateful)
+	writeMat(e, rl.W)
+}
+
+func decodeRecurrent[T mat.Float](d *decoder, tag string) (Layer[T], error) {
+	var iSize, hSize uint64
+	var tbptt int64
+	var stateful bool
+	d.read(&iSize)
+	d.read(&hSize)
+	d.read(&tbptt)
+	d.read(&stateful)
+	W := readMat[T](d)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var layer Layer[T]
+	var rl *recurrent[T]
+	switch tag {
+	case tagRNN:
+		l := NewRNN[T](iSize, hSize)
+		layer, rl = l, &l.recurrent
+	case tagLSTM:
+		l := NewLSTM[T](iSize, hSize)
+		layer, rl = l, &l.recurrent
+	default:
+		l := NewGRU[T](iSize, hSize)
+		layer, rl = l, &l.recurrent
+	}
+
+	if err := matchWeights(rl.cell.name(), rl.W, W); err != nil {
+		return nil, err
+	}
+	rl.W = W
+	rl.TBPTT = int(tbptt)
+	rl.Stateful = stateful
+
+	return layer, nil
+}
+
+func decodeTransformerEncoder[T mat.Float](d *decoder) (Layer[T], error) {
+	var dModel, heads, ffSize uint64
+	d.read(&dModel)
+	d.read(&heads)
+	d.read(&ffSize)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	tb, err := NewTransformerEncoderBlock[T](dModel, heads, ffSize)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]Layer[T], 4)
+	for i := range subs {
+		if subs[i], err = decodeLayer[T](d); err != nil {
+			return nil, err
+		}
+	}
+
+	attention, ok1 := subs[0].(*MultiHeadAttention[T])
+	norm1, ok2 := subs[1].(*LayerNorm[T])
+	ffn, ok3 := subs[2].(*Sequential[T])
+	norm2, ok4 := subs[3].(*LayerNorm[T])
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, fmt.Errorf("TransformerEncoderBlock has unexpected sublayers")
+	}
+	if attention.dModel != dModel || norm1.features != dModel || norm2.features != dModel {
+		return nil, fmt.Errorf("TransformerEncoderBlock sublayers do not match dModel %d", dModel)
+	}
+
+	tb.Attention, tb.Norm1, tb.FFN, tb.Norm2 = attention, norm1, ffn, norm2
+	return tb, nil
+}
+
+// loaded weights must have the shape the constructor allocated
+func matchWeights[T mat.Float](name string, current, loaded *mat.Mat2D[T]) error {
+	if !mat.DimsMatch(current, loaded) {
+		return fmt.Errorf(
+			"%s weights W[%d, %d] do not match [%d, %d]",
+			name, loaded.Rows(), loaded.Cols(), current.Rows(), current.Cols(),
+		)
+	}
+	return nil
+}
+
+// sticky error writer, the first failure is kept in err and later writes are skipped
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *encoder) write(v any) {
+	if e.err == nil {
+		e.err = binary.Write(e.w, binary.LittleEndian, v)
+	}
+}
+
+func (e *encoder) str(s string) {
+	e.write(uint16(len(s)))
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+func writeMat[T mat.Float](e *encoder, m *mat.Mat2D[T]) {
+	if e.err == nil {
+		_, e.err = m.WriteTo(e.w)
+	}
+}
+
+// sticky error reader, mirrors encoder
+type decoder struct {
+	r   io.Reader
+	err error
+}
+
+func (d *decoder) read(v any) {
+	if d.err == nil {
+		d.err = binary.Read(d.r, binary.LittleEndian, v)
+	}
+}
+
+func (d *decoder) str() string {
+	var n uint16
+	d.read(&n)
+	if d.err != nil {
+		return ""
+	}
+
+	buf := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, buf)
+	return string(buf)
+}
+
+func readMat[T mat.Float](d *decoder) *mat.Mat2D[T] {
+	if d.err != nil {
+		return nil
+	}
+	m, err := mat.ReadMat2D[T](d.r)
+	d.err = err
+	return m
+}
@@ -0,0 +1,213 @@
+package layer
+
+import (
+	"fmt"
+	"gonn/internal/acti"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math/rand"
+)
+
+/*
+* Transformer Encoder Block
+*
+* Post-norm encoder layer over X[steps*dModel, N]:
+*
+*   H = Norm1(X + Attention(X))
+*   Y = Norm2(H + FFN(H)),    FFN = Linear(dModel, ffSize) -> ReLU -> Linear(ffSize, dModel)
+*
+* Inside the block every token is a column of [dModel, N*steps], so the
+* feed-forward network and both norms see each token as its own sample.
+* Masking is configured on Attention (Causal, Lengths).
+**/
+type TransformerEncoderBlock[T mat.Float] struct {
+	LayerIO[T]
+	Mode
+
+	Attention *MultiHeadAttention[T]
+	Norm1     *LayerNorm[T]
+	FFN       *Sequential[T]
+	Norm2     *LayerNorm[T]
+
+	dModel, ffSize uint64
+	seqLen         int
+}
+
+func NewTransformerEncoderBlock[T mat.Float](dModel, heads, ffSize uint64) (*TransformerEncoderBlock[T], error) {
+	attention, err := NewMultiHeadAttention[T](dModel, heads)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create TransformerEncoderBlock, reason { %s }", err)
+	}
+
+	relu, err := NewALByName[T](acti.NameReLU)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create TransformerEncoderBlock, reason { %s }", err)
+	}
+
+	return &TransformerEncoderBlock[T]{
+		Attention: attention,
+		Norm1:     NewLayerNorm[T](dModel),
+		FFN: NewSequential[T](
+			NewLL[T](dModel, ffSize),
+			relu,
+			NewLL[T](ffSize, dModel),
+		),
+		Norm2: NewLayerNorm[T](dModel),
+
+		dModel: dModel,
+		ffSize: ffSize,
+
+		LayerIO: LayerIO[T]{
+			I: nil,
+			O: nil,
+		},
+	}, nil
+}
+
+func (tb *TransformerEncoderBlock[T]) ISize() int64 {
+	return int64(tb.dModel)
+}
+
+func (tb *TransformerEncoderBlock[T]) OSize() int64 {
+	return int64(tb.dModel)
+}
+
+func (tb *TransformerEncoderBlock[T]) Forward(x *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if x == nil {
+		return nil, tb.wrapForwardErr(fmt.Errorf("nil input"))
+	}
+
+	xc, seqLen, err := tokensToCols(x, tb.dModel)
+	if err != nil {
+		return nil, tb.wrapForwardErr(err)
+	}
+
+	a, err := tb.Attention.forwardCols(xc, seqLen)
+	if err != nil {
+		return nil, tb.wrapForwardErr(err)
+	}
+
+	h, err := tb.Norm1.Forward(mat.MustAdd(xc, a))
+	if err != nil {
+		return nil, tb.wrapForwardErr(err)
+	}
+
+	f, err := tb.FFN.Forward(h)
+	if err != nil {
+		return nil, tb.wrapForwardErr(err)
+	}
+
+	y, err := tb.Norm2.Forward(mat.MustAdd(h, f))
+	if err != nil {
+		return nil, tb.wrapForwardErr(err)
+	}
+
+	tb.seqLen = seqLen
+	tb.I = x
+	tb.O = colsToTokens(y, seqLen)
+
+	return tb.O, nil
+}
+
+func (tb *TransformerEncoderBlock[T]) Backward(loss *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if loss == nil {
+		return nil, tb.wrapBackwardErr(fmt.Errorf("nil loss"))
+	}
+	if tb.I == nil {
+		return nil, tb.wrapBackwardErr(fmt.Errorf("Backward called before Forward"))
+	}
+	if !mat.DimsMatch(loss, tb.I) {
+		return nil, tb.wrapBackwardErr(fmt.Errorf(
+			"loss[%d, %d] does not match output[%d, %d]",
+			loss.Rows(), loss.Cols(), tb.I.Rows(), tb.I.Cols(),
+		))
+	}
+
+	dy, _, err := tokensToCols(loss, tb.dModel)
+	if err != nil {
+		return nil, tb.wrapBackwardErr(err)
+	}
+
+	// residuals pass the gradient around each sublayer unchanged
+	dSum2, err := tb.Norm2.Backward(dy)
+	if err != nil {
+		return nil, tb.wrapBackwardErr(err)
+	}
+
+	dh, err := tb.FFN.Backward(dSum2)
+	if err != nil {
+		return nil, tb.wrapBackwardErr(err)
+	}
+	dh.MustAdd(dSum2)
+
+	dSum1, err := tb.Norm1.Backward(dh)
+	if err != nil {
+		return nil, tb.wrapBackwardErr(err)
+	}
+
+	dx, err := tb.Attention.backwardCols(dSum1)
+	if err != nil {
+		return nil, tb.wrapBackwardErr(err)
+	}
+	dx.MustAdd(dSum1)
+
+	return colsToTokens(dx, tb.seqLen), nil
+}
+
+func (tb *TransformerEncoderBlock[T]) SetTraining(training bool) {
+	tb.Mode.SetTraining(training)
+	setTraining[T](training, tb.Attention, tb.Norm1, tb.FFN, tb.Norm2)
+}
+
+// initializes the attention and feed-forward weights, the norms keep gamma = 1 and beta = 0
+func (tb *TransformerEncoderBlock[T]) Init(weights, bias weightinit.Initializer[T], rng *rand.Rand) {
+	tb.Attention.Init(weights, bias, rng)
+	tb.FFN.Init(weights, bias, rng)
+}
+
+func (tb *TransformerEncoderBlock[T]) Params() []Learnable[T] {
+	params := []Learnable[T]{tb.Attention, tb.Norm1}
+	params = append(params, tb.FFN.Params()...)
+	return append(params, tb.Norm2)
+}
+
+// gradients live on the sublayers (see Params)
+func (tb *TransformerEncoderBlock[T]) IsLearnable() (learnable bool, gradient *mat.Mat2D[T]) {
+	return true, nil
+}
+
+func (tb *TransformerEncoderBlock[T]) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error)),
+) error {
+	for _, param := range tb.Params() {
+		if err := param.Learn(updateWeights); err != nil {
+			return fmt.Errorf("Failed to %s::Learn, reason: { %s }", tb.shapeRep(), err)
+		}
+	}
+	return nil
+}
+
+// ## private ##
+
+func (tb *TransformerEncoderBlock[T]) wrapForwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Forward, reason: { %s }",
+		tb.shapeRep(),
+		err,
+	)
+}
+
+func (tb *TransformerEncoderBlock[T]) wrapBackwardErr(err error) error {
+	return fmt.Errorf(
+		"Failed to %s::Backward, reason: { %s }",
+		tb.shapeRep(),
+		err,
+	)
+}
+
+func (tb *TransformerEncoderBlock[T]) shapeRep() string {
+	return fmt.Sprintf(
+		"<TransformerEncoderBlock([%d, N] x steps, %d heads, ff %d)>",
+		tb.dModel, tb.Attention.heads, tb.ffSize,
+	)
+}
@@ -42,7 +42,7 @@ func CrossEntropy[T mat.Float](y, y_ *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
 					= -y/y_ + (1-y)/(1-y_)
 	*/
 	CE := mat.New2D[T](uint64(y.Rows()), uint64(y.Cols()))
-	for j := range CE.Rows() {
+	for j := range CE.Cols() {
 		// jth training label
 
 		for i := range CE.Rows() {
@@ -76,7 +76,7 @@ func DCrossEntropy[T mat.Float](y, y_ *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
 
 	dce := mat.New2D[T](uint64(y.Rows()), uint64(y.Cols()))
 
-	for j := range dce.Rows() {
+	for j := range dce.Cols() {
 		// jth training label
 
 		for i := range dce.Rows() {
@@ -90,3 +90,28 @@ func DCrossEntropy[T mat.Float](y, y_ *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
 
 	return dce, nil
 }
+
+/*
+* Loss pairs a loss F with its derivative DF wrt the prediction y_, the way
+* train.Trainer consumes them. F may return per element or per sample losses,
+* a batch's loss is their sum divided by the batch size.
+**/
+type Loss[T mat.Float] struct {
+	Name string
+	F    func(y, y_ *mat.Mat2D[T]) (*mat.Mat2D[T], error)
+	DF   func(y, y_ *mat.Mat2D[T]) (*mat.Mat2D[T], error)
+}
+
+func SquaredErrorLoss[T mat.Float]() Loss[T] {
+	return Loss[T]{Name: "se", F: SquaredError[T], DF: DSquaredError[T]}
+}
+
+// y_ are probabilities, e.g. the output of a sigmoid layer
+func CrossEntropyLoss[T mat.Float]() Loss[T] {
+	return Loss[T]{Name: "ce", F: CrossEntropy[T], DF: DCrossEntropy[T]}
+}
+
+// y_ are logits, softmax is fused into the loss
+func SoftmaxCrossEntropyLoss[T mat.Float]() Loss[T] {
+	return Loss[T]{Name: "softmax_ce", F: SoftmaxCrossEntropy[T], DF: DSoftmaxCrossEntropy[T]}
+}
@@ -0,0 +1,149 @@
+package lossfuncs
+
+import (
+	"fmt"
+	"gonn/internal/acti"
+	"gonn/internal/mat"
+)
+
+/*
+* Softmax Cross Entropy (from logits)
+*
+* y[classes, N] target distributions (usually one-hot), logits[classes, N]
+*
+* ce[j]         = -sum_i y[i, j] * logsoftmax(logits)[i, j]
+* dce/dlogits   = softmax(logits) * sum_i y[i, j] - y
+*               = softmax(logits) - y                (one-hot / normalized y)
+*
+* Fusing softmax into the loss goes through logsumexp, so large logits
+* never overflow and log(0) is never taken. Returns the per sample loss [1, N].
+**/
+func SoftmaxCrossEntropy[T mat.Float](y, logits *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := matchLossDims("SoftmaxCrossEntropy", y, logits); err != nil {
+		return nil, err
+	}
+
+	logp := acti.LogSoftmax(logits)
+	ce := mat.New2D[T](1, uint64(y.Cols()))
+
+	for j := range y.Cols() {
+		// jth training label
+		var sum T = 0
+		for i := range y.Rows() {
+			if Y := y.MustGet(i, j); Y != 0 {
+				sum += Y * logp.MustGet(i, j)
+			}
+		}
+		ce.MustSet(0, j, -sum)
+	}
+
+	return ce, nil
+}
+
+func DSoftmaxCrossEntropy[T mat.Float](y, logits *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := matchLossDims("DSoftmaxCrossEntropy", y, logits); err != nil {
+		return nil, err
+	}
+
+	dce := acti.Softmax(logits)
+
+	for j := range y.Cols() {
+		// jth training label
+		var total T = 0
+		for i := range y.Rows() {
+			total += y.MustGet(i, j)
+		}
+		for i := range y.Rows() {
+			dce.MustSet(i, j, dce.MustGet(i, j)*total-y.MustGet(i, j))
+		}
+	}
+
+	return dce, nil
+}
+
+/*
+* Softmax Cross Entropy with integer targets
+*
+* labels[j] is the class index of sample j, equivalent to
+* SoftmaxCrossEntropy with y = OneHot(labels) but without building y.
+**/
+func SoftmaxCrossEntropyIdx[T mat.Float](labels []int, logits *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validateLabels("SoftmaxCrossEntropyIdx", labels, logits); err != nil {
+		return nil, err
+	}
+
+	lse := logits.MustLogSumExp(mat.AxisRows, true)
+	ce := mat.New2D[T](1, uint64(len(labels)))
+
+	for j, label := range labels {
+		ce.MustSet(0, int64(j), lse.MustGet(0, int64(j))-logits.MustGet(int64(label), int64(j)))
+	}
+
+	return ce, nil
+}
+
+func DSoftmaxCrossEntropyIdx[T mat.Float](labels []int, logits *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	if err := validateLabels("DSoftmaxCrossEntropyIdx", labels, logits); err != nil {
+		return nil, err
+	}
+
+	dce := acti.Softmax(logits)
+	for j, label := range labels {
+		i := int64(label)
+		dce.MustSet(i, int64(j), dce.MustGet(i, int64(j))-1)
+	}
+
+	return dce, nil
+}
+
+// one-hot matrix [classes, N] for class index labels
+func OneHot[T mat.Float](labels []int, classes uint64) (*mat.Mat2D[T], error) {
+	y := mat.New2D[T](classes, uint64(len(labels)))
+
+	for j, label := range labels {
+		if label < 0 || uint64(label) >= classes {
+			return nil, fmt.Errorf(
+				"Cannot one-hot label[%d] = %d, expected class in [0, %d)",
+				j, label, classes,
+			)
+		}
+		y.MustSet(int64(label), int64(j), 1)
+	}
+
+	return y, nil
+}
+
+// vvv PRIVATE vvv
+
+func matchLossDims[T mat.Float](name string, y, y_ *mat.Mat2D[T]) error {
+	if !mat.DimsMatch(y, y_) {
+		return fmt.Errorf(
+			"Cannot perform %s loss on matrices with mismatched dims: "+
+				"y[%d, %d]\ty_[%d, %d]",
+			name,
+			y.Rows(), y.Cols(),
+			y_.Rows(), y_.Cols(),
+		)
+	}
+	return nil
+}
+
+func validateLabels[T mat.Float](name string, labels []int, logits *mat.Mat2D[T]) error {
+	if int64(len(labels)) != logits.Cols() {
+		return fmt.Errorf(
+			"Cannot perform %s loss with %d labels for logits[%d, %d]",
+			name, len(labels), logits.Rows(), logits.Cols(),
+		)
+	}
+
+	for j, label := range labels {
+		if label < 0 || int64(label) >= logits.Rows() {
+			return fmt.Errorf(
+				"Cannot perform %s loss, label[%d] = %d out of range for %d classes",
+				name, j, label, logits.Rows(),
+			)
+		}
+	}
+
+	return nil
+}
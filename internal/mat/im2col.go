@@ -0,0 +1,202 @@
+package mat
+
+import (
+	"fmt"
+	"log"
+)
+
+/*
+* ConvGeom
+*
+* Geometry of a 2D sliding window (convolution or pooling) over images
+* stored one per column as [Channels * Height * Width, N], channel major:
+* pixel (c, h, w) of image n lives at row c*Height*Width + h*Width + w, col n.
+*
+* Zero values of Stride and Dilation mean 1.
+**/
+type ConvGeom struct {
+	Channels, Height, Width uint64
+
+	KernelH, KernelW     uint64
+	StrideH, StrideW     uint64
+	PadH, PadW           uint64
+	DilationH, DilationW uint64
+}
+
+func (g ConvGeom) Normalized() ConvGeom {
+	g.StrideH, g.StrideW = max(g.StrideH, 1), max(g.StrideW, 1)
+	g.DilationH, g.DilationW = max(g.DilationH, 1), max(g.DilationW, 1)
+	return g
+}
+
+func (g ConvGeom) Validate() error {
+	n := g.Normalized()
+
+	if n.Channels == 0 || n.Height == 0 || n.Width == 0 || n.KernelH == 0 || n.KernelW == 0 {
+		return fmt.Errorf("Invalid conv geometry %s, zero sized input or kernel", g)
+	}
+
+	spanH := n.DilationH*(n.KernelH-1) + 1
+	spanW := n.DilationW*(n.KernelW-1) + 1
+	if spanH > n.Height+2*n.PadH || spanW > n.Width+2*n.PadW {
+		return fmt.Errorf("Invalid conv geometry %s, kernel larger than padded input", g)
+	}
+
+	return nil
+}
+
+func (g ConvGeom) OutH() uint64 {
+	n := g.Normalized()
+	return (n.Height+2*n.PadH-n.DilationH*(n.KernelH-1)-1)/n.StrideH + 1
+}
+
+func (g ConvGeom) OutW() uint64 {
+	n := g.Normalized()
+	return (n.Width+2*n.PadW-n.DilationW*(n.KernelW-1)-1)/n.StrideW + 1
+}
+
+// rows of an input batch, Channels * Height * Width
+func (g ConvGeom) InSize() uint64 {
+	return g.Channels * g.Height * g.Width
+}
+
+// rows of an im2col patch matrix, Channels * KernelH * KernelW
+func (g ConvGeom) PatchSize() uint64 {
+	return g.Channels * g.KernelH * g.KernelW
+}
+
+/*
+* Input Row
+*
+* Row of the input pixel covered by kernel tap (kh, kw) of channel c
+* when the window is at output position (oh, ow), ok is false if
+* that tap falls on padding.
+**/
+func (g ConvGeom) InputRow(c, oh, ow, kh, kw uint64) (row uint64, ok bool) {
+	n := g.Normalized()
+
+	// unsigned arithmetic, taps left of / above the image wrap around to huge values
+	ih := oh*n.StrideH + kh*n.DilationH - n.PadH
+	iw := ow*n.StrideW + kw*n.DilationW - n.PadW
+	if ih >= n.Height || iw >= n.Width {
+		return 0, false
+	}
+
+	return c*n.Height*n.Width + ih*n.Width + iw, true
+}
+
+func (g ConvGeom) String() string {
+	n := g.Normalized()
+	return fmt.Sprintf(
+		"<[%d, %d, %d] kernel[%d, %d] stride[%d, %d] pad[%d, %d] dilation[%d, %d]>",
+		n.Channels, n.Height, n.Width,
+		n.KernelH, n.KernelW,
+		n.StrideH, n.StrideW,
+		n.PadH, n.PadW,
+		n.DilationH, n.DilationW,
+	)
+}
+
+/*
+* Im2Col
+*
+* Unrolls every kernel window of x[C*H*W, N] into a column, returning
+* cols[C*KH*KW, OH*OW*N] where
+*
+*	row = c*KH*KW + kh*KW + kw
+*	col = (oh*OW + ow)*N + n
+*
+* so a convolution is W[OC, C*KH*KW] * cols = out[OC, OH*OW*N], which is
+* already laid out as [OC*OH*OW, N]. Padding taps are 0.
+**/
+func Im2Col[T Float](x *Mat2D[T], g ConvGeom) (*Mat2D[T], error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	if x.rows != g.InSize() {
+		return nil, fmt.Errorf(
+			"Cannot Im2Col x%s for geometry %s, expected %d rows",
+			x.stringifyRowCol(), g, g.InSize(),
+		)
+	}
+
+	N := x.cols
+	P := g.OutH() * g.OutW()
+	xVals, xStride := x.rowMajor()
+
+	cols := New2D[T](g.PatchSize(), P*N)
+
+	g.forEachTap(func(row, p, inRow uint64) {
+		dst := cols.values[row*P*N+p*N : row*P*N+(p+1)*N]
+		copy(dst, xVals[inRow*uint64(xStride):inRow*uint64(xStride)+N])
+	})
+
+	return cols, nil
+}
+
+func MustIm2Col[T Float](x *Mat2D[T], g ConvGeom) *Mat2D[T] {
+	cols, err := Im2Col(x, g)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return cols
+}
+
+/*
+* Col2Im
+*
+* Adjoint of Im2Col, sums every entry of cols[C*KH*KW, OH*OW*N] back
+* onto the input pixel it was copied from, returning [C*H*W, N].
+**/
+func Col2Im[T Float](cols *Mat2D[T], g ConvGeom) (*Mat2D[T], error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	P := g.OutH() * g.OutW()
+	if cols.rows != g.PatchSize() || cols.cols%P != 0 {
+		return nil, fmt.Errorf(
+			"Cannot Col2Im cols%s for geometry %s, expected [%d, %d * N]",
+			cols.stringifyRowCol(), g, g.PatchSize(), P,
+		)
+	}
+
+	N := cols.cols / P
+	cVals, cStride := cols.rowMajor()
+
+	x := New2D[T](g.InSize(), N)
+
+	g.forEachTap(func(row, p, inRow uint64) {
+		src := cVals[row*uint64(cStride)+p*N : row*uint64(cStride)+(p+1)*N]
+		dst := x.values[inRow*N : (inRow+1)*N]
+		for n, v := range src {
+			dst[n] += v
+		}
+	})
+
+	return x, nil
+}
+
+// vvv PRIVATE vvv
+
+// calls f for every (patch row, output position) pair that is not padding
+func (g ConvGeom) forEachTap(f func(row, p, inRow uint64)) {
+	n := g.Normalized()
+	OH, OW := n.OutH(), n.OutW()
+
+	for c := range n.Channels {
+		for kh := range n.KernelH {
+			for kw := range n.KernelW {
+				row := c*n.KernelH*n.KernelW + kh*n.KernelW + kw
+
+				for oh := range OH {
+					for ow := range OW {
+						if inRow, ok := n.InputRow(c, oh, ow, kh, kw); ok {
+							f(row, oh*OW+ow, inRow)
+						}
+					}
+				}
+			}
+		}
+	}
+}
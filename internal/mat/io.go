@@ -0,0 +1,183 @@
+package mat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+/*
+* Binary Mat2D format (little endian)
+*
+*	magic    [4]byte  "GMAT"
+*	version  uint16
+*	dtype    uint8    (DTypeF32 | DTypeF64)
+*	rows     uint64
+*	cols     uint64
+*	values   [rows * cols]dtype, row major in logical (Get) order
+*	checksum uint32   CRC-32 (IEEE) of every preceding byte
+*
+* Transposed and sliced views are written by their logical values,
+* so reading always yields a fresh contiguous matrix.
+**/
+
+const (
+	MatFormatVersion uint16 = 1
+
+	DTypeF32 uint8 = 1
+	DTypeF64 uint8 = 2
+)
+
+var matMagic = [4]byte{'G', 'M', 'A', 'T'}
+
+// dtype tag of T
+func DType[T Float]() uint8 {
+	var zero T
+	if _, ok := any(zero).(float32); ok {
+		return DTypeF32
+	}
+	return DTypeF64
+}
+
+func (m *Mat2D[T]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(cw, crc))
+
+	header := struct {
+		Magic   [4]byte
+		Version uint16
+		DType   uint8
+		Rows    uint64
+		Cols    uint64
+	}{matMagic, MatFormatVersion, DType[T](), m.rows, m.cols}
+
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return cw.n, fmt.Errorf("Failed to write Mat2D header, reason { %s }", err)
+	}
+
+	buf := make([]byte, 8)
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			val := m.MustGet(i, j)
+
+			var b []byte
+			if DType[T]() == DTypeF32 {
+				b = binary.LittleEndian.AppendUint32(buf[:0], math.Float32bits(float32(val)))
+			} else {
+				b = binary.LittleEndian.AppendUint64(buf[:0], math.Float64bits(float64(val)))
+			}
+
+			if _, err := bw.Write(b); err != nil {
+				return cw.n, fmt.Errorf("Failed to write Mat2D values, reason { %s }", err)
+			}
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return cw.n, fmt.Errorf("Failed to write Mat2D values, reason { %s }", err)
+	}
+
+	if err := binary.Write(cw, binary.LittleEndian, crc.Sum32()); err != nil {
+		return cw.n, fmt.Errorf("Failed to write Mat2D checksum, reason { %s }", err)
+	}
+
+	return cw.n, nil
+}
+
+/*
+* Read Mat2D
+*
+* Reads a matrix written by WriteTo. Values stored with a different dtype
+* than T are converted.
+**/
+func ReadMat2D[T Float](r io.Reader) (*Mat2D[T], error) {
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+
+	var header struct {
+		Magic   [4]byte
+		Version uint16
+		DType   uint8
+		Rows    uint64
+		Cols    uint64
+	}
+	if err := binary.Read(tr, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("Failed to read Mat2D header, reason { %s }", err)
+	}
+
+	if header.Magic != matMagic {
+		return nil, fmt.Errorf("Failed to read Mat2D, reason { bad magic %q }", header.Magic[:])
+	}
+	if header.Version == 0 || header.Version > MatFormatVersion {
+		return nil, fmt.Errorf(
+			"Failed to read Mat2D, reason { unsupported version %d, latest is %d }",
+			header.Version, MatFormatVersion,
+		)
+	}
+
+	var width uint64
+	switch header.DType {
+	case DTypeF32:
+		width = 4
+	case DTypeF64:
+		width = 8
+	default:
+		return nil, fmt.Errorf("Failed to read Mat2D, reason { unknown dtype %d }", header.DType)
+	}
+
+	// guard against corrupt headers allocating absurd amounts of memory
+	const maxValues = 1 << 30
+	if header.Rows != 0 && header.Cols > maxValues/header.Rows {
+		return nil, fmt.Errorf(
+			"Failed to read Mat2D, reason { invalid shape [%d, %d] }",
+			header.Rows, header.Cols,
+		)
+	}
+
+	m := New2D[T](header.Rows, header.Cols)
+
+	raw := make([]byte, uint64(len(m.values))*width)
+	if _, err := io.ReadFull(tr, raw); err != nil {
+		return nil, fmt.Errorf("Failed to read Mat2D values, reason { %s }", err)
+	}
+
+	for i := range m.values {
+		b := raw[uint64(i)*width:]
+		if header.DType == DTypeF32 {
+			m.values[i] = T(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		} else {
+			m.values[i] = T(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		}
+	}
+	expected := crc.Sum32()
+
+	var checksum uint32
+	if err := binary.Read(r, binary.LittleEndian, &checksum); err != nil {
+		return nil, fmt.Errorf("Failed to read Mat2D checksum, reason { %s }", err)
+	}
+	if checksum != expected {
+		return nil, fmt.Errorf(
+			"Failed to read Mat2D, reason { checksum mismatch %08x != %08x }",
+			checksum, expected,
+		)
+	}
+
+	return m, nil
+}
+
+// vvv PRIVATE vvv
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
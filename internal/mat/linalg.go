@@ -0,0 +1,604 @@
+package mat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/*
+* Dense Linear Algebra
+*
+* Decompositions and solvers for small dense matrices. Every routine reads
+* its input through Get, so transposed and sliced views work, and computes
+* in float64 whatever T is, converting back only for the results.
+*
+* A matrix is treated as singular when a pivot is below
+*
+*	max(rows, cols) * eps(float64) * max|a[i, j]|
+*
+* Errors wrap ErrNotSquare, ErrSingular, ErrNotSymmetric or
+* ErrNotPositiveDefinite, so callers can tell them apart with errors.Is.
+**/
+var (
+	ErrNotSquare           = errors.New("matrix is not square")
+	ErrSingular            = errors.New("matrix is singular")
+	ErrNotSymmetric        = errors.New("matrix is not symmetric")
+	ErrNotPositiveDefinite = errors.New("matrix is not positive definite")
+)
+
+// Which half of a square matrix holds a triangular one
+type Triangle int
+
+const (
+	Lower Triangle = iota
+	Upper
+)
+
+/*
+* LU Decomposition
+*
+* P*A = L*U with partial (row) pivoting for a square A[n, n]: L is unit
+* lower triangular, U upper triangular and P the permutation moving row
+* Pivots()[i] of A to row i. A singular A still factors (Det is 0), only
+* solving with it fails.
+**/
+type LU[T Float] struct {
+	lu    *dense // L below the diagonal, U on and above
+	piv   []int
+	sign  float64 // of the permutation
+	small float64 // pivots at or below it are zero
+}
+
+func LUDecompose[T Float](a *Mat2D[T]) (*LU[T], error) {
+	if err := mustBeSquare("LU", a); err != nil {
+		return nil, err
+	}
+
+	lu := denseOf(a)
+	n := lu.rows
+	piv := make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+	sign := 1.0
+
+	for k := range n {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu.at(i, k)) > math.Abs(lu.at(p, k)) {
+				p = i
+			}
+		}
+		if p != k {
+			lu.swapRows(p, k)
+			piv[p], piv[k] = piv[k], piv[p]
+			sign = -sign
+		}
+
+		pivot := lu.at(k, k)
+		if pivot == 0 {
+			continue // the column is already eliminated
+		}
+		for i := k + 1; i < n; i++ {
+			l := lu.at(i, k) / pivot
+			lu.set(i, k, l)
+			for j := k + 1; j < n; j++ {
+				lu.set(i, j, lu.at(i, j)-l*lu.at(k, j))
+			}
+		}
+	}
+
+	return &LU[T]{lu: lu, piv: piv, sign: sign, small: smallPivot(a)}, nil
+}
+
+// unit lower triangular L[n, n]
+func (f *LU[T]) L() *Mat2D[T] {
+	n := f.lu.rows
+	L := newDense(n, n)
+	for i := range n {
+		L.set(i, i, 1)
+		for j := range i {
+			L.set(i, j, f.lu.at(i, j))
+		}
+	}
+	return matOf[T](L)
+}
+
+// upper triangular U[n, n]
+func (f *LU[T]) U() *Mat2D[T] {
+	n := f.lu.rows
+	U := newDense(n, n)
+	for i := range n {
+		for j := i; j < n; j++ {
+			U.set(i, j, f.lu.at(i, j))
+		}
+	}
+	return matOf[T](U)
+}
+
+// the permutation P[n, n] with P*A = L*U
+func (f *LU[T]) P() *Mat2D[T] {
+	n := f.lu.rows
+	P := newDense(n, n)
+	for i, p := range f.piv {
+		P.set(i, p, 1)
+	}
+	return matOf[T](P)
+}
+
+// row i of L*U is row Pivots()[i] of A
+func (f *LU[T]) Pivots() []int {
+	return append([]int{}, f.piv...)
+}
+
+func (f *LU[T]) Det() T {
+	det := f.sign
+	for i := range f.lu.rows {
+		det *= f.lu.at(i, i)
+	}
+	return T(det)
+}
+
+func (f *LU[T]) IsSingular() bool {
+	for i := range f.lu.rows {
+		if math.Abs(f.lu.at(i, i)) <= f.small {
+			return true
+		}
+	}
+	return false
+}
+
+// X[n, k] with A*X = B[n, k]
+func (f *LU[T]) Solve(b *Mat2D[T]) (*Mat2D[T], error) {
+	n := f.lu.rows
+	if b == nil || int(b.Rows()) != n {
+		return nil, fmt.Errorf("Failed to LU::Solve, reason { B must have %d rows, found %s }", n, dimsOf(b))
+	}
+	if f.IsSingular() {
+		return nil, fmt.Errorf("Failed to LU::Solve, reason { %w }", ErrSingular)
+	}
+
+	// P*A*X = L*U*X = P*B
+	B := denseOf(b)
+	X := newDense(n, B.cols)
+	for i, p := range f.piv {
+		for j := range B.cols {
+			X.set(i, j, B.at(p, j))
+		}
+	}
+	f.lu.solveTriangular(X, Lower, true)
+	f.lu.solveTriangular(X, Upper, false)
+
+	return matOf[T](X), nil
+}
+
+func (f *LU[T]) Inverse() (*Mat2D[T], error) {
+	return f.Solve(Id[T](uint64(f.lu.rows)))
+}
+
+/*
+* QR Decomposition
+*
+* A[m, n] = Q*R for m >= n by Householder reflections, Q[m, n] has
+* orthonormal columns and R[n, n] is upper triangular (the thin
+* factorization, FullQ gives the square Q[m, m]).
+**/
+type QR[T Float] struct {
+	qr    *dense      // R on and above the diagonal
+	vs    [][]float64 // unit Householder vectors, vs[k] acting on rows k..m-1
+	small float64
+}
+
+func QRDecompose[T Float](a *Mat2D[T]) (*QR[T], error) {
+	if a == nil || a.Rows() < a.Cols() {
+		return nil, fmt.Errorf("Failed to QR, reason { expected A[m, n] with m >= n, found %s }", dimsOf(a))
+	}
+
+	qr := denseOf(a)
+	m, n := qr.rows, qr.cols
+	vs := make([][]float64, n)
+
+	for k := range n {
+		// v = x - alpha * e1 with alpha = -sign(x0) * |x| to avoid cancellation
+		v := make([]float64, m-k)
+		var norm float64
+		for i := k; i < m; i++ {
+			v[i-k] = qr.at(i, k)
+			norm = math.Hypot(norm, v[i-k])
+		}
+		vs[k] = v
+		if norm == 0 {
+			clear(v) // nothing to eliminate, a zero vector reflects as the identity
+			continue
+		}
+
+		alpha := -math.Copysign(norm, v[0])
+		v[0] -= alpha
+		vNorm := 0.0
+		for _, x := range v {
+			vNorm = math.Hypot(vNorm, x)
+		}
+		for i := range v {
+			v[i] /= vNorm
+		}
+
+		qr.reflect(v, k, k)
+	}
+
+	return &QR[T]{qr: qr, vs: vs, small: smallPivot(a)}, nil
+}
+
+// upper triangular R[n, n]
+func (f *QR[T]) R() *Mat2D[T] {
+	n := f.qr.cols
+	R := newDense(n, n)
+	for i := range n {
+		for j := i; j < n; j++ {
+			R.set(i, j, f.qr.at(i, j))
+		}
+	}
+	return matOf[T](R)
+}
+
+// Q[m, n] with orthonormal columns
+func (f *QR[T]) Q() *Mat2D[T] {
+	return matOf[T](f.q(f.qr.cols))
+}
+
+// the orthogonal Q[m, m], its first n columns are Q()
+func (f *QR[T]) FullQ() *Mat2D[T] {
+	return matOf[T](f.q(f.qr.rows))
+}
+
+// false when A has linearly dependent columns
+func (f *QR[T]) IsFullRank() bool {
+	for i := range f.qr.cols {
+		if math.Abs(f.qr.at(i, i)) <= f.small {
+			return false
+		}
+	}
+	return true
+}
+
+// the least squares X[n, k] minimizing |A*X - B|, B[m, k]
+func (f *QR[T]) Solve(b *Mat2D[T]) (*Mat2D[T], error) {
+	m, n := f.qr.rows, f.qr.cols
+	if b == nil || int(b.Rows()) != m {
+		return nil, fmt.Errorf("Failed to QR::Solve, reason { B must have %d rows, found %s }", m, dimsOf(b))
+	}
+	if !f.IsFullRank() {
+		return nil, fmt.Errorf("Failed to QR::Solve, reason { rank deficient: %w }", ErrSingular)
+	}
+
+	// R*X = (Q^T*B)[:n]
+	QtB := denseOf(b)
+	for k, v := range f.vs {
+		QtB.reflect(v, k, 0)
+	}
+	X := newDense(n, QtB.cols)
+	for i := range n {
+		for j := range QtB.cols {
+			X.set(i, j, QtB.at(i, j))
+		}
+	}
+	f.qr.solveTriangular(X, Upper, false)
+
+	return matOf[T](X), nil
+}
+
+/*
+* Cholesky Decomposition
+*
+* A = L*L^T for a symmetric positive definite A[n, n], L lower triangular
+* with a positive diagonal.
+**/
+type Cholesky[T Float] struct {
+	l *dense
+}
+
+func CholeskyDecompose[T Float](a *Mat2D[T]) (*Cholesky[T], error) {
+	if err := mustBeSquare("Cholesky", a); err != nil {
+		return nil, err
+	}
+
+	A := denseOf(a)
+	n := A.rows
+	symTol := 8 * smallPivot(a)
+	for i := range n {
+		for j := range i {
+			if math.Abs(A.at(i, j)-A.at(j, i)) > symTol {
+				return nil, fmt.Errorf(
+					"Failed to Cholesky, reason { %w: a[%d, %d] = %g, a[%d, %d] = %g }",
+					ErrNotSymmetric, i, j, A.at(i, j), j, i, A.at(j, i),
+				)
+			}
+		}
+	}
+
+	L := newDense(n, n)
+	for j := range n {
+		d := A.at(j, j)
+		for k := range j {
+			d -= L.at(j, k) * L.at(j, k)
+		}
+		if !(d > 0) {
+			return nil, fmt.Errorf("Failed to Cholesky, reason { %w: pivot %d is %g }", ErrNotPositiveDefinite, j, d)
+		}
+		L.set(j, j, math.Sqrt(d))
+
+		for i := j + 1; i < n; i++ {
+			s := A.at(i, j)
+			for k := range j {
+				s -= L.at(i, k) * L.at(j, k)
+			}
+			L.set(i, j, s/L.at(j, j))
+		}
+	}
+
+	return &Cholesky[T]{l: L}, nil
+}
+
+// lower triangular L[n, n]
+func (f *Cholesky[T]) L() *Mat2D[T] {
+	return matOf[T](f.l)
+}
+
+func (f *Cholesky[T]) Det() T {
+	det := 1.0
+	for i := range f.l.rows {
+		det *= f.l.at(i, i) * f.l.at(i, i)
+	}
+	return T(det)
+}
+
+// X[n, k] with A*X = B[n, k]
+func (f *Cholesky[T]) Solve(b *Mat2D[T]) (*Mat2D[T], error) {
+	n := f.l.rows
+	if b == nil || int(b.Rows()) != n {
+		return nil, fmt.Errorf("Failed to Cholesky::Solve, reason { B must have %d rows, found %s }", n, dimsOf(b))
+	}
+
+	// L*Y = B, L^T*X = Y
+	X := denseOf(b)
+	f.l.solveTriangular(X, Lower, false)
+	f.l.transposed().solveTriangular(X, Upper, false)
+
+	return matOf[T](X), nil
+}
+
+/*
+* Solves t*X = B for X, t[n, n] triangular, only the given half of t is read
+* (so an LU can be passed whole). Fails with ErrSingular on a zero diagonal.
+**/
+func SolveTriangular[T Float](t *Mat2D[T], tri Triangle, b *Mat2D[T]) (*Mat2D[T], error) {
+	if err := mustBeSquare("SolveTriangular", t); err != nil {
+		return nil, err
+	}
+	if b == nil || b.Rows() != t.Rows() {
+		return nil, fmt.Errorf(
+			"Failed to SolveTriangular, reason { B must have %d rows, found %s }", t.Rows(), dimsOf(b),
+		)
+	}
+
+	tri_ := denseOf(t)
+	for i := range tri_.rows {
+		if tri_.at(i, i) == 0 {
+			return nil, fmt.Errorf("Failed to SolveTriangular, reason { zero diagonal at %d: %w }", i, ErrSingular)
+		}
+	}
+
+	X := denseOf(b)
+	tri_.solveTriangular(X, tri, false)
+	return matOf[T](X), nil
+}
+
+// X with A*X = B for a square A, through LU
+func Solve[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
+	lu, err := LUDecompose(a)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Solve, reason { %w }", err)
+	}
+	x, err := lu.Solve(b)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Solve, reason { %w }", err)
+	}
+	return x, nil
+}
+
+func Inverse[T Float](a *Mat2D[T]) (*Mat2D[T], error) {
+	lu, err := LUDecompose(a)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Inverse, reason { %w }", err)
+	}
+	inv, err := lu.Inverse()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Inverse, reason { %w }", err)
+	}
+	return inv, nil
+}
+
+func Det[T Float](a *Mat2D[T]) (T, error) {
+	lu, err := LUDecompose(a)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to Det, reason { %w }", err)
+	}
+	return lu.Det(), nil
+}
+
+/*
+* Condition number in the 1-norm (max absolute column sum),
+*
+*	cond(A) = |A|_1 * |A^-1|_1
+*
+* +Inf for a singular A. log10(cond) estimates the digits lost solving with A.
+**/
+func Cond[T Float](a *Mat2D[T]) (float64, error) {
+	lu, err := LUDecompose(a)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to Cond, reason { %w }", err)
+	}
+	if lu.IsSingular() {
+		return math.Inf(1), nil
+	}
+
+	inv, err := lu.Inverse()
+	if err != nil {
+		return 0, fmt.Errorf("Failed to Cond, reason { %w }", err)
+	}
+	return denseOf(a).norm1() * denseOf(inv).norm1(), nil
+}
+
+// vvv PRIVATE vvv
+
+// row major float64 working copy of a matrix
+type dense struct {
+	rows, cols int
+	v          []float64
+}
+
+func newDense(rows, cols int) *dense {
+	return &dense{rows: rows, cols: cols, v: make([]float64, rows*cols)}
+}
+
+func denseOf[T Float](m *Mat2D[T]) *dense {
+	d := newDense(int(m.Rows()), int(m.Cols()))
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			d.set(int(i), int(j), float64(m.MustGet(i, j)))
+		}
+	}
+	return d
+}
+
+func matOf[T Float](d *dense) *Mat2D[T] {
+	m := New2D[T](uint64(d.rows), uint64(d.cols))
+	for i := range d.rows {
+		for j := range d.cols {
+			m.MustSet(int64(i), int64(j), T(d.at(i, j)))
+		}
+	}
+	return m
+}
+
+func (d *dense) at(i, j int) float64 {
+	return d.v[i*d.cols+j]
+}
+
+func (d *dense) set(i, j int, val float64) {
+	d.v[i*d.cols+j] = val
+}
+
+func (d *dense) swapRows(a, b int) {
+	for j := range d.cols {
+		d.v[a*d.cols+j], d.v[b*d.cols+j] = d.v[b*d.cols+j], d.v[a*d.cols+j]
+	}
+}
+
+func (d *dense) transposed() *dense {
+	t := newDense(d.cols, d.rows)
+	for i := range d.rows {
+		for j := range d.cols {
+			t.set(j, i, d.at(i, j))
+		}
+	}
+	return t
+}
+
+// max absolute column sum
+func (d *dense) norm1() float64 {
+	norm := 0.0
+	for j := range d.cols {
+		sum := 0.0
+		for i := range d.rows {
+			sum += math.Abs(d.at(i, j))
+		}
+		norm = max(norm, sum)
+	}
+	return norm
+}
+
+/*
+* overwrites X[n, k] with the solution of t*X = X, t the tri half of the
+* leading [n, n] block of d, unitDiag treats the diagonal as ones (the L of an LU)
+**/
+func (d *dense) solveTriangular(X *dense, tri Triangle, unitDiag bool) {
+	n := X.rows
+	for c := range X.cols {
+		for step := range n {
+			i := step
+			if tri == Upper {
+				i = n - 1 - step
+			}
+
+			s := X.at(i, c)
+			if tri == Lower {
+				for k := range i {
+					s -= d.at(i, k) * X.at(k, c)
+				}
+			} else {
+				for k := i + 1; k < n; k++ {
+					s -= d.at(i, k) * X.at(k, c)
+				}
+			}
+			if !unitDiag {
+				s /= d.at(i, i)
+			}
+			X.set(i, c, s)
+		}
+	}
+}
+
+// applies H = I - 2*v*v^T to rows k.. of columns from.. in place
+func (d *dense) reflect(v []float64, k, from int) {
+	for j := from; j < d.cols; j++ {
+		dot := 0.0
+		for i, vi := range v {
+			dot += vi * d.at(k+i, j)
+		}
+		if dot == 0 {
+			continue
+		}
+		for i, vi := range v {
+			d.set(k+i, j, d.at(k+i, j)-2*vi*dot)
+		}
+	}
+}
+
+// the first cols columns of Q = H_0 * H_1 * ... * H_(n-1)
+func (f *QR[T]) q(cols int) *dense {
+	Q := newDense(f.qr.rows, cols)
+	for i := range min(f.qr.rows, cols) {
+		Q.set(i, i, 1)
+	}
+	for k := len(f.vs) - 1; k >= 0; k-- {
+		Q.reflect(f.vs[k], k, 0)
+	}
+	return Q
+}
+
+// pivots at or below this count as zero, see the package doc above
+func smallPivot[T Float](a *Mat2D[T]) float64 {
+	maxAbs := 0.0
+	for i := range a.Rows() {
+		for j := range a.Cols() {
+			maxAbs = max(maxAbs, math.Abs(float64(a.MustGet(i, j))))
+		}
+	}
+	const eps = 0x1p-52
+	return float64(max(a.Rows(), a.Cols())) * eps * maxAbs
+}
+
+func mustBeSquare[T Float](name string, a *Mat2D[T]) error {
+	if a == nil || a.Rows() != a.Cols() || a.Rows() == 0 {
+		return fmt.Errorf("Failed to %s, reason { %w, found %s }", name, ErrNotSquare, dimsOf(a))
+	}
+	return nil
+}
+
+func dimsOf[T Float](m *Mat2D[T]) string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("[%d, %d]", m.Rows(), m.Cols())
+}
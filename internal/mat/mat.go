@@ -3,7 +3,6 @@ package mat
 import (
 	"fmt"
 	"log"
-	"math/rand"
 	"strings"
 )
 
@@ -197,28 +196,17 @@ func Id[T Float](length uint64) *Mat2D[T] { // identity matrix
 	return mat
 }
 
+// U[0, 1) from the default source (see Seed)
 func Rand[T Float](rows, cols uint64) *Mat2D[T] {
-	mat := New2D[T](rows, cols)
-	mat.Apply(func(x T) T {
-		return T(rand.Float64())
-	})
-	return mat
+	return RandWith[T](nil, rows, cols)
 }
 
 func RandF32(rows, cols uint64) *Mat2DF32 {
-	mat := New2DF32(rows, cols)
-	for i := range len(mat.values) {
-		mat.values[i] = rand.Float32()
-	}
-	return mat
+	return Rand[float32](rows, cols)
 }
 
 func RandF64(rows, cols uint64) *Mat2DF64 {
-	mat := New2DF64(rows, cols)
-	for i := range len(mat.values) {
-		mat.values[i] = rand.Float64()
-	}
-	return mat
+	return Rand[float64](rows, cols)
 }
 
 func ARange[T Float](upto uint64) *Mat2D[T] {
@@ -304,6 +292,8 @@ func (m *Mat2D[T]) Scale(sc T) *Mat2D[T] {
 	return m
 }
 
+// In place element-wise ops, b is broadcast to the shape of a (see BroadcastDims)
+
 func (a *Mat2D[T]) MustAdd(b *Mat2D[T]) *Mat2D[T] {
 	if err := add(a, a, b); err != nil {
 		log.Fatal(err)
@@ -333,7 +323,7 @@ func (a *Mat2D[T]) Subtract(b *Mat2D[T]) error {
 }
 
 func (a *Mat2D[T]) MustMul(b *Mat2D[T]) *Mat2D[T] {
-	if err := subtract(a, a, b); err != nil {
+	if err := mul(a, a, b); err != nil {
 		log.Fatal(err)
 	}
 	return a
@@ -346,6 +336,21 @@ func (a *Mat2D[T]) Mul(b *Mat2D[T]) error {
 	return nil
 }
 
+// a = b, e.g. to fill a slice view of a larger matrix
+func (a *Mat2D[T]) MustCopy(b *Mat2D[T]) *Mat2D[T] {
+	if err := copyFrom(a, b); err != nil {
+		log.Fatal(err)
+	}
+	return a
+}
+
+func (a *Mat2D[T]) Copy(b *Mat2D[T]) error {
+	if err := copyFrom(a, b); err != nil {
+		return err
+	}
+	return nil
+}
+
 func Equals[T Float](a, b *Mat2D[T]) bool {
 	if !DimsMatch(a, b) {
 		return false
@@ -362,8 +367,15 @@ func Equals[T Float](a, b *Mat2D[T]) bool {
 	return true
 }
 
+// Element-wise ops, a and b are broadcast against each other (see BroadcastDims)
+
 func Add[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
-	dst := New2D[T](a.rows, a.cols)
+	rows, cols, err := BroadcastDims(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := New2D[T](rows, cols)
 	if err := add(dst, a, b); err != nil {
 		return nil, err
 	}
@@ -371,7 +383,12 @@ func Add[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
 }
 
 func Subtract[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
-	dst := New2D[T](a.rows, a.cols)
+	rows, cols, err := BroadcastDims(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := New2D[T](rows, cols)
 	if err := subtract(dst, a, b); err != nil {
 		return nil, err
 	}
@@ -379,7 +396,12 @@ func Subtract[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
 }
 
 func Mul[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
-	dst := New2D[T](a.rows, a.cols)
+	rows, cols, err := BroadcastDims(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := New2D[T](rows, cols)
 	if err := mul(dst, a, b); err != nil {
 		return nil, err
 	}
@@ -387,11 +409,11 @@ func Mul[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
 }
 
 func MustAdd[T Float](a, b *Mat2D[T]) *Mat2D[T] {
-	a, err := Subtract(a, b)
+	sum, err := Add(a, b)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return a
+	return sum
 }
 
 func MustSubtract[T Float](a, b *Mat2D[T]) *Mat2D[T] {
@@ -410,28 +432,34 @@ func MustMul[T Float](a, b *Mat2D[T]) *Mat2D[T] {
 	return dot
 }
 
-func MatMul[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
-	if err := dimsCanMul(a, b); err != nil {
-		return nil, err
-	}
+func DimsMatch[T Float](a, b *Mat2D[T]) bool {
+	return a.rows == b.rows && a.cols == b.cols
+}
 
-	// a.cols == b.rows
-	res := New2D[T](a.rows, b.cols)
-	for i := range res.Rows() {
-		for j := range res.Cols() {
-			var sum T = 0
-			for k := range b.Rows() {
-				sum += a.MustGet(i, k) * b.MustGet(k, j)
-			}
-			res.Set(i, j, sum)
-		}
+/*
+* Broadcast Dims
+*
+* Returns the shape a and b broadcast to, following NumPy rules:
+* along each axis the sizes must match or one of them must be 1.
+**/
+func BroadcastDims[T Float](a, b *Mat2D[T]) (rows, cols uint64, err error) {
+	rows, rowsOk := broadcastAxis(a.rows, b.rows)
+	cols, colsOk := broadcastAxis(a.cols, b.cols)
+
+	if !rowsOk || !colsOk {
+		return 0, 0, fmt.Errorf(
+			"Cannot broadcast a%s with b%s",
+			a.stringifyRowCol(),
+			b.stringifyRowCol(),
+		)
 	}
 
-	return res, nil
+	return rows, cols, nil
 }
 
-func DimsMatch[T Float](a, b *Mat2D[T]) bool {
-	return a.rows == b.rows && a.cols == b.cols
+func CanBroadcast[T Float](a, b *Mat2D[T]) bool {
+	_, _, err := BroadcastDims(a, b)
+	return err == nil
 }
 
 func VCat[T Float](matrices ...(*Mat2D[T])) (*Mat2D[T], error) {
@@ -462,6 +490,14 @@ func VCat[T Float](matrices ...(*Mat2D[T])) (*Mat2D[T], error) {
 	return res, nil
 }
 
+func MustVCat[T Float](matrices ...(*Mat2D[T])) *Mat2D[T] {
+	res, err := VCat(matrices...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return res
+}
+
 func HCat[T Float](matrices ...(*Mat2D[T])) (*Mat2D[T], error) {
 	rows, cols, err := dimsCanHCat(matrices...)
 	if err != nil {
@@ -695,6 +731,18 @@ func dimsCanMul[T Float](a, b *Mat2D[T]) error {
 	return nil
 }
 
+func broadcastAxis(a, b uint64) (uint64, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == 1:
+		return b, true
+	case b == 1:
+		return a, true
+	}
+	return 0, false
+}
+
 func (m *Mat2D[T]) reshapable(rows, cols uint64) bool {
 	return m.rows*m.cols == rows*cols
 }
@@ -711,63 +759,53 @@ func (m *Mat2D[T]) mustBeReshapeable(rows, cols uint64) error {
 }
 
 func subtract[T Float](dst, a, b *Mat2D[T]) error {
-	if err := validateDimsMatch(dst, a, b); err != nil {
-		return err
-	}
-
-	rows, cols := a.Rows(), a.Cols()
-
-	for i := range rows {
-		for j := range cols {
-			dst.Set(i, j, (a.MustGet(i, j) - b.MustGet(i, j)))
-		}
-	}
-
-	return nil
+	return broadcastOp(dst, a, b, "-", func(x, y T) T { return x - y })
 }
 
 func add[T Float](dst, a, b *Mat2D[T]) error {
-	if err := validateDimsMatch(dst, a, b); err != nil {
-		return err
-	}
-
-	rows, cols := a.Rows(), a.Cols()
-
-	for i := range rows {
-		for j := range cols {
-			dst.Set(i, j, (a.MustGet(i, j) + b.MustGet(i, j)))
-		}
-	}
-
-	return nil
+	return broadcastOp(dst, a, b, "+", func(x, y T) T { return x + y })
 }
 
 func mul[T Float](dst, a, b *Mat2D[T]) error {
-	if err := validateDimsMatch(dst, a, b); err != nil {
-		return err
-	}
+	return broadcastOp(dst, a, b, "*", func(x, y T) T { return x * y })
+}
 
-	rows, cols := a.Rows(), a.Cols()
+func copyFrom[T Float](dst, src *Mat2D[T]) error {
+	return broadcastOp(dst, dst, src, "=", func(_, y T) T { return y })
+}
 
-	for i := range rows {
-		for j := range cols {
-			dst.Set(i, j, (a.MustGet(i, j) * b.MustGet(i, j)))
-		}
+/*
+* dst = op(a, b), element-wise with a and b broadcast to the shape of dst.
+* An axis of size 1 is repeated along that axis, so row vectors [1, N],
+* column vectors [M, 1] and scalars [1, 1] all combine with an [M, N] matrix.
+**/
+func broadcastOp[T Float](dst, a, b *Mat2D[T], symbol string, op func(x, y T) T) error {
+	rows, cols, err := BroadcastDims(a, b)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func validateDimsMatch[T Float](dst, a, b *Mat2D[T]) error {
-	if !(DimsMatch(a, b) && DimsMatch(dst, a)) {
+	if dst.rows != rows || dst.cols != cols {
 		return fmt.Errorf(
-			"Mismatched dims dst%s = a%s - b%s",
+			"Mismatched dims dst%s = a%s %s b%s, broadcast result is [%d, %d]",
 			dst.stringifyRowCol(),
 			a.stringifyRowCol(),
+			symbol,
 			b.stringifyRowCol(),
+			rows, cols,
 		)
 	}
 
+	for i := range int64(rows) {
+		ai, bi := min(i, a.Rows()-1), min(i, b.Rows()-1)
+
+		for j := range int64(cols) {
+			aj, bj := min(j, a.Cols()-1), min(j, b.Cols()-1)
+
+			dst.MustSet(i, j, op(a.MustGet(ai, aj), b.MustGet(bi, bj)))
+		}
+	}
+
 	return nil
 }
 
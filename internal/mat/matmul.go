@@ -0,0 +1,176 @@
+package mat
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	matMulTile = 64 // rows / cols / depth handled per cache block
+
+	// products with fewer multiply-adds than this are not worth a goroutine
+	matMulParallelThreshold = 64 * 64 * 64
+)
+
+var matMulWorkers atomic.Int64
+
+func init() {
+	matMulWorkers.Store(int64(runtime.GOMAXPROCS(0)))
+}
+
+// Sets the size of the goroutine pool used by MatMul, values below 1 mean 1
+func SetMatMulWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	matMulWorkers.Store(int64(n))
+}
+
+func MatMulWorkers() int {
+	return int(matMulWorkers.Load())
+}
+
+func MatMul[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
+	if err := dimsCanMul(a, b); err != nil {
+		return nil, err
+	}
+
+	res := New2D[T](a.rows, b.cols)
+	matMulBlocked(res, a, b, MatMulWorkers())
+
+	return res, nil
+}
+
+func MustMatMul[T Float](a, b *Mat2D[T]) *Mat2D[T] {
+	res, err := MatMul(a, b)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return res
+}
+
+/*
+* Naive MatMul
+*
+* Reference triple loop through MustGet, kept for testing and benchmarking
+* the blocked implementation against.
+**/
+func NaiveMatMul[T Float](a, b *Mat2D[T]) (*Mat2D[T], error) {
+	if err := dimsCanMul(a, b); err != nil {
+		return nil, err
+	}
+
+	// a.cols == b.rows
+	res := New2D[T](a.rows, b.cols)
+	for i := range res.Rows() {
+		for j := range res.Cols() {
+			var sum T = 0
+			for k := range b.Rows() {
+				sum += a.MustGet(i, k) * b.MustGet(k, j)
+			}
+			res.Set(i, j, sum)
+		}
+	}
+
+	return res, nil
+}
+
+// vvv PRIVATE vvv
+
+/*
+* dst[M, N] += a[M, K] * b[K, N]
+*
+* dst must be a freshly allocated (contiguous, untransposed) matrix.
+* Operands are first brought into row major order so every inner loop
+* walks contiguous memory, then the product is computed in
+* matMulTile sized blocks, with row blocks split across workers.
+**/
+func matMulBlocked[T Float](dst, a, b *Mat2D[T], workers int) {
+	M, K, N := int(a.rows), int(a.cols), int(b.cols)
+	if M == 0 || K == 0 || N == 0 {
+		return
+	}
+
+	aVals, aStride := a.rowMajor()
+	bVals, bStride := b.rowMajor()
+	cVals := dst.values
+
+	rowBlocks := (M + matMulTile - 1) / matMulTile
+
+	if M*K*N < matMulParallelThreshold {
+		workers = 1
+	}
+	workers = min(workers, rowBlocks)
+
+	computeRows := func(i0, i1 int) {
+		for k0 := 0; k0 < K; k0 += matMulTile {
+			k1 := min(k0+matMulTile, K)
+
+			for j0 := 0; j0 < N; j0 += matMulTile {
+				j1 := min(j0+matMulTile, N)
+
+				for i := i0; i < i1; i++ {
+					aRow := aVals[i*aStride+k0 : i*aStride+k1]
+					cRow := cVals[i*N+j0 : i*N+j1]
+
+					// no skipping aik == 0, 0 * NaN and 0 * Inf must still reach c as NaN
+					for kk, aik := range aRow {
+						k := k0 + kk
+						bRow := bVals[k*bStride+j0 : k*bStride+j1]
+						for j, bkj := range bRow {
+							cRow[j] += aik * bkj
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if workers <= 1 {
+		computeRows(0, M)
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				block := int(next.Add(1)) - 1
+				if block >= rowBlocks {
+					return
+				}
+				i0 := block * matMulTile
+				computeRows(i0, min(i0+matMulTile, M))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// returns the values of m laid out row major along with the row stride,
+// a copy is only made when m is a transposed view
+func (m *Mat2D[T]) rowMajor() ([]T, int) {
+	if !m.transposed {
+		return m.values, int(m.stride)
+	}
+
+	rows, cols := int(m.rows), int(m.cols)
+	packed := make([]T, rows*cols)
+
+	// element (i, j) of a transposed view lives at values[j*stride + i]
+	for j := range cols {
+		src := m.values[j*int(m.stride) : j*int(m.stride)+rows]
+		for i, val := range src {
+			packed[i*cols+j] = val
+		}
+	}
+
+	return packed, cols
+}
@@ -0,0 +1,131 @@
+package mat
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+* Random numbers
+*
+* Every random constructor in gonn takes its numbers from a *rand.Rand, a nil
+* one stands for the package default. The default is randomly seeded,
+* Seed makes it (and so whole training runs) reproducible:
+*
+*	mat.Seed(42)
+*	W := mat.Rand[float32](3, 4)             // default source
+*	V := mat.Normal[float32](rng, 3, 4, 0, 1) // injected source
+**/
+
+var defaultRNG atomic.Pointer[rand.Rand]
+
+func init() {
+	Seed(rand.Int63())
+}
+
+// reseeds the default source, safe for concurrent use
+func Seed(seed int64) {
+	defaultRNG.Store(rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)}))
+}
+
+// replaces the default source, rng must be safe for concurrent use if shared across goroutines
+func SetRNG(rng *rand.Rand) {
+	defaultRNG.Store(rng)
+}
+
+func DefaultRNG() *rand.Rand {
+	return defaultRNG.Load()
+}
+
+// U[0, 1)
+func RandWith[T Float](rng *rand.Rand, rows, cols uint64) *Mat2D[T] {
+	return Uniform[T](rng, rows, cols, 0, 1)
+}
+
+// U[lo, hi)
+func Uniform[T Float](rng *rand.Rand, rows, cols uint64, lo, hi T) *Mat2D[T] {
+	rng = rngOr(rng)
+	mat := New2D[T](rows, cols)
+	for i := range mat.values {
+		v := lo + (hi-lo)*T(rng.Float64())
+		for v >= hi && hi > lo { // float32 rounding can reach hi
+			v = lo + (hi-lo)*T(rng.Float64())
+		}
+		mat.values[i] = v
+	}
+	return mat
+}
+
+// N(mean, std^2)
+func Normal[T Float](rng *rand.Rand, rows, cols uint64, mean, std T) *Mat2D[T] {
+	rng = rngOr(rng)
+	mat := New2D[T](rows, cols)
+	for i := range mat.values {
+		mat.values[i] = mean + std*T(rng.NormFloat64())
+	}
+	return mat
+}
+
+// N(mean, std^2) redrawn until within 2 std of the mean
+func TruncatedNormal[T Float](rng *rand.Rand, rows, cols uint64, mean, std T) *Mat2D[T] {
+	rng = rngOr(rng)
+	mat := New2D[T](rows, cols)
+	for i := range mat.values {
+		z := rng.NormFloat64()
+		for z < -2 || z > 2 {
+			z = rng.NormFloat64()
+		}
+		mat.values[i] = mean + std*T(z)
+	}
+	return mat
+}
+
+// 1 with probability p, else 0
+func Bernoulli[T Float](rng *rand.Rand, rows, cols uint64, p float64) *Mat2D[T] {
+	rng = rngOr(rng)
+	mat := New2D[T](rows, cols)
+	for i := range mat.values {
+		if rng.Float64() < p {
+			mat.values[i] = 1
+		}
+	}
+	return mat
+}
+
+// a random permutation of [0, n)
+func Perm(rng *rand.Rand, n int) []int {
+	return rngOr(rng).Perm(n)
+}
+
+// ## private ##
+
+func rngOr(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return DefaultRNG()
+	}
+	return rng
+}
+
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
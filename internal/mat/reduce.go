@@ -0,0 +1,366 @@
+package mat
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+/*
+* Axis Reductions
+*
+* Every reduction collapses one Axis of m[M, N]:
+*
+*	AxisRows    over the rows, one result per column   [1, N]   (like SumRows)
+*	AxisCols    over the columns, one result per row   [M, 1]   (like SumCols)
+*	AxisAll     over every element                     [1, 1]
+*
+* With keepDims the result keeps that broadcastable shape, so it can be
+* combined with m directly (m - m.Mean(AxisCols, true)). Without it the
+* results are returned as a column vector [k, 1], the layout the rest of
+* gonn uses for vectors, which only differs for AxisRows ([N, 1]).
+*
+* Elements are read through Get, so transposed and sliced views reduce like
+* the matrices they show, and accumulation is done in float64. Reducing an
+* empty axis gives the identity of the reduction where it has one (0 for
+* sums and norms, -Inf for Max and LogSumExp, +Inf for Min) and NaN otherwise.
+*
+* NaNs propagate, as in NumPy: a lane holding a NaN reduces to NaN, and
+* ArgMax / ArgMin point at its first NaN, so they always agree with Max / Min.
+*
+* Every reduction fails on an unknown Axis or Norm, the Must variants exit instead.
+**/
+type Axis int
+
+const (
+	AxisRows Axis = iota
+	AxisCols
+	AxisAll
+)
+
+func (a Axis) String() string {
+	switch a {
+	case AxisRows:
+		return "AxisRows"
+	case AxisCols:
+		return "AxisCols"
+	case AxisAll:
+		return "AxisAll"
+	default:
+		return fmt.Sprintf("Axis(%d)", int(a))
+	}
+}
+
+/*
+* Norm kinds. Along AxisRows / AxisCols each lane is a vector, so L2 and
+* Frobenius agree. Over AxisAll the matrix norms are used:
+*
+*	L1          max absolute column sum
+*	L2          spectral norm, the largest singular value
+*	Frobenius   sqrt(sum of squares), the L2 norm of all elements (e.g. for gradient clipping)
+*	Inf         max absolute row sum
+**/
+type Norm int
+
+const (
+	L1 Norm = iota
+	L2
+	Frobenius
+	Inf
+)
+
+func (n Norm) String() string {
+	switch n {
+	case L1:
+		return "L1"
+	case L2:
+		return "L2"
+	case Frobenius:
+		return "Frobenius"
+	case Inf:
+		return "Inf"
+	default:
+		return fmt.Sprintf("Norm(%d)", int(n))
+	}
+}
+
+func (m *Mat2D[T]) SumAxis(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("SumAxis", axis, keepDims, func(lane []float64) float64 {
+		sum := 0.0
+		for _, x := range lane {
+			sum += x
+		}
+		return sum
+	})
+}
+
+func (m *Mat2D[T]) Mean(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("Mean", axis, keepDims, mean)
+}
+
+func (m *Mat2D[T]) Max(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("Max", axis, keepDims, func(lane []float64) float64 {
+		best := math.Inf(-1)
+		for _, x := range lane {
+			best = math.Max(best, x)
+		}
+		return best
+	})
+}
+
+func (m *Mat2D[T]) Min(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("Min", axis, keepDims, func(lane []float64) float64 {
+		best := math.Inf(1)
+		for _, x := range lane {
+			best = math.Min(best, x)
+		}
+		return best
+	})
+}
+
+// population variance, mean((x - mean(x))^2)
+func (m *Mat2D[T]) Var(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("Var", axis, keepDims, variance)
+}
+
+// population standard deviation, sqrt(Var)
+func (m *Mat2D[T]) Std(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("Std", axis, keepDims, func(lane []float64) float64 {
+		return math.Sqrt(variance(lane))
+	})
+}
+
+// log(sum(exp(x))), shifted by max(x) so large values cannot overflow
+func (m *Mat2D[T]) LogSumExp(axis Axis, keepDims bool) (*Mat2D[T], error) {
+	return m.reduce("LogSumExp", axis, keepDims, func(lane []float64) float64 {
+		shift := math.Inf(-1)
+		for _, x := range lane {
+			shift = math.Max(shift, x)
+		}
+		if math.IsInf(shift, 0) {
+			return shift
+		}
+
+		sum := 0.0
+		for _, x := range lane {
+			sum += math.Exp(x - shift)
+		}
+		return shift + math.Log(sum)
+	})
+}
+
+// also fails when the SVD behind the spectral (L2, AxisAll) norm does not converge
+func (m *Mat2D[T]) Norm(kind Norm, axis Axis, keepDims bool) (*Mat2D[T], error) {
+	switch kind {
+	case L1, L2, Frobenius, Inf:
+	default:
+		return nil, fmt.Errorf("Failed to Norm, reason { unknown %s }", kind)
+	}
+
+	if axis == AxisAll && kind != Frobenius {
+		norm, err := m.matrixNorm(kind)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to Norm, reason { %s }", err)
+		}
+		return m.reduce("Norm", axis, keepDims, func([]float64) float64 { return norm })
+	}
+
+	return m.reduce("Norm", axis, keepDims, func(lane []float64) float64 {
+		norm := 0.0
+		for _, x := range lane {
+			switch kind {
+			case L1:
+				norm += math.Abs(x)
+			case L2, Frobenius:
+				norm = math.Hypot(norm, x)
+			case Inf:
+				norm = math.Max(norm, math.Abs(x))
+			}
+		}
+		return norm
+	})
+}
+
+/*
+* index of the largest element of every lane of axis, the first one on ties:
+* a row index per column for AxisRows (the class of each sample of [classes, N]
+* scores), a column index per row for AxisCols, and the row major index
+* i * N + j for AxisAll. The first NaN wins (see NaNs above), -1 for an empty lane.
+**/
+func (m *Mat2D[T]) ArgMax(axis Axis) ([]int, error) {
+	return m.argBest("ArgMax", axis, func(x, best float64) bool { return x > best })
+}
+
+// index of the smallest element of every lane of axis, see ArgMax
+func (m *Mat2D[T]) ArgMin(axis Axis) ([]int, error) {
+	return m.argBest("ArgMin", axis, func(x, best float64) bool { return x < best })
+}
+
+func (m *Mat2D[T]) MustSumAxis(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.SumAxis(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustMean(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Mean(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustMax(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Max(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustMin(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Min(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustVar(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Var(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustStd(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Std(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustLogSumExp(axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.LogSumExp(axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustNorm(kind Norm, axis Axis, keepDims bool) *Mat2D[T] {
+	return mustReduce(m.Norm(kind, axis, keepDims))
+}
+
+func (m *Mat2D[T]) MustArgMax(axis Axis) []int {
+	return mustReduce(m.ArgMax(axis))
+}
+
+func (m *Mat2D[T]) MustArgMin(axis Axis) []int {
+	return mustReduce(m.ArgMin(axis))
+}
+
+// vvv PRIVATE vvv
+
+// applies f to every lane of axis and lays the results out as described above
+func (m *Mat2D[T]) reduce(name string, axis Axis, keepDims bool, f func(lane []float64) float64) (*Mat2D[T], error) {
+	lanes, err := m.lanes(axis)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to %s, reason { %s }", name, err)
+	}
+
+	var res *Mat2D[T]
+	if axis == AxisRows && keepDims {
+		res = New2D[T](1, uint64(len(lanes)))
+	} else {
+		res = New2D[T](uint64(len(lanes)), 1)
+	}
+
+	for k, lane := range lanes {
+		// res is a fresh contiguous vector, so its kth value is its kth element either way
+		res.values[k] = T(f(lane))
+	}
+	return res, nil
+}
+
+// the values reduced together: each column for AxisRows, each row for AxisCols, all (row major) for AxisAll
+func (m *Mat2D[T]) lanes(axis Axis) ([][]float64, error) {
+	rows, cols := m.Rows(), m.Cols()
+
+	switch axis {
+	case AxisRows:
+		lanes := make([][]float64, cols)
+		for j := range cols {
+			lanes[j] = make([]float64, rows)
+			for i := range rows {
+				lanes[j][i] = float64(m.MustGet(i, j))
+			}
+		}
+		return lanes, nil
+	case AxisCols:
+		lanes := make([][]float64, rows)
+		for i := range rows {
+			lanes[i] = make([]float64, cols)
+			for j := range cols {
+				lanes[i][j] = float64(m.MustGet(i, j))
+			}
+		}
+		return lanes, nil
+	case AxisAll:
+		all := make([]float64, 0, rows*cols)
+		for i := range rows {
+			for j := range cols {
+				all = append(all, float64(m.MustGet(i, j)))
+			}
+		}
+		return [][]float64{all}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s", axis)
+	}
+}
+
+func (m *Mat2D[T]) argBest(name string, axis Axis, better func(x, best float64) bool) ([]int, error) {
+	lanes, err := m.lanes(axis)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to %s, reason { %s }", name, err)
+	}
+	indexes := make([]int, len(lanes))
+
+	for k, lane := range lanes {
+		indexes[k] = -1
+		for i, x := range lane {
+			if math.IsNaN(x) {
+				indexes[k] = i
+				break
+			}
+			if indexes[k] == -1 || better(x, lane[indexes[k]]) {
+				indexes[k] = i
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// L1, L2 (spectral) or Inf norm of the whole matrix, 0 when empty
+func (m *Mat2D[T]) matrixNorm(kind Norm) (float64, error) {
+	if m.Rows() == 0 || m.Cols() == 0 {
+		return 0, nil
+	}
+
+	switch kind {
+	case L1:
+		return denseOf(m).norm1(), nil
+	case Inf:
+		return denseOf(m.TP()).norm1(), nil
+	default: // L2
+		dec, err := ThinSVD(m)
+		if err != nil {
+			return 0, err
+		}
+		return float64(dec.S[0]), nil
+	}
+}
+
+func mustReduce[R any](res R, err error) R {
+	if err != nil {
+		log.Fatal(err)
+	}
+	return res
+}
+
+func mean(lane []float64) float64 {
+	if len(lane) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, x := range lane {
+		sum += x
+	}
+	return sum / float64(len(lane))
+}
+
+// two pass, subtracting the mean first keeps it accurate for large offsets
+func variance(lane []float64) float64 {
+	mu := mean(lane)
+	sum := 0.0
+	for _, x := range lane {
+		sum += (x - mu) * (x - mu)
+	}
+	return sum / float64(len(lane))
+}
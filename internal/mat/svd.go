@@ -0,0 +1,399 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// sweeps over all pairs before giving up, Jacobi methods converge quadratically in a handful
+const jacobiMaxSweeps = 100
+
+/*
+* Singular Value Decomposition
+*
+*	A[m, n] = U * diag(S) * V^T
+*
+* S holds the k = min(m, n) singular values in descending order. The thin
+* decomposition has U[m, k] and V[n, k], the full one the square U[m, m] and
+* V[n, n], orthonormal columns either way (completed to a basis where A has
+* none to give, i.e. for zero singular values). Sigma() gives the diagonal
+* matrix that fits between them.
+*
+* Computed with one-sided (Hestenes) Jacobi rotations in float64, accurate
+* even for the small singular values.
+**/
+type SVD[T Float] struct {
+	U *Mat2D[T]
+	S []T
+	V *Mat2D[T]
+}
+
+func ThinSVD[T Float](a *Mat2D[T]) (*SVD[T], error) {
+	return svd(a, false)
+}
+
+func FullSVD[T Float](a *Mat2D[T]) (*SVD[T], error) {
+	return svd(a, true)
+}
+
+// Sigma[cols(U), cols(V)] with S on the diagonal, so A = U * Sigma * V^T
+func (s *SVD[T]) Sigma() *Mat2D[T] {
+	sigma := New2D[T](uint64(s.U.Cols()), uint64(s.V.Cols()))
+	for i, v := range s.S {
+		sigma.MustSet(int64(i), int64(i), v)
+	}
+	return sigma
+}
+
+/*
+* Symmetric Eigendecomposition
+*
+*	A[n, n] = Vectors * diag(Values) * Vectors^T
+*
+* for a symmetric A, Values in descending order and Vectors orthonormal with
+* Vectors[:, i] the eigenvector of Values[i]. Computed with cyclic Jacobi
+* rotations in float64.
+**/
+type Eigen[T Float] struct {
+	Values  []T
+	Vectors *Mat2D[T]
+}
+
+func SymEig[T Float](a *Mat2D[T]) (*Eigen[T], error) {
+	if err := mustBeSquare("SymEig", a); err != nil {
+		return nil, err
+	}
+
+	A := denseOf(a)
+	n := A.rows
+	symTol := 8 * smallPivot(a)
+	for i := range n {
+		for j := range i {
+			if math.Abs(A.at(i, j)-A.at(j, i)) > symTol {
+				return nil, fmt.Errorf("Failed to SymEig, reason { %w at [%d, %d] }", ErrNotSymmetric, i, j)
+			}
+		}
+	}
+
+	V := identity(n)
+	converged := false
+	for range jacobiMaxSweeps {
+		if A.offDiagonalNorm() <= 0x1p-52*A.frobenius() {
+			converged = true
+			break
+		}
+		for p := range n {
+			for q := p + 1; q < n; q++ {
+				A.jacobiRotate(V, p, q)
+			}
+		}
+	}
+	if !converged {
+		return nil, fmt.Errorf("Failed to SymEig, reason { no convergence after %d sweeps }", jacobiMaxSweeps)
+	}
+
+	values := make([]float64, n)
+	for i := range n {
+		values[i] = A.at(i, i)
+	}
+	order := descending(values)
+
+	eig := &Eigen[T]{Values: make([]T, n), Vectors: New2D[T](uint64(n), uint64(n))}
+	for c, i := range order {
+		eig.Values[c] = T(values[i])
+		for r := range n {
+			eig.Vectors.MustSet(int64(r), int64(c), T(V.at(r, i)))
+		}
+	}
+	return eig, nil
+}
+
+/*
+* Moore-Penrose pseudo-inverse A+[n, m] of A[m, n], through the SVD with
+* singular values at or below the Rank tolerance treated as zero. A+ * B is
+* the minimum norm least squares solution of A*X = B.
+**/
+func Pinv[T Float](a *Mat2D[T]) (*Mat2D[T], error) {
+	dec, err := ThinSVD(a)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to Pinv, reason { %s }", err)
+	}
+
+	tol := rankTol(dec.S, a)
+	U, V := denseOf(dec.U), denseOf(dec.V)
+	m, n := U.rows, V.rows
+
+	// A+ = V * diag(1/S) * U^T over the non zero singular values
+	pinv := newDense(n, m)
+	for k, sv := range dec.S {
+		if float64(sv) <= tol {
+			continue
+		}
+		inv := 1 / float64(sv)
+		for i := range n {
+			vik := V.at(i, k) * inv
+			for j := range m {
+				pinv.set(i, j, pinv.at(i, j)+vik*U.at(j, k))
+			}
+		}
+	}
+
+	return matOf[T](pinv), nil
+}
+
+// number of singular values above max(m, n) * eps(T) * max(S)
+func Rank[T Float](a *Mat2D[T]) (int, error) {
+	dec, err := ThinSVD(a)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to Rank, reason { %s }", err)
+	}
+
+	tol := rankTol(dec.S, a)
+	rank := 0
+	for _, sv := range dec.S {
+		if float64(sv) > tol {
+			rank++
+		}
+	}
+	return rank, nil
+}
+
+// vvv PRIVATE vvv
+
+func svd[T Float](a *Mat2D[T], full bool) (*SVD[T], error) {
+	if a == nil || a.Rows() == 0 || a.Cols() == 0 {
+		return nil, fmt.Errorf("Failed to SVD, reason { empty matrix %s }", dimsOf(a))
+	}
+
+	// a wide A is the transpose of a tall one: A^T = V * S * U^T
+	if a.Rows() < a.Cols() {
+		dec, err := svd(a.TP(), full)
+		if err != nil {
+			return nil, err
+		}
+		return &SVD[T]{U: dec.V, S: dec.S, V: dec.U}, nil
+	}
+
+	U := denseOf(a)
+	m, n := U.rows, U.cols
+	V := identity(n)
+
+	converged := false
+	for range jacobiMaxSweeps {
+		rotated := false
+		for p := range n {
+			for q := p + 1; q < n; q++ {
+				if U.orthogonalize(V, p, q) {
+					rotated = true
+				}
+			}
+		}
+		if !rotated {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return nil, fmt.Errorf("Failed to SVD, reason { no convergence after %d sweeps }", jacobiMaxSweeps)
+	}
+
+	// the columns of U are now orthogonal, their norms are the singular values
+	sigmas := make([]float64, n)
+	for j := range n {
+		sigmas[j] = U.colNorm(j)
+	}
+	order := descending(sigmas)
+
+	cols := n
+	if full {
+		cols = m
+	}
+	outU, outV := newDense(m, cols), newDense(n, n)
+	S := make([]T, n)
+	small := float64(m) * 0x1p-52 * sigmas[order[0]]
+	found := 0 // leading columns of outU that are set
+	for c, j := range order {
+		S[c] = T(sigmas[j])
+		for i := range n {
+			outV.set(i, c, V.at(i, j))
+		}
+		if sigmas[j] > small && found == c {
+			for i := range m {
+				outU.set(i, c, U.at(i, j)/sigmas[j])
+			}
+			found++
+		}
+	}
+	outU.completeBasis(found)
+
+	return &SVD[T]{U: matOf[T](outU), S: S, V: matOf[T](outV)}, nil
+}
+
+/*
+* rotates columns p and q of d (and V alongside) to make them orthogonal,
+* returns false when they already are to working precision
+**/
+func (d *dense) orthogonalize(V *dense, p, q int) bool {
+	var alpha, beta, gamma float64
+	for i := range d.rows {
+		up, uq := d.at(i, p), d.at(i, q)
+		alpha += up * up
+		beta += uq * uq
+		gamma += up * uq
+	}
+	if gamma == 0 || math.Abs(gamma) <= 0x1p-52*math.Sqrt(alpha*beta) {
+		return false
+	}
+
+	zeta := (beta - alpha) / (2 * gamma)
+	t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Hypot(1, zeta))
+	c := 1 / math.Hypot(1, t)
+	s := c * t
+
+	d.rotateCols(p, q, c, s)
+	V.rotateCols(p, q, c, s)
+	return true
+}
+
+/*
+* one Jacobi rotation A' = J^T * A * J zeroing a[p, q] of the symmetric d,
+* accumulating V' = V * J
+**/
+func (d *dense) jacobiRotate(V *dense, p, q int) {
+	apq := d.at(p, q)
+	if apq == 0 {
+		return
+	}
+
+	theta := (d.at(q, q) - d.at(p, p)) / (2 * apq)
+	t := math.Copysign(1, theta) / (math.Abs(theta) + math.Hypot(1, theta))
+	c := 1 / math.Hypot(1, t)
+	s := c * t
+
+	d.rotateCols(p, q, c, s)
+	d.rotateRows(p, q, c, s)
+	V.rotateCols(p, q, c, s)
+	d.set(p, q, 0)
+	d.set(q, p, 0)
+}
+
+// col p, col q = c * col p - s * col q, s * col p + c * col q
+func (d *dense) rotateCols(p, q int, c, s float64) {
+	for i := range d.rows {
+		xp, xq := d.at(i, p), d.at(i, q)
+		d.set(i, p, c*xp-s*xq)
+		d.set(i, q, s*xp+c*xq)
+	}
+}
+
+// row p, row q = c * row p - s * row q, s * row p + c * row q
+func (d *dense) rotateRows(p, q int, c, s float64) {
+	for j := range d.cols {
+		xp, xq := d.at(p, j), d.at(q, j)
+		d.set(p, j, c*xp-s*xq)
+		d.set(q, j, s*xp+c*xq)
+	}
+}
+
+/*
+* fills columns have.. of d with unit vectors orthogonal to all previous
+* columns, Gram-Schmidt (twice) on the standard basis vector that keeps the
+* most of its norm
+**/
+func (d *dense) completeBasis(have int) {
+	for c := have; c < d.cols; c++ {
+		best, bestNorm := []float64(nil), 0.0
+		for e := range d.rows {
+			v := make([]float64, d.rows)
+			v[e] = 1
+			for range 2 {
+				for k := range c {
+					dot := 0.0
+					for i := range d.rows {
+						dot += d.at(i, k) * v[i]
+					}
+					for i := range d.rows {
+						v[i] -= dot * d.at(i, k)
+					}
+				}
+			}
+
+			norm := 0.0
+			for _, x := range v {
+				norm = math.Hypot(norm, x)
+			}
+			if norm > bestNorm {
+				best, bestNorm = v, norm
+			}
+		}
+
+		for i := range d.rows {
+			d.set(i, c, best[i]/bestNorm)
+		}
+	}
+}
+
+func (d *dense) colNorm(j int) float64 {
+	norm := 0.0
+	for i := range d.rows {
+		norm = math.Hypot(norm, d.at(i, j))
+	}
+	return norm
+}
+
+func (d *dense) frobenius() float64 {
+	norm := 0.0
+	for _, x := range d.v {
+		norm = math.Hypot(norm, x)
+	}
+	return norm
+}
+
+func (d *dense) offDiagonalNorm() float64 {
+	norm := 0.0
+	for i := range d.rows {
+		for j := range d.cols {
+			if i != j {
+				norm = math.Hypot(norm, d.at(i, j))
+			}
+		}
+	}
+	return norm
+}
+
+func identity(n int) *dense {
+	d := newDense(n, n)
+	for i := range n {
+		d.set(i, i, 1)
+	}
+	return d
+}
+
+// indexes of values from largest to smallest
+func descending(values []float64) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case values[a] > values[b]:
+			return -1
+		case values[a] < values[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return order
+}
+
+// singular values at or below this are zero, relative to the precision of T
+func rankTol[T Float](S []T, a *Mat2D[T]) float64 {
+	eps := 0x1p-52
+	if _, ok := any(S).([]float32); ok {
+		eps = 0x1p-23
+	}
+	return float64(max(a.Rows(), a.Cols())) * eps * float64(S[0])
+}
@@ -0,0 +1,455 @@
+package mat
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+/*
+* Tensor
+*
+* N dimensional generalization of Mat2D. Element idx lives at
+* values[offset + sum(idx[a] * strides[a])], which lets Permute, Reshape
+* (when contiguous), Slice and Expand return views that share values.
+* A stride of 0 marks an expanded (broadcast) axis.
+**/
+type Tensor[T Float] struct {
+	shape   []uint64
+	strides []uint64
+	offset  uint64
+
+	values []T
+}
+
+// Aliases
+type TensorF32 = Tensor[float32]
+type TensorF64 = Tensor[float64]
+
+// Constructors
+func NewTensor[T Float](shape ...uint64) *Tensor[T] {
+	return &Tensor[T]{
+		shape:   slices.Clone(shape),
+		strides: contiguousStrides(shape),
+		offset:  0,
+
+		values: make([]T, shapeSize(shape)),
+	}
+}
+
+func TensorFromValues[T Float](values []T, shape ...uint64) (*Tensor[T], error) {
+	if uint64(len(values)) != shapeSize(shape) {
+		return nil, fmt.Errorf(
+			"Cannot create Tensor%v from %d values",
+			shape, len(values),
+		)
+	}
+
+	return &Tensor[T]{
+		shape:   slices.Clone(shape),
+		strides: contiguousStrides(shape),
+		offset:  0,
+
+		values: values,
+	}, nil
+}
+
+// zero-copy view of m as a rank 2 tensor
+func TensorFromMat2D[T Float](m *Mat2D[T]) *Tensor[T] {
+	strides := []uint64{m.stride, 1}
+	if m.transposed {
+		strides = []uint64{1, m.stride}
+	}
+
+	return &Tensor[T]{
+		shape:   []uint64{m.rows, m.cols},
+		strides: strides,
+		offset:  0,
+
+		values: m.values,
+	}
+}
+
+// End Constructors
+
+func (t *Tensor[T]) Shape() []uint64 {
+	return slices.Clone(t.shape)
+}
+
+func (t *Tensor[T]) Strides() []uint64 {
+	return slices.Clone(t.strides)
+}
+
+func (t *Tensor[T]) Rank() int {
+	return len(t.shape)
+}
+
+func (t *Tensor[T]) Size() uint64 {
+	return shapeSize(t.shape)
+}
+
+func (t *Tensor[T]) IsContiguous() bool {
+	return slices.Equal(t.strides, contiguousStrides(t.shape))
+}
+
+func (t *Tensor[T]) Get(idx ...int64) (T, error) {
+	index, err := t.valueIndex(idx)
+	if err != nil {
+		return 0, err
+	}
+	return t.values[index], nil
+}
+
+func (t *Tensor[T]) MustGet(idx ...int64) T {
+	value, err := t.Get(idx...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return value
+}
+
+func (t *Tensor[T]) Set(val T, idx ...int64) error {
+	index, err := t.valueIndex(idx)
+	if err != nil {
+		return err
+	}
+	t.values[index] = val
+	return nil
+}
+
+func (t *Tensor[T]) MustSet(val T, idx ...int64) {
+	if err := t.Set(val, idx...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// contiguous copy of t
+func (t *Tensor[T]) Clone() *Tensor[T] {
+	clone := NewTensor[T](t.shape...)
+
+	i := 0
+	t.forEach(func(index uint64) {
+		clone.values[i] = t.values[index]
+		i++
+	})
+
+	return clone
+}
+
+// applies f in place, note that expanded axes share their values
+func (t *Tensor[T]) Apply(f func(T) T) *Tensor[T] {
+	t.forEach(func(index uint64) {
+		t.values[index] = f(t.values[index])
+	})
+	return t
+}
+
+func (t *Tensor[T]) Fill(val T) *Tensor[T] {
+	return t.Apply(func(T) T { return val })
+}
+
+/*
+* Permute
+*
+* Reorders the axes of t, axes[k] is the axis of t that becomes axis k.
+* Permute(1, 0) on a rank 2 tensor is a transpose.
+**/
+func (t *Tensor[T]) Permute(axes ...int) (*Tensor[T], error) {
+	if len(axes) != t.Rank() {
+		return nil, fmt.Errorf(
+			"Invalid permutation %v for Tensor%v, expected %d axes",
+			axes, t.shape, t.Rank(),
+		)
+	}
+
+	seen := make([]bool, t.Rank())
+	shape := make([]uint64, t.Rank())
+	strides := make([]uint64, t.Rank())
+
+	for k, axis := range axes {
+		if axis < 0 || axis >= t.Rank() || seen[axis] {
+			return nil, fmt.Errorf(
+				"Invalid permutation %v for Tensor%v",
+				axes, t.shape,
+			)
+		}
+		seen[axis] = true
+		shape[k] = t.shape[axis]
+		strides[k] = t.strides[axis]
+	}
+
+	return t.view(shape, strides, t.offset), nil
+}
+
+func (t *Tensor[T]) MustPermute(axes ...int) *Tensor[T] {
+	p, err := t.Permute(axes...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return p
+}
+
+/*
+* Reshape
+*
+* Returns a view with the new shape when t is contiguous,
+* otherwise t is first copied into contiguous memory.
+**/
+func (t *Tensor[T]) Reshape(shape ...uint64) (*Tensor[T], error) {
+	if shapeSize(shape) != t.Size() {
+		return nil, fmt.Errorf(
+			"Invalid reshape%v for Tensor%v",
+			shape, t.shape,
+		)
+	}
+
+	src := t
+	if !t.IsContiguous() {
+		src = t.Clone()
+	}
+
+	return src.view(slices.Clone(shape), contiguousStrides(shape), src.offset), nil
+}
+
+func (t *Tensor[T]) MustReshape(shape ...uint64) *Tensor[T] {
+	r, err := t.Reshape(shape...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return r
+}
+
+/*
+* Slice
+*
+* Takes ranges[a] = [start, end) along each axis a, axes without a range
+* are kept whole. Negative values count back from the end of the axis.
+**/
+func (t *Tensor[T]) Slice(ranges ...SliceRange) (*Tensor[T], error) {
+	if len(ranges) > t.Rank() {
+		return nil, fmt.Errorf(
+			"Invalid slice %v for Tensor%v, too many ranges",
+			ranges, t.shape,
+		)
+	}
+
+	shape := slices.Clone(t.shape)
+	offset := t.offset
+
+	for axis, r := range ranges {
+		dim := int64(t.shape[axis])
+		start, end := r[0], r[1]
+		if start < 0 {
+			start += dim
+		}
+		if end < 0 {
+			end += dim
+		}
+
+		if !(0 <= start && start < end && end <= dim) {
+			return nil, fmt.Errorf(
+				"Invalid slice %v for Tensor%v, axis %d range %v out of bounds",
+				ranges, t.shape, axis, r,
+			)
+		}
+
+		shape[axis] = uint64(end - start)
+		offset += uint64(start) * t.strides[axis]
+	}
+
+	return t.view(shape, slices.Clone(t.strides), offset), nil
+}
+
+func (t *Tensor[T]) MustSlice(ranges ...SliceRange) *Tensor[T] {
+	s, err := t.Slice(ranges...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+/*
+* Expand
+*
+* Broadcasts t to shape without copying, following NumPy rules:
+* t is right aligned against shape, axes of size 1 may grow to any size
+* and new leading axes may be added.
+**/
+func (t *Tensor[T]) Expand(shape ...uint64) (*Tensor[T], error) {
+	if len(shape) < t.Rank() {
+		return nil, fmt.Errorf(
+			"Cannot expand Tensor%v to lower rank shape%v",
+			t.shape, shape,
+		)
+	}
+
+	lead := len(shape) - t.Rank()
+	strides := make([]uint64, len(shape)) // new leading axes have stride 0
+
+	for axis := range t.Rank() {
+		dim, target := t.shape[axis], shape[lead+axis]
+
+		switch {
+		case dim == target:
+			strides[lead+axis] = t.strides[axis]
+		case dim == 1:
+			strides[lead+axis] = 0
+		default:
+			return nil, fmt.Errorf(
+				"Cannot expand Tensor%v to shape%v, axis %d has size %d != %d",
+				t.shape, shape, axis, dim, target,
+			)
+		}
+	}
+
+	return t.view(slices.Clone(shape), strides, t.offset), nil
+}
+
+func (t *Tensor[T]) MustExpand(shape ...uint64) *Tensor[T] {
+	e, err := t.Expand(shape...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return e
+}
+
+/*
+* ToMat2D
+*
+* Converts a rank 1 ([N] -> [1, N]) or rank 2 tensor to a Mat2D,
+* sharing values when the layout is row or column major and copying otherwise.
+**/
+func (t *Tensor[T]) ToMat2D() (*Mat2D[T], error) {
+	switch t.Rank() {
+	case 1:
+		r, err := t.Reshape(1, t.shape[0])
+		if err != nil {
+			return nil, err
+		}
+		return r.ToMat2D()
+	case 2:
+	default:
+		return nil, fmt.Errorf(
+			"Cannot convert Tensor%v of rank %d to Mat2D",
+			t.shape, t.Rank(),
+		)
+	}
+
+	rows, cols := t.shape[0], t.shape[1]
+	values := t.values[t.offset:]
+
+	switch {
+	case rows <= 1 && t.strides[1] == 1:
+		return &Mat2D[T]{rows: rows, cols: cols, stride: cols, values: values}, nil
+
+	case t.strides[1] == 1 && t.strides[0] >= cols:
+		return &Mat2D[T]{rows: rows, cols: cols, stride: t.strides[0], values: values}, nil
+
+	case cols <= 1 && t.strides[0] == 1:
+		return (&Mat2D[T]{rows: cols, cols: rows, stride: rows, values: values}).TP(), nil
+
+	case t.strides[0] == 1 && t.strides[1] >= rows:
+		return (&Mat2D[T]{rows: cols, cols: rows, stride: t.strides[1], values: values}).TP(), nil
+	}
+
+	return t.Clone().ToMat2D()
+}
+
+func (t *Tensor[T]) MustToMat2D() *Mat2D[T] {
+	m, err := t.ToMat2D()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return m
+}
+
+func TensorEquals[T Float](a, b *Tensor[T]) bool {
+	if !slices.Equal(a.shape, b.shape) {
+		return false
+	}
+
+	ac, bc := a.Clone(), b.Clone()
+	return slices.Equal(ac.values, bc.values)
+}
+
+// vvv PRIVATE vvv
+
+func (t *Tensor[T]) view(shape, strides []uint64, offset uint64) *Tensor[T] {
+	return &Tensor[T]{
+		shape:   shape,
+		strides: strides,
+		offset:  offset,
+
+		values: t.values,
+	}
+}
+
+func (t *Tensor[T]) valueIndex(idx []int64) (uint64, error) {
+	if len(idx) != t.Rank() {
+		return 0, fmt.Errorf(
+			"Index%v does not match rank %d of Tensor%v",
+			idx, t.Rank(), t.shape,
+		)
+	}
+
+	index := t.offset
+	for axis, i := range idx {
+		dim := int64(t.shape[axis])
+		if i < -dim || i >= dim {
+			return 0, fmt.Errorf(
+				"Index%v out of bounds%v",
+				idx, t.shape,
+			)
+		}
+		index += uint64((i+dim)%dim) * t.strides[axis]
+	}
+
+	return index, nil
+}
+
+// calls f with the value index of every element of t in row major order
+func (t *Tensor[T]) forEach(f func(index uint64)) {
+	if t.Size() == 0 {
+		return
+	}
+
+	idx := make([]uint64, t.Rank())
+	index := t.offset
+
+	for {
+		f(index)
+
+		// odometer increment, last axis fastest
+		axis := t.Rank() - 1
+		for ; axis >= 0; axis-- {
+			idx[axis]++
+			index += t.strides[axis]
+			if idx[axis] < t.shape[axis] {
+				break
+			}
+			index -= idx[axis] * t.strides[axis]
+			idx[axis] = 0
+		}
+		if axis < 0 {
+			return
+		}
+	}
+}
+
+func shapeSize(shape []uint64) uint64 {
+	size := uint64(1)
+	for _, dim := range shape {
+		size *= dim
+	}
+	return size
+}
+
+func contiguousStrides(shape []uint64) []uint64 {
+	strides := make([]uint64, len(shape))
+	stride := uint64(1)
+	for axis := len(shape) - 1; axis >= 0; axis-- {
+		strides[axis] = stride
+		stride *= shape[axis]
+	}
+	return strides
+}
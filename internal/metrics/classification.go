@@ -0,0 +1,277 @@
+package metrics
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+// Probabilities are clipped to [LogLossEps, 1 - LogLossEps] so log(0) is never taken
+const LogLossEps = 1e-15
+
+// fraction of samples whose predicted class is the target class
+func Accuracy[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	correct, n, err := accuracySum(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(correct, n), nil
+}
+
+// fraction of samples whose target class is among the k largest predictions
+func TopKAccuracy[T mat.Float](y, y_ *mat.Mat2D[T], k int) (float64, error) {
+	correct, n, err := topKSum(y, y_, k)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(correct, n), nil
+}
+
+/*
+* Log Loss
+*
+* Mean cross entropy of predicted probabilities y_ (not logits) over samples,
+*
+*	-sum_i y[i, j] * log(y_[i, j])                      several rows
+*	-(y[0, j] * log(y_[0, j]) + (1 - y[0, j]) * log(1 - y_[0, j]))   one row
+**/
+func LogLoss[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	sum, n, err := logLossSum(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(sum, n), nil
+}
+
+/*
+* Confusion
+*
+* Counts[actual][predicted] of single label classification decisions, with
+* the precision, recall and F1 derived from them:
+*
+*	precision[k] = Counts[k][k] / sum_a Counts[a][k]
+*	recall[k]    = Counts[k][k] / sum_p Counts[k][p]
+*	f1[k]        = 2 * precision[k] * recall[k] / (precision[k] + recall[k])
+*
+* Undefined ratios (a class never predicted or never present) count as 0.
+**/
+type Confusion struct {
+	Counts [][]int
+}
+
+func NewConfusion(classes int) *Confusion {
+	counts := make([][]int, classes)
+	for k := range counts {
+		counts[k] = make([]int, classes)
+	}
+	return &Confusion{Counts: counts}
+}
+
+// Confusion of the classes of y_ against those of y
+func ConfusionMatrix[T mat.Float](y, y_ *mat.Mat2D[T]) (*Confusion, error) {
+	if err := matchDims("ConfusionMatrix", y, y_); err != nil {
+		return nil, err
+	}
+
+	c := NewConfusion(classCount(y))
+	c.Add(Classes(y), Classes(y_))
+	return c, nil
+}
+
+// counts the decisions predicted[j] for samples of class actual[j]
+func (c *Confusion) Add(actual, predicted []int) {
+	for j := range actual {
+		c.Counts[actual[j]][predicted[j]]++
+	}
+}
+
+func (c *Confusion) Classes() int {
+	return len(c.Counts)
+}
+
+func (c *Confusion) Total() int {
+	total := 0
+	for _, row := range c.Counts {
+		for _, count := range row {
+			total += count
+		}
+	}
+	return total
+}
+
+func (c *Confusion) Accuracy() float64 {
+	correct := 0
+	for k := range c.Counts {
+		correct += c.Counts[k][k]
+	}
+	return ratio(float64(correct), c.Total())
+}
+
+// number of samples of class k
+func (c *Confusion) Support(k int) int {
+	support := 0
+	for _, count := range c.Counts[k] {
+		support += count
+	}
+	return support
+}
+
+// precision, recall and F1 of class k alone
+func (c *Confusion) ClassScores(k int) (precision, recall, f1 float64) {
+	predicted := 0
+	for a := range c.Counts {
+		predicted += c.Counts[a][k]
+	}
+
+	tp := float64(c.Counts[k][k])
+	precision = safeDiv(tp, float64(predicted))
+	recall = safeDiv(tp, float64(c.Support(k)))
+	f1 = safeDiv(2*precision*recall, precision+recall)
+	return precision, recall, f1
+}
+
+func (c *Confusion) Precision(avg Average) (float64, error) {
+	return c.average(avg, func(k int) float64 {
+		p, _, _ := c.ClassScores(k)
+		return p
+	})
+}
+
+func (c *Confusion) Recall(avg Average) (float64, error) {
+	return c.average(avg, func(k int) float64 {
+		_, r, _ := c.ClassScores(k)
+		return r
+	})
+}
+
+func (c *Confusion) F1(avg Average) (float64, error) {
+	return c.average(avg, func(k int) float64 {
+		_, _, f1 := c.ClassScores(k)
+		return f1
+	})
+}
+
+func Precision[T mat.Float](y, y_ *mat.Mat2D[T], avg Average) (float64, error) {
+	c, err := ConfusionMatrix(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return c.Precision(avg)
+}
+
+func Recall[T mat.Float](y, y_ *mat.Mat2D[T], avg Average) (float64, error) {
+	c, err := ConfusionMatrix(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return c.Recall(avg)
+}
+
+func F1[T mat.Float](y, y_ *mat.Mat2D[T], avg Average) (float64, error) {
+	c, err := ConfusionMatrix(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return c.F1(avg)
+}
+
+// ## private ##
+
+func (c *Confusion) average(avg Average, score func(k int) float64) (float64, error) {
+	switch avg {
+	case Micro:
+		// every wrong decision is a false positive for one class and a false negative for another
+		return c.Accuracy(), nil
+	case Macro:
+		var sum float64
+		for k := range c.Counts {
+			sum += score(k)
+		}
+		return ratio(sum, c.Classes()), nil
+	case Weighted:
+		var sum float64
+		for k := range c.Counts {
+			sum += score(k) * float64(c.Support(k))
+		}
+		return ratio(sum, c.Total()), nil
+	case Binary:
+		if c.Classes() != 2 {
+			return 0, fmt.Errorf("Binary average of %d classes, expected 2", c.Classes())
+		}
+		return score(1), nil
+	default:
+		return 0, fmt.Errorf("Unknown %s", avg)
+	}
+}
+
+func accuracySum[T mat.Float](y, y_ *mat.Mat2D[T]) (correct float64, n int, err error) {
+	if err := matchDims("Accuracy", y, y_); err != nil {
+		return 0, 0, err
+	}
+
+	actual, predicted := Classes(y), Classes(y_)
+	for j := range actual {
+		if actual[j] == predicted[j] {
+			correct++
+		}
+	}
+	return correct, len(actual), nil
+}
+
+func topKSum[T mat.Float](y, y_ *mat.Mat2D[T], k int) (correct float64, n int, err error) {
+	if err := matchDims("TopKAccuracy", y, y_); err != nil {
+		return 0, 0, err
+	}
+	if y.Rows() < 2 || k < 1 || k > int(y.Rows()) {
+		return 0, 0, fmt.Errorf(
+			"Failed to TopKAccuracy, reason { k = %d needs one row per class and 1 <= k <= %d }", k, y.Rows(),
+		)
+	}
+
+	for j, target := range Classes(y) {
+		// ties with the target count in its favour
+		score := y_.MustGet(int64(target), int64(j))
+		above := 0
+		for i := range y_.Rows() {
+			if y_.MustGet(i, int64(j)) > score {
+				above++
+			}
+		}
+		if above < k {
+			correct++
+		}
+	}
+	return correct, int(y.Cols()), nil
+}
+
+func logLossSum[T mat.Float](y, y_ *mat.Mat2D[T]) (sum float64, n int, err error) {
+	if err := matchDims("LogLoss", y, y_); err != nil {
+		return 0, 0, err
+	}
+
+	logClipped := func(p float64) float64 {
+		return math.Log(min(max(p, LogLossEps), 1-LogLossEps))
+	}
+
+	for j := range y.Cols() {
+		if y.Rows() == 1 {
+			Y, P := float64(y.MustGet(0, j)), float64(y_.MustGet(0, j))
+			sum -= Y*logClipped(P) + (1-Y)*logClipped(1-P)
+			continue
+		}
+		for i := range y.Rows() {
+			if Y := float64(y.MustGet(i, j)); Y != 0 {
+				sum -= Y * logClipped(float64(y_.MustGet(i, j)))
+			}
+		}
+	}
+	return sum, int(y.Cols()), nil
+}
+
+// 0 for an undefined ratio
+func safeDiv(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
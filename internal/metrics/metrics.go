@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* Metrics
+*
+* Scores of predictions y_[outputs, N] against targets y[outputs, N], one
+* sample per column like everywhere else. For classification a column with
+* several rows is one class per row (one-hot targets, probabilities or
+* logits), its class is the row of its largest value. A single row is a
+* binary problem, its class is 1 when the value is >= 0.5.
+*
+* Each metric is a plain function over one batch, and a streaming
+* accumulator (Reset, Update per batch, Value) that gives the same result
+* over all the batches of an epoch, usable as a train.Metric.
+**/
+
+// How per class precision, recall and F1 are combined into one score
+type Average int
+
+const (
+	Micro    Average = iota // over all decisions pooled (equals accuracy for single label problems)
+	Macro                   // unweighted mean over classes
+	Weighted                // mean over classes weighted by their support (true count)
+	Binary                  // of class 1 only
+)
+
+func (a Average) String() string {
+	switch a {
+	case Micro:
+		return "micro"
+	case Macro:
+		return "macro"
+	case Weighted:
+		return "weighted"
+	case Binary:
+		return "binary"
+	default:
+		return fmt.Sprintf("Average(%d)", int(a))
+	}
+}
+
+// the class of every column of m
+func Classes[T mat.Float](m *mat.Mat2D[T]) []int {
+	classes := make([]int, m.Cols())
+	for j := range m.Cols() {
+		if m.Rows() == 1 {
+			if m.MustGet(0, j) >= 0.5 {
+				classes[j] = 1
+			}
+			continue
+		}
+
+		best := int64(0)
+		for i := range m.Rows() {
+			if m.MustGet(i, j) > m.MustGet(best, j) {
+				best = i
+			}
+		}
+		classes[j] = int(best)
+	}
+	return classes
+}
+
+// ## private ##
+
+func matchDims[T mat.Float](name string, y, y_ *mat.Mat2D[T]) error {
+	if y == nil || y_ == nil {
+		return fmt.Errorf("Failed to %s, reason { nil matrix }", name)
+	}
+	if !mat.DimsMatch(y, y_) {
+		return fmt.Errorf(
+			"Failed to %s, reason { mismatched dims y[%d, %d] y_[%d, %d] }",
+			name,
+			y.Rows(), y.Cols(),
+			y_.Rows(), y_.Cols(),
+		)
+	}
+	if y.Cols() == 0 {
+		return fmt.Errorf("Failed to %s, reason { no samples }", name)
+	}
+	return nil
+}
+
+// number of classes of [outputs, N] predictions, a single row is binary
+func classCount[T mat.Float](m *mat.Mat2D[T]) int {
+	return max(int(m.Rows()), 2)
+}
+
+// NaN as the value of a metric without any samples yet
+func ratio(sum float64, n int) float64 {
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"gonn/internal/mat"
+	"math"
+)
+
+// mean absolute error over all elements
+func MAE[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	sum, n, err := absErrorSum(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return ratio(sum, n), nil
+}
+
+// root mean squared error over all elements
+func RMSE[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	sum, n, err := squaredErrorSum(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(ratio(sum, n)), nil
+}
+
+/*
+* R² (coefficient of determination)
+*
+*	r2[i] = 1 - sum_j (y[i, j] - y_[i, j])^2 / sum_j (y[i, j] - mean_j y[i, j])^2
+*
+* averaged over the outputs i. An output with constant targets scores 1 if
+* predicted exactly and 0 otherwise.
+**/
+func R2[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	r2 := NewR2[T]()
+	if err := r2.Update(y, y_); err != nil {
+		return 0, err
+	}
+	return r2.Value(), nil
+}
+
+// ## private ##
+
+func absErrorSum[T mat.Float](y, y_ *mat.Mat2D[T]) (sum float64, n int, err error) {
+	if err := matchDims("MAE", y, y_); err != nil {
+		return 0, 0, err
+	}
+	for i := range y.Rows() {
+		for j := range y.Cols() {
+			sum += math.Abs(float64(y.MustGet(i, j) - y_.MustGet(i, j)))
+		}
+	}
+	return sum, int(y.Rows() * y.Cols()), nil
+}
+
+func squaredErrorSum[T mat.Float](y, y_ *mat.Mat2D[T]) (sum float64, n int, err error) {
+	if err := matchDims("RMSE", y, y_); err != nil {
+		return 0, 0, err
+	}
+	for i := range y.Rows() {
+		for j := range y.Cols() {
+			diff := float64(y.MustGet(i, j) - y_.MustGet(i, j))
+			sum += diff * diff
+		}
+	}
+	return sum, int(y.Rows() * y.Cols()), nil
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+	"slices"
+)
+
+/*
+* ROC Curve
+*
+* Of binary targets y[1, N] (0 or 1) against scores y_[1, N], higher meaning
+* more likely positive. Point i is the false and true positive rates when
+* samples scoring >= Thresholds[i] are predicted positive, from (0, 0) with
+* an infinite threshold up to (1, 1).
+**/
+type ROCCurve struct {
+	FPR        []float64
+	TPR        []float64
+	Thresholds []float64
+}
+
+func ROC[T mat.Float](y, y_ *mat.Mat2D[T]) (*ROCCurve, error) {
+	if err := matchDims("ROC", y, y_); err != nil {
+		return nil, err
+	}
+	if y.Rows() != 1 {
+		return nil, fmt.Errorf("Failed to ROC, reason { expected binary targets y[1, N], found %d rows }", y.Rows())
+	}
+
+	labels := make([]bool, y.Cols())
+	scores := make([]float64, y.Cols())
+	for j := range y.Cols() {
+		labels[j] = y.MustGet(0, j) >= 0.5
+		scores[j] = float64(y_.MustGet(0, j))
+	}
+
+	roc, err := rocCurve(labels, scores)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to ROC, reason { %s }", err)
+	}
+	return roc, nil
+}
+
+// area under the curve through the points (x[i], y[i]) by the trapezoidal rule, x sorted
+func AUC(x, y []float64) (float64, error) {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0, fmt.Errorf("Failed to AUC, reason { need at least 2 points, found %d x and %d y }", len(x), len(y))
+	}
+
+	var area float64
+	for i := 1; i < len(x); i++ {
+		area += (x[i] - x[i-1]) * (y[i] + y[i-1]) / 2
+	}
+	return area, nil
+}
+
+// the area under the ROC curve of y_ against y, 1 for a perfect ranking and 0.5 for a random one
+func ROCAUC[T mat.Float](y, y_ *mat.Mat2D[T]) (float64, error) {
+	roc, err := ROC(y, y_)
+	if err != nil {
+		return 0, err
+	}
+	return roc.AUC(), nil
+}
+
+func (roc *ROCCurve) AUC() float64 {
+	area, _ := AUC(roc.FPR, roc.TPR) // a curve always has at least 2 points
+	return area
+}
+
+// ## private ##
+
+func rocCurve(labels []bool, scores []float64) (*ROCCurve, error) {
+	positives := 0
+	for _, label := range labels {
+		if label {
+			positives++
+		}
+	}
+	negatives := len(labels) - positives
+	if positives == 0 || negatives == 0 {
+		return nil, fmt.Errorf("need both positive and negative samples, found %d and %d", positives, negatives)
+	}
+
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	roc := &ROCCurve{
+		FPR:        []float64{0},
+		TPR:        []float64{0},
+		Thresholds: []float64{math.Inf(1)},
+	}
+
+	tp, fp := 0, 0
+	for i, j := range order {
+		if labels[j] {
+			tp++
+		} else {
+			fp++
+		}
+
+		// one point per distinct score, after all the samples tied at it
+		if i+1 < len(order) && scores[order[i+1]] == scores[j] {
+			continue
+		}
+		roc.FPR = append(roc.FPR, float64(fp)/float64(negatives))
+		roc.TPR = append(roc.TPR, float64(tp)/float64(positives))
+		roc.Thresholds = append(roc.Thresholds, scores[j])
+	}
+
+	return roc, nil
+}
@@ -0,0 +1,249 @@
+package metrics
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* Streaming accumulators
+*
+* Each aggregates a metric over mini-batches, Update(y, y_) adds a batch and
+* Value is the metric over everything added since the last Reset, exactly as
+* if it was computed on all the batches at once (NaN before any sample).
+* They implement train.Metric:
+*
+*	trainer.Metrics = []train.Metric[float32]{metrics.NewAccuracy[float32](), metrics.NewF1[float32](metrics.Macro)}
+**/
+
+// A metric that is a sum over samples (or elements) divided by their count
+type Mean[T mat.Float] struct {
+	name   string
+	batch  func(y, y_ *mat.Mat2D[T]) (sum float64, n int, err error)
+	finish func(mean float64) float64
+
+	sum float64
+	n   int
+}
+
+func NewAccuracy[T mat.Float]() *Mean[T] {
+	return &Mean[T]{name: "accuracy", batch: accuracySum[T]}
+}
+
+func NewTopKAccuracy[T mat.Float](k int) *Mean[T] {
+	return &Mean[T]{
+		name: fmt.Sprintf("top%d_accuracy", k),
+		batch: func(y, y_ *mat.Mat2D[T]) (float64, int, error) {
+			return topKSum(y, y_, k)
+		},
+	}
+}
+
+func NewLogLoss[T mat.Float]() *Mean[T] {
+	return &Mean[T]{name: "log_loss", batch: logLossSum[T]}
+}
+
+func NewMAE[T mat.Float]() *Mean[T] {
+	return &Mean[T]{name: "mae", batch: absErrorSum[T]}
+}
+
+func NewRMSE[T mat.Float]() *Mean[T] {
+	return &Mean[T]{name: "rmse", batch: squaredErrorSum[T], finish: math.Sqrt}
+}
+
+func (m *Mean[T]) Name() string {
+	return m.name
+}
+
+func (m *Mean[T]) Reset() {
+	m.sum, m.n = 0, 0
+}
+
+func (m *Mean[T]) Update(y, y_ *mat.Mat2D[T]) error {
+	sum, n, err := m.batch(y, y_)
+	if err != nil {
+		return err
+	}
+	m.sum += sum
+	m.n += n
+	return nil
+}
+
+func (m *Mean[T]) Value() float64 {
+	mean := ratio(m.sum, m.n)
+	if m.finish != nil {
+		return m.finish(mean)
+	}
+	return mean
+}
+
+// Precision, recall or F1 from a Confusion accumulated over batches
+type ConfusionMetric[T mat.Float] struct {
+	name  string
+	score func(c *Confusion) (float64, error)
+
+	Confusion *Confusion // nil until the first Update
+}
+
+func NewPrecision[T mat.Float](avg Average) *ConfusionMetric[T] {
+	return &ConfusionMetric[T]{name: "precision_" + avg.String(), score: func(c *Confusion) (float64, error) {
+		return c.Precision(avg)
+	}}
+}
+
+func NewRecall[T mat.Float](avg Average) *ConfusionMetric[T] {
+	return &ConfusionMetric[T]{name: "recall_" + avg.String(), score: func(c *Confusion) (float64, error) {
+		return c.Recall(avg)
+	}}
+}
+
+func NewF1[T mat.Float](avg Average) *ConfusionMetric[T] {
+	return &ConfusionMetric[T]{name: "f1_" + avg.String(), score: func(c *Confusion) (float64, error) {
+		return c.F1(avg)
+	}}
+}
+
+func (m *ConfusionMetric[T]) Name() string {
+	return m.name
+}
+
+func (m *ConfusionMetric[T]) Reset() {
+	m.Confusion = nil
+}
+
+func (m *ConfusionMetric[T]) Update(y, y_ *mat.Mat2D[T]) error {
+	if err := matchDims(m.name, y, y_); err != nil {
+		return err
+	}
+	if m.Confusion == nil {
+		m.Confusion = NewConfusion(classCount(y))
+	}
+	if classCount(y) != m.Confusion.Classes() {
+		return fmt.Errorf(
+			"Failed to %s, reason { batch of %d classes after %d }", m.name, classCount(y), m.Confusion.Classes(),
+		)
+	}
+
+	m.Confusion.Add(Classes(y), Classes(y_))
+	return nil
+}
+
+// NaN before any sample or for an invalid average
+func (m *ConfusionMetric[T]) Value() float64 {
+	if m.Confusion == nil || m.Confusion.Total() == 0 {
+		return math.NaN()
+	}
+	score, err := m.score(m.Confusion)
+	if err != nil {
+		return math.NaN()
+	}
+	return score
+}
+
+// ROC AUC over batches, keeps every score since the ranking is global
+type AUCMetric[T mat.Float] struct {
+	labels []bool
+	scores []float64
+}
+
+func NewAUC[T mat.Float]() *AUCMetric[T] {
+	return &AUCMetric[T]{}
+}
+
+func (m *AUCMetric[T]) Name() string {
+	return "auc"
+}
+
+func (m *AUCMetric[T]) Reset() {
+	m.labels, m.scores = m.labels[:0], m.scores[:0]
+}
+
+func (m *AUCMetric[T]) Update(y, y_ *mat.Mat2D[T]) error {
+	if err := matchDims("AUC", y, y_); err != nil {
+		return err
+	}
+	if y.Rows() != 1 {
+		return fmt.Errorf("Failed to AUC, reason { expected binary targets y[1, N], found %d rows }", y.Rows())
+	}
+
+	for j := range y.Cols() {
+		m.labels = append(m.labels, y.MustGet(0, j) >= 0.5)
+		m.scores = append(m.scores, float64(y_.MustGet(0, j)))
+	}
+	return nil
+}
+
+// NaN until both classes were seen
+func (m *AUCMetric[T]) Value() float64 {
+	roc, err := rocCurve(m.labels, m.scores)
+	if err != nil {
+		return math.NaN()
+	}
+	return roc.AUC()
+}
+
+// R² over batches, from per output running means and sums of squared deviations (Welford)
+type R2Metric[T mat.Float] struct {
+	n     int
+	mean  []float64
+	m2    []float64 // sum (y - mean)^2, without the cancellation of sum y^2 - (sum y)^2 / n
+	ssRes []float64
+}
+
+func NewR2[T mat.Float]() *R2Metric[T] {
+	return &R2Metric[T]{}
+}
+
+func (m *R2Metric[T]) Name() string {
+	return "r2"
+}
+
+func (m *R2Metric[T]) Reset() {
+	*m = R2Metric[T]{}
+}
+
+func (m *R2Metric[T]) Update(y, y_ *mat.Mat2D[T]) error {
+	if err := matchDims("R2", y, y_); err != nil {
+		return err
+	}
+	if m.mean == nil {
+		m.mean = make([]float64, y.Rows())
+		m.m2 = make([]float64, y.Rows())
+		m.ssRes = make([]float64, y.Rows())
+	}
+	if int(y.Rows()) != len(m.mean) {
+		return fmt.Errorf("Failed to R2, reason { batch of %d outputs after %d }", y.Rows(), len(m.mean))
+	}
+
+	for j := range y.Cols() {
+		m.n++
+		for i := range y.Rows() {
+			Y := float64(y.MustGet(i, j))
+			diff := Y - float64(y_.MustGet(i, j))
+
+			delta := Y - m.mean[i]
+			m.mean[i] += delta / float64(m.n)
+			m.m2[i] += delta * (Y - m.mean[i])
+			m.ssRes[i] += diff * diff
+		}
+	}
+	return nil
+}
+
+func (m *R2Metric[T]) Value() float64 {
+	if m.n == 0 {
+		return math.NaN()
+	}
+
+	var sum float64
+	for i, ssTot := range m.m2 {
+		switch {
+		case ssTot > 0:
+			sum += 1 - m.ssRes[i]/ssTot
+		case m.ssRes[i] == 0:
+			sum += 1
+		}
+	}
+	return sum / float64(len(m.m2))
+}
@@ -0,0 +1,108 @@
+package optim
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* Adam
+*
+* g    = grad + WeightDecay * w                    (Adam, L2 penalty)
+* m    = Beta1 * m + (1 - Beta1) * g
+* v    = Beta2 * v + (1 - Beta2) * g^2
+* m^   = m / (1 - Beta1^t)
+* v^   = v / (1 - Beta2^t)
+* w    = w - Alpha * m^ / (sqrt(v^) + Eps)
+*
+* AdamW decouples the decay from the gradient instead:
+* w    = w - Alpha * (m^ / (sqrt(v^) + Eps) + WeightDecay * w)
+**/
+type Adam[T mat.Float] struct {
+	Alpha       T // learning rate
+	Beta1       T // decay of the first moment estimate
+	Beta2       T // decay of the second moment estimate
+	Eps         T
+	WeightDecay T
+
+	decoupled bool
+
+	m, v  map[layer.Learnable[T]]*mat.Mat2D[T]
+	steps map[layer.Learnable[T]]int
+}
+
+func NewAdam[T mat.Float](alpha T) *Adam[T] {
+	return &Adam[T]{
+		Alpha:       alpha,
+		Beta1:       0.9,
+		Beta2:       0.999,
+		Eps:         1e-8,
+		WeightDecay: 0,
+
+		decoupled: false,
+
+		m:     map[layer.Learnable[T]]*mat.Mat2D[T]{},
+		v:     map[layer.Learnable[T]]*mat.Mat2D[T]{},
+		steps: map[layer.Learnable[T]]int{},
+	}
+}
+
+func NewAdamW[T mat.Float](alpha, weightDecay T) *Adam[T] {
+	adam := NewAdam(alpha)
+	adam.WeightDecay = weightDecay
+	adam.decoupled = true
+	return adam
+}
+
+func (o *Adam[T]) LR() T {
+	return o.Alpha
+}
+
+func (o *Adam[T]) SetLR(lr T) {
+	o.Alpha = lr
+}
+
+func (o *Adam[T]) Step(params ...layer.Learnable[T]) error {
+	name := "Adam"
+	if o.decoupled {
+		name = "AdamW"
+	}
+	return step(name, params, o.update)
+}
+
+func (o *Adam[T]) update(param layer.Learnable[T], w, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	m := stateFor(o.m, param, w)
+	v := stateFor(o.v, param, w)
+
+	o.steps[param]++
+	t := float64(o.steps[param])
+
+	correction1 := T(1 - math.Pow(float64(o.Beta1), t))
+	correction2 := T(1 - math.Pow(float64(o.Beta2), t))
+
+	each(w, func(i, j int64) {
+		wij := w.MustGet(i, j)
+		g := grad.MustGet(i, j)
+		if !o.decoupled {
+			g += o.WeightDecay * wij
+		}
+
+		mij := o.Beta1*m.MustGet(i, j) + (1-o.Beta1)*g
+		vij := o.Beta2*v.MustGet(i, j) + (1-o.Beta2)*g*g
+		m.MustSet(i, j, mij)
+		v.MustSet(i, j, vij)
+
+		mHat := mij / correction1
+		vHat := vij / correction2
+
+		delta := mHat / (T(math.Sqrt(float64(vHat))) + o.Eps)
+		if o.decoupled {
+			delta += o.WeightDecay * wij
+		}
+
+		w.MustSet(i, j, wij-o.Alpha*delta)
+	})
+
+	return w, nil
+}
@@ -0,0 +1,95 @@
+package optim
+
+import (
+	"fmt"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+)
+
+/*
+* Optimizer
+*
+* Applies one update to every learnable param through Layer.Learn,
+* keeping any per-param state (momentum, moment estimates, ...) keyed by the param.
+* Containers (layer.Container) are flattened into their Params first, so every
+* child keeps its own state whether a model or its Params are passed.
+* Switching optimizers only changes the constructor, for example:
+*
+*	opt := optim.NewAdam[float32](0.001)
+*	err := opt.Step(model.Params()...)
+**/
+type Optimizer[T mat.Float] interface {
+	LRAdjustable[T]
+	Step(params ...layer.Learnable[T]) error
+}
+
+// Anything with a learning rate a scheduler can drive
+type LRAdjustable[T mat.Float] interface {
+	LR() T
+	SetLR(lr T)
+}
+
+// vvv PRIVATE vvv
+
+type updateFunc[T mat.Float] func(
+	param layer.Learnable[T],
+	weights, grad *mat.Mat2D[T],
+) (*mat.Mat2D[T], error)
+
+func step[T mat.Float](name string, params []layer.Learnable[T], update updateFunc[T]) error {
+	params = flatten(params)
+	for i, param := range params {
+		if learnable, _ := param.IsLearnable(); !learnable {
+			continue
+		}
+
+		updater := func(weights, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+			return update(param, weights, grad)
+		}
+
+		if err := param.Learn(&updater); err != nil {
+			return fmt.Errorf(
+				"Failed to %s::Step at param[%d of %d], reason { %s }",
+				name, i+1, len(params), err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// replaces every container by its children, recursively
+func flatten[T mat.Float](params []layer.Learnable[T]) []layer.Learnable[T] {
+	flat := make([]layer.Learnable[T], 0, len(params))
+	for _, param := range params {
+		if container, ok := param.(layer.Container[T]); ok {
+			flat = append(flat, flatten(container.Params())...)
+			continue
+		}
+		flat = append(flat, param)
+	}
+	return flat
+}
+
+// returns state[param], creating a zero matrix shaped like like if missing
+func stateFor[T mat.Float](
+	state map[layer.Learnable[T]]*mat.Mat2D[T],
+	param layer.Learnable[T],
+	like *mat.Mat2D[T],
+) *mat.Mat2D[T] {
+	s, ok := state[param]
+	if !ok || !mat.DimsMatch(s, like) {
+		s = mat.New2D[T](uint64(like.Rows()), uint64(like.Cols()))
+		state[param] = s
+	}
+	return s
+}
+
+// calls f(i, j) for every element of m
+func each[T mat.Float](m *mat.Mat2D[T], f func(i, j int64)) {
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			f(i, j)
+		}
+	}
+}
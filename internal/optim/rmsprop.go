@@ -0,0 +1,62 @@
+package optim
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+)
+
+/*
+* RMSProp
+*
+* g = grad + WeightDecay * w
+* s = Rho * s + (1 - Rho) * g^2
+* w = w - Alpha * g / (sqrt(s) + Eps)
+**/
+type RMSProp[T mat.Float] struct {
+	Alpha       T // learning rate
+	Rho         T // decay of the squared gradient average
+	Eps         T
+	WeightDecay T // L2 penalty
+
+	sq map[layer.Learnable[T]]*mat.Mat2D[T]
+}
+
+func NewRMSProp[T mat.Float](alpha T) *RMSProp[T] {
+	return &RMSProp[T]{
+		Alpha:       alpha,
+		Rho:         0.9,
+		Eps:         1e-8,
+		WeightDecay: 0,
+
+		sq: map[layer.Learnable[T]]*mat.Mat2D[T]{},
+	}
+}
+
+func (o *RMSProp[T]) LR() T {
+	return o.Alpha
+}
+
+func (o *RMSProp[T]) SetLR(lr T) {
+	o.Alpha = lr
+}
+
+func (o *RMSProp[T]) Step(params ...layer.Learnable[T]) error {
+	return step("RMSProp", params, o.update)
+}
+
+func (o *RMSProp[T]) update(param layer.Learnable[T], w, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	s := stateFor(o.sq, param, w)
+
+	each(w, func(i, j int64) {
+		wij := w.MustGet(i, j)
+		g := grad.MustGet(i, j) + o.WeightDecay*wij
+
+		sij := o.Rho*s.MustGet(i, j) + (1-o.Rho)*g*g
+		s.MustSet(i, j, sij)
+
+		w.MustSet(i, j, wij-o.Alpha*g/(T(math.Sqrt(float64(sij)))+o.Eps))
+	})
+
+	return w, nil
+}
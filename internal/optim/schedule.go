@@ -0,0 +1,256 @@
+package optim
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+)
+
+// A Schedule maps a step count (batches or epochs, the caller decides) to a learning rate
+type Schedule interface {
+	At(step int) float64
+}
+
+/*
+* Scheduler
+*
+* Drives the learning rate of an optimizer from a Schedule,
+* call Step once per step after the optimizer has stepped.
+**/
+type Scheduler[T mat.Float] struct {
+	opt      LRAdjustable[T]
+	schedule Schedule
+	step     int
+}
+
+// sets the optimizer to the rate of step 0 right away
+func NewScheduler[T mat.Float](opt LRAdjustable[T], schedule Schedule) *Scheduler[T] {
+	s := &Scheduler[T]{
+		opt:      opt,
+		schedule: schedule,
+		step:     0,
+	}
+	opt.SetLR(T(schedule.At(0)))
+	return s
+}
+
+func (s *Scheduler[T]) Step() T {
+	s.step++
+	lr := T(s.schedule.At(s.step))
+	s.opt.SetLR(lr)
+	return lr
+}
+
+func (s *Scheduler[T]) StepCount() int {
+	return s.step
+}
+
+/*
+* Step Decay
+*
+* lr = Base * Gamma^floor(step / StepSize)
+**/
+type StepDecay struct {
+	Base     float64
+	Gamma    float64
+	StepSize int
+}
+
+func NewStepDecay(base, gamma float64, stepSize int) *StepDecay {
+	return &StepDecay{Base: base, Gamma: gamma, StepSize: max(stepSize, 1)}
+}
+
+func (s *StepDecay) At(step int) float64 {
+	return s.Base * math.Pow(s.Gamma, float64(step/s.StepSize))
+}
+
+/*
+* Exponential Decay
+*
+* lr = Base * Gamma^step
+**/
+type ExponentialDecay struct {
+	Base  float64
+	Gamma float64
+}
+
+func NewExponentialDecay(base, gamma float64) *ExponentialDecay {
+	return &ExponentialDecay{Base: base, Gamma: gamma}
+}
+
+func (s *ExponentialDecay) At(step int) float64 {
+	return s.Base * math.Pow(s.Gamma, float64(step))
+}
+
+/*
+* Cosine Annealing with warm restarts (SGDR)
+*
+* Within a cycle of length Ti, at t steps into the cycle:
+* lr = Min + (Base - Min) * (1 + cos(pi * t / Ti)) / 2
+*
+* The first cycle lasts Period steps, each following cycle is PeriodMult times
+* longer than the last. A PeriodMult of 1 restarts every Period steps.
+**/
+type CosineAnnealing struct {
+	Base       float64
+	Min        float64
+	Period     int
+	PeriodMult int
+}
+
+func NewCosineAnnealing(base, minLR float64, period, periodMult int) *CosineAnnealing {
+	return &CosineAnnealing{
+		Base:       base,
+		Min:        minLR,
+		Period:     max(period, 1),
+		PeriodMult: max(periodMult, 1),
+	}
+}
+
+func (s *CosineAnnealing) At(step int) float64 {
+	t, Ti := step, s.Period
+	if s.PeriodMult == 1 {
+		t = step % Ti
+	} else {
+		for t >= Ti {
+			t -= Ti
+			Ti *= s.PeriodMult
+		}
+	}
+
+	return cosineAnneal(s.Base, s.Min, float64(t)/float64(Ti))
+}
+
+/*
+* Linear Warmup
+*
+* Ramps linearly from Start to After.At(0) over the first Warmup steps,
+* then follows After (shifted so After starts at step 0).
+**/
+type LinearWarmup struct {
+	Warmup int
+	Start  float64
+	After  Schedule
+}
+
+func NewLinearWarmup(warmup int, start float64, after Schedule) *LinearWarmup {
+	return &LinearWarmup{Warmup: warmup, Start: start, After: after}
+}
+
+func (s *LinearWarmup) At(step int) float64 {
+	if step >= s.Warmup {
+		return s.After.At(step - s.Warmup)
+	}
+	pct := float64(step) / float64(s.Warmup)
+	return s.Start + (s.After.At(0)-s.Start)*pct
+}
+
+/*
+* One Cycle
+*
+* Anneals (cosine) from Max / DivFactor up to Max over the first
+* PctStart * Total steps, then down to Max / (DivFactor * FinalDivFactor)
+* by step Total, staying there afterwards.
+**/
+type OneCycle struct {
+	Max            float64
+	Total          int
+	PctStart       float64
+	DivFactor      float64
+	FinalDivFactor float64
+}
+
+// uses the common defaults PctStart = 0.3, DivFactor = 25, FinalDivFactor = 1e4
+func NewOneCycle(maxLR float64, total int) *OneCycle {
+	return &OneCycle{
+		Max:            maxLR,
+		Total:          total,
+		PctStart:       0.3,
+		DivFactor:      25,
+		FinalDivFactor: 1e4,
+	}
+}
+
+func (s *OneCycle) At(step int) float64 {
+	initial := s.Max / s.DivFactor
+	final := initial / s.FinalDivFactor
+
+	up := int(s.PctStart * float64(s.Total))
+	if step < up {
+		return cosineAnneal(initial, s.Max, float64(step)/float64(up))
+	}
+
+	down := s.Total - up
+	if down <= 0 || step >= s.Total {
+		return final
+	}
+	return cosineAnneal(s.Max, final, float64(step-up)/float64(down))
+}
+
+/*
+* Reduce On Plateau
+*
+* Driven by a monitored loss instead of the step count: when the loss has not
+* improved on the best seen by more than Threshold * |best| for Patience
+* consecutive steps, the learning rate is multiplied by Factor (never below Min).
+* Taking |best| keeps the threshold meaningful for negative losses.
+**/
+type ReduceOnPlateau[T mat.Float] struct {
+	Factor    float64
+	Patience  int
+	Threshold float64
+	Min       float64
+
+	opt  LRAdjustable[T]
+	best float64
+	bad  int
+}
+
+func NewReduceOnPlateau[T mat.Float](opt LRAdjustable[T], factor float64, patience int) (*ReduceOnPlateau[T], error) {
+	if !(0 < factor && factor < 1) {
+		return nil, fmt.Errorf(
+			"Failed to create ReduceOnPlateau, reason { factor %f must be in (0, 1) }",
+			factor,
+		)
+	}
+
+	return &ReduceOnPlateau[T]{
+		Factor:    factor,
+		Patience:  patience,
+		Threshold: 1e-4,
+		Min:       0,
+
+		opt:  opt,
+		best: math.Inf(1),
+		bad:  0,
+	}, nil
+}
+
+// records loss, returns true if the learning rate was reduced
+func (s *ReduceOnPlateau[T]) Step(loss float64) bool {
+	if math.IsInf(s.best, 1) || loss < s.best-s.Threshold*math.Abs(s.best) {
+		s.best = loss
+		s.bad = 0
+		return false
+	}
+
+	s.bad++
+	if s.bad <= s.Patience {
+		return false
+	}
+
+	s.bad = 0
+	lr := max(float64(s.opt.LR())*s.Factor, s.Min)
+	if lr >= float64(s.opt.LR()) {
+		return false
+	}
+	s.opt.SetLR(T(lr))
+	return true
+}
+
+// vvv PRIVATE vvv
+
+// cosine interpolation from start (pct = 0) to end (pct = 1)
+func cosineAnneal(start, end, pct float64) float64 {
+	return end + (start-end)*(1+math.Cos(math.Pi*pct))/2
+}
@@ -0,0 +1,87 @@
+package optim
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+)
+
+/*
+* Stochastic Gradient Descent
+*
+* g = grad + WeightDecay * w
+* v = Momentum * v + g
+* w = w - Alpha * v                       (classic momentum)
+* w = w - Alpha * (g + Momentum * v)      (Nesterov)
+*
+* With Momentum == 0 this is plain SGD.
+**/
+type SGD[T mat.Float] struct {
+	Alpha       T // learning rate
+	Momentum    T
+	Nesterov    bool
+	WeightDecay T // L2 penalty
+
+	velocity map[layer.Learnable[T]]*mat.Mat2D[T]
+}
+
+func NewSGD[T mat.Float](alpha T) *SGD[T] {
+	return &SGD[T]{
+		Alpha:       alpha,
+		Momentum:    0,
+		Nesterov:    false,
+		WeightDecay: 0,
+
+		velocity: map[layer.Learnable[T]]*mat.Mat2D[T]{},
+	}
+}
+
+func NewMomentum[T mat.Float](alpha, momentum T) *SGD[T] {
+	sgd := NewSGD(alpha)
+	sgd.Momentum = momentum
+	return sgd
+}
+
+func NewNesterov[T mat.Float](alpha, momentum T) *SGD[T] {
+	sgd := NewMomentum(alpha, momentum)
+	sgd.Nesterov = true
+	return sgd
+}
+
+func (o *SGD[T]) LR() T {
+	return o.Alpha
+}
+
+func (o *SGD[T]) SetLR(lr T) {
+	o.Alpha = lr
+}
+
+func (o *SGD[T]) Step(params ...layer.Learnable[T]) error {
+	return step("SGD", params, o.update)
+}
+
+func (o *SGD[T]) update(param layer.Learnable[T], w, grad *mat.Mat2D[T]) (*mat.Mat2D[T], error) {
+	var v *mat.Mat2D[T]
+	if o.Momentum != 0 {
+		v = stateFor(o.velocity, param, w)
+	}
+
+	each(w, func(i, j int64) {
+		wij := w.MustGet(i, j)
+		g := grad.MustGet(i, j) + o.WeightDecay*wij
+
+		if v != nil {
+			vij := o.Momentum*v.MustGet(i, j) + g
+			v.MustSet(i, j, vij)
+
+			if o.Nesterov {
+				g = g + o.Momentum*vij
+			} else {
+				g = vij
+			}
+		}
+
+		w.MustSet(i, j, wij-o.Alpha*g)
+	})
+
+	return w, nil
+}
@@ -0,0 +1,103 @@
+package train
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Values logged for a batch or epoch: "loss", every metric by name, and "val_" prefixed validation values
+type Logs map[string]float64
+
+// The Logs of every completed epoch
+type History []Logs
+
+// the value of key in every epoch that logged it
+func (h History) Series(key string) []float64 {
+	series := make([]float64, 0, len(h))
+	for _, logs := range h {
+		if v, ok := logs[key]; ok {
+			series = append(series, v)
+		}
+	}
+	return series
+}
+
+// Returned by a callback to end Fit early without an error (e.g. early stopping)
+var ErrStopTraining = errors.New("stop training")
+
+/*
+* Callback
+*
+* Hooks into Trainer.Fit, epochs and batches are numbered from 0. A non nil
+* error aborts Fit and is returned from it, except ErrStopTraining which ends
+* Fit after the current batch or epoch with the history so far.
+**/
+type Callback interface {
+	OnEpochStart(epoch int) error
+	OnEpochEnd(epoch int, logs Logs) error
+	OnBatchStart(epoch, batch int) error
+	OnBatchEnd(epoch, batch int, logs Logs) error
+}
+
+// A Callback from plain funcs, nil funcs are skipped
+type CallbackFuncs struct {
+	EpochStart func(epoch int) error
+	EpochEnd   func(epoch int, logs Logs) error
+	BatchStart func(epoch, batch int) error
+	BatchEnd   func(epoch, batch int, logs Logs) error
+}
+
+func (c CallbackFuncs) OnEpochStart(epoch int) error {
+	if c.EpochStart == nil {
+		return nil
+	}
+	return c.EpochStart(epoch)
+}
+
+func (c CallbackFuncs) OnEpochEnd(epoch int, logs Logs) error {
+	if c.EpochEnd == nil {
+		return nil
+	}
+	return c.EpochEnd(epoch, logs)
+}
+
+func (c CallbackFuncs) OnBatchStart(epoch, batch int) error {
+	if c.BatchStart == nil {
+		return nil
+	}
+	return c.BatchStart(epoch, batch)
+}
+
+func (c CallbackFuncs) OnBatchEnd(epoch, batch int, logs Logs) error {
+	if c.BatchEnd == nil {
+		return nil
+	}
+	return c.BatchEnd(epoch, batch, logs)
+}
+
+// Prints the logs of every epoch to w, keys sorted, e.g. "Epoch[3] loss: 0.120000, val_loss: 0.150000"
+func PrintEpochs(w io.Writer) Callback {
+	return CallbackFuncs{
+		EpochEnd: func(epoch int, logs Logs) error {
+			_, err := fmt.Fprintf(w, "Epoch[%d] %s\n", epoch, logs)
+			return err
+		},
+	}
+}
+
+func (logs Logs) String() string {
+	keys := make([]string, 0, len(logs))
+	for k := range logs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s: %f", k, logs[k])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,337 @@
+package train
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonn/internal/data"
+	"gonn/internal/layer"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/optim"
+	"math/rand"
+)
+
+/*
+* Metric
+*
+* Accumulates a score over the batches of an epoch, Update gets the targets
+* y and the model output y_ of each batch and Value the score over all of
+* them since the last Reset.
+**/
+type Metric[T mat.Float] interface {
+	Name() string
+	Reset()
+	Update(y, y_ *mat.Mat2D[T]) error
+	Value() float64
+}
+
+/*
+* Trainer
+*
+* Fits Model to a Dataset with mini-batch gradient descent:
+*
+*	y_   = Model.Forward(X)
+*	loss = sum(Loss.F(Y, y_)) / N
+*	Model.Backward(Loss.DF(Y, y_) / N)
+*	Optimizer.Step(params...)
+*
+* Each epoch reports the mean training loss and Metrics in its Logs, plus
+* "val_" prefixed ones over Validation, or a random ValidationSplit fraction
+* held out of the training data. Models with a mode (layer.ModeSetter) are
+* switched to inference while validating. Shuffling and the split draw from
+* RNG, nil for mat.DefaultRNG.
+*
+*	trainer, err := train.NewTrainer(model, lossfuncs.SoftmaxCrossEntropyLoss[float32](), optim.NewAdam[float32](0.001))
+*	trainer.ValidationSplit = 0.1
+*	history, err := trainer.Fit(ctx, ds, 10)
+**/
+type Trainer[T mat.Float] struct {
+	Model     layer.Layer[T]
+	Loss      lossfuncs.Loss[T]
+	Optimizer optim.Optimizer[T]
+	Metrics   []Metric[T]
+	Callbacks []Callback
+
+	BatchSize int
+	Shuffle   bool
+	Prefetch  int
+	RNG       *rand.Rand
+
+	ValidationSplit float64
+	Validation      data.Dataset[T] // takes precedence over ValidationSplit
+}
+
+func NewTrainer[T mat.Float](
+	model layer.Layer[T], loss lossfuncs.Loss[T], optimizer optim.Optimizer[T],
+) (*Trainer[T], error) {
+	if model == nil || optimizer == nil || loss.F == nil || loss.DF == nil {
+		return nil, fmt.Errorf("Failed to create Trainer, reason { need a model, a loss with its derivative and an optimizer }")
+	}
+
+	return &Trainer[T]{
+		Model:     model,
+		Loss:      loss,
+		Optimizer: optimizer,
+		Metrics:   nil,
+		Callbacks: nil,
+
+		BatchSize: 32,
+		Shuffle:   true,
+		Prefetch:  0,
+		RNG:       nil,
+
+		ValidationSplit: 0,
+		Validation:      nil,
+	}, nil
+}
+
+/*
+* Trains for epochs passes over ds, returning the Logs of every completed
+* epoch. Cancelling ctx stops before the next batch and returns ctx.Err()
+* with the history so far, as does a callback returning ErrStopTraining but
+* with a nil error.
+**/
+func (t *Trainer[T]) Fit(ctx context.Context, ds data.Dataset[T], epochs int) (History, error) {
+	trainSet, valSet, err := t.split(ds)
+	if err != nil {
+		return nil, t.wrapErr("Fit", err)
+	}
+
+	loader, err := t.loader(trainSet, t.Shuffle)
+	if err != nil {
+		return nil, t.wrapErr("Fit", err)
+	}
+
+	if ms, ok := t.Model.(layer.ModeSetter); ok {
+		defer ms.SetTraining(ms.IsTraining())
+	}
+
+	history := History{}
+	for epoch := range epochs {
+		logs, err := t.epoch(ctx, loader, epoch)
+		if err == nil && valSet != nil {
+			err = t.validate(ctx, valSet, logs)
+		}
+		if err == nil {
+			history = append(history, logs)
+			err = t.each(func(c Callback) error { return c.OnEpochEnd(epoch, logs) })
+		}
+
+		if errors.Is(err, ErrStopTraining) {
+			return history, nil
+		}
+		if err != nil {
+			return history, err
+		}
+	}
+
+	return history, nil
+}
+
+// The mean loss and Metrics of the model over ds, in inference mode
+func (t *Trainer[T]) Evaluate(ctx context.Context, ds data.Dataset[T]) (Logs, error) {
+	loader, err := t.loader(ds, false)
+	if err != nil {
+		return nil, t.wrapErr("Evaluate", err)
+	}
+
+	if ms, ok := t.Model.(layer.ModeSetter); ok {
+		defer ms.SetTraining(ms.IsTraining())
+		ms.SetTraining(false)
+	}
+	t.resetMetrics()
+
+	it := loader.Epoch()
+	defer it.Close()
+
+	var total float64
+	n := 0
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		X, Y := it.Batch()
+		out, err := t.Model.Forward(X)
+		if err != nil {
+			return nil, t.wrapErr("Evaluate", err)
+		}
+		loss, err := t.Loss.F(Y, out)
+		if err != nil {
+			return nil, t.wrapErr("Evaluate", err)
+		}
+		if err := t.updateMetrics(Y, out); err != nil {
+			return nil, t.wrapErr("Evaluate", err)
+		}
+
+		total += float64(loss.Sum())
+		n += int(X.Cols())
+	}
+	if err := it.Err(); err != nil {
+		return nil, t.wrapErr("Evaluate", err)
+	}
+
+	return t.logs(total / float64(n)), nil
+}
+
+// the learnable params the optimizer steps, the children of containers
+func (t *Trainer[T]) Params() []layer.Learnable[T] {
+	if c, ok := t.Model.(layer.Container[T]); ok {
+		return c.Params()
+	}
+	return []layer.Learnable[T]{t.Model}
+}
+
+// ## private ##
+
+func (t *Trainer[T]) epoch(ctx context.Context, loader *data.DataLoader[T], epoch int) (Logs, error) {
+	if err := t.each(func(c Callback) error { return c.OnEpochStart(epoch) }); err != nil {
+		return nil, err
+	}
+
+	if ms, ok := t.Model.(layer.ModeSetter); ok {
+		ms.SetTraining(true)
+	}
+	t.resetMetrics()
+
+	it := loader.Epoch()
+	defer it.Close()
+
+	var total float64
+	n := 0
+	for batch := 0; it.Next(); batch++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := t.each(func(c Callback) error { return c.OnBatchStart(epoch, batch) }); err != nil {
+			return nil, err
+		}
+
+		X, Y := it.Batch()
+		loss, err := t.step(X, Y)
+		if err != nil {
+			return nil, t.wrapErr("Fit", fmt.Errorf("epoch %d batch %d: %s", epoch, batch, err))
+		}
+		total += loss * float64(X.Cols())
+		n += int(X.Cols())
+
+		batchLogs := Logs{"loss": loss}
+		if err := t.each(func(c Callback) error { return c.OnBatchEnd(epoch, batch, batchLogs) }); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, t.wrapErr("Fit", err)
+	}
+
+	return t.logs(total / float64(n)), nil
+}
+
+// one gradient descent step on a batch, returning its mean loss
+func (t *Trainer[T]) step(X, Y *mat.Mat2D[T]) (float64, error) {
+	out, err := t.Model.Forward(X)
+	if err != nil {
+		return 0, err
+	}
+
+	loss, err := t.Loss.F(Y, out)
+	if err != nil {
+		return 0, err
+	}
+	dLoss, err := t.Loss.DF(Y, out)
+	if err != nil {
+		return 0, err
+	}
+
+	scale := 1 / T(X.Cols())
+	if _, err := t.Model.Backward(dLoss.Scale(scale)); err != nil {
+		return 0, err
+	}
+	if err := t.Optimizer.Step(t.Params()...); err != nil {
+		return 0, err
+	}
+	if err := t.updateMetrics(Y, out); err != nil {
+		return 0, err
+	}
+
+	return float64(loss.Sum() * scale), nil
+}
+
+// adds the "val_" prefixed Evaluate logs of valSet to logs
+func (t *Trainer[T]) validate(ctx context.Context, valSet data.Dataset[T], logs Logs) error {
+	valLogs, err := t.Evaluate(ctx, valSet)
+	if err != nil {
+		return err
+	}
+	for k, v := range valLogs {
+		logs["val_"+k] = v
+	}
+	return nil
+}
+
+func (t *Trainer[T]) split(ds data.Dataset[T]) (trainSet, valSet data.Dataset[T], err error) {
+	if ds == nil {
+		return nil, nil, fmt.Errorf("nil Dataset")
+	}
+	if t.Validation != nil || t.ValidationSplit == 0 {
+		return ds, t.Validation, nil
+	}
+
+	trainSub, valSub, err := data.Split(ds, t.ValidationSplit, t.RNG)
+	if err != nil {
+		return nil, nil, err
+	}
+	return trainSub, valSub, nil
+}
+
+func (t *Trainer[T]) loader(ds data.Dataset[T], shuffle bool) (*data.DataLoader[T], error) {
+	if ds.Len() == 0 {
+		return nil, fmt.Errorf("empty Dataset")
+	}
+
+	loader, err := data.NewDataLoader(ds, t.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	loader.Shuffle = shuffle
+	loader.Prefetch = t.Prefetch
+	loader.RNG = t.RNG
+	return loader, nil
+}
+
+func (t *Trainer[T]) each(f func(c Callback) error) error {
+	for _, c := range t.Callbacks {
+		if err := f(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Trainer[T]) resetMetrics() {
+	for _, m := range t.Metrics {
+		m.Reset()
+	}
+}
+
+func (t *Trainer[T]) updateMetrics(y, y_ *mat.Mat2D[T]) error {
+	for _, m := range t.Metrics {
+		if err := m.Update(y, y_); err != nil {
+			return fmt.Errorf("metric %s: %s", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (t *Trainer[T]) logs(loss float64) Logs {
+	logs := Logs{"loss": loss}
+	for _, m := range t.Metrics {
+		logs[m.Name()] = m.Value()
+	}
+	return logs
+}
+
+func (t *Trainer[T]) wrapErr(method string, err error) error {
+	return fmt.Errorf("Failed to Trainer::%s, reason { %s }", method, err)
+}
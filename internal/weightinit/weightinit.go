@@ -0,0 +1,161 @@
+package weightinit
+
+import (
+	"gonn/internal/mat"
+	"math"
+	"math/rand"
+)
+
+/*
+* Initializer
+*
+* Fills a weight matrix W in place with numbers from rng (nil for
+* mat.DefaultRNG), scaled by the fans of the layer it belongs to. Layers keep
+* their bias in column 0 of W, so they hand the weight columns and the bias
+* column to (possibly different) initializers as separate views, both with
+* the fans of the weights, see layer.Initializable.
+**/
+type Initializer[T mat.Float] func(w *mat.Mat2D[T], fans Fans, rng *rand.Rand)
+
+/*
+* Fans
+*
+* The inputs (In) and outputs (Out) each weight connects. For a dense
+* W[fanOut, fanIn] they are its shape (FansOf), weights shared over a
+* receptive field count it on both sides, e.g. a convolution's
+* W[OC, C*KH*KW] has In = C*KH*KW and Out = OC*KH*KW.
+**/
+type Fans struct {
+	In, Out float64
+}
+
+func FansOf[T mat.Float](w *mat.Mat2D[T]) Fans {
+	return Fans{In: float64(max(w.Cols(), 1)), Out: float64(max(w.Rows(), 1))}
+}
+
+// the fans of weights shared over a receptive field of the given size
+func (f Fans) Receptive(field int64) Fans {
+	return Fans{In: f.In, Out: f.Out * float64(max(field, 1))}
+}
+
+// Xavier / Glorot: U(-a, a), a = sqrt(6 / (fanIn + fanOut))
+func XavierUniform[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	uniform(w, rng, math.Sqrt(6/(fans.In+fans.Out)))
+}
+
+// Xavier / Glorot: N(0, std), std = sqrt(2 / (fanIn + fanOut))
+func XavierNormal[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	normal(w, rng, math.Sqrt(2/(fans.In+fans.Out)))
+}
+
+// He / Kaiming, for ReLU: U(-a, a), a = sqrt(6 / fanIn)
+func HeUniform[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	uniform(w, rng, math.Sqrt(6/fans.In))
+}
+
+// He / Kaiming, for ReLU: N(0, std), std = sqrt(2 / fanIn)
+func HeNormal[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	normal(w, rng, math.Sqrt(2/fans.In))
+}
+
+// LeCun: U(-a, a), a = sqrt(3 / fanIn)
+func LeCunUniform[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	uniform(w, rng, math.Sqrt(3/fans.In))
+}
+
+// LeCun: N(0, std), std = sqrt(1 / fanIn)
+func LeCunNormal[T mat.Float](w *mat.Mat2D[T], fans Fans, rng *rand.Rand) {
+	normal(w, rng, math.Sqrt(1/fans.In))
+}
+
+/*
+* Orthogonal
+*
+* gain * a matrix with orthonormal rows (or columns, whichever are fewer),
+* from Gram-Schmidt on a Gaussian matrix (Saxe et al. 2013).
+**/
+func Orthogonal[T mat.Float](gain T) Initializer[T] {
+	return func(w *mat.Mat2D[T], _ Fans, rng *rand.Rand) {
+		rows, cols := w.Rows(), w.Cols()
+		if rows == 0 || cols == 0 {
+			return
+		}
+		if rng == nil {
+			rng = mat.DefaultRNG()
+		}
+
+		// orthonormalize the short side: k vectors of length n
+		k, n := min(rows, cols), max(rows, cols)
+		vecs := make([][]float64, k)
+		for i := range vecs {
+			vecs[i] = gaussianVector(rng, n)
+			for {
+				for _, prev := range vecs[:i] {
+					d := dot(vecs[i], prev)
+					for x := range vecs[i] {
+						vecs[i][x] -= d * prev[x]
+					}
+				}
+				if norm := math.Sqrt(dot(vecs[i], vecs[i])); norm > 1e-6 {
+					for x := range vecs[i] {
+						vecs[i][x] /= norm
+					}
+					break
+				}
+				// degenerate draw, start this vector over
+				vecs[i] = gaussianVector(rng, n)
+			}
+		}
+
+		for i := range rows {
+			for j := range cols {
+				if rows <= cols {
+					w.MustSet(i, j, gain*T(vecs[i][j]))
+				} else {
+					w.MustSet(i, j, gain*T(vecs[j][i]))
+				}
+			}
+		}
+	}
+}
+
+func Zeros[T mat.Float](w *mat.Mat2D[T], _ Fans, _ *rand.Rand) {
+	w.Fill(0)
+}
+
+func Constant[T mat.Float](c T) Initializer[T] {
+	return func(w *mat.Mat2D[T], _ Fans, _ *rand.Rand) {
+		w.Fill(c)
+	}
+}
+
+// U[0, 1), what mat.Rand gives and the layers used before initializers
+func Rand[T mat.Float](w *mat.Mat2D[T], _ Fans, rng *rand.Rand) {
+	w.MustCopy(mat.RandWith[T](rng, uint64(w.Rows()), uint64(w.Cols())))
+}
+
+// ## private ##
+
+func uniform[T mat.Float](w *mat.Mat2D[T], rng *rand.Rand, limit float64) {
+	w.MustCopy(mat.Uniform(rng, uint64(w.Rows()), uint64(w.Cols()), T(-limit), T(limit)))
+}
+
+func normal[T mat.Float](w *mat.Mat2D[T], rng *rand.Rand, std float64) {
+	w.MustCopy(mat.Normal(rng, uint64(w.Rows()), uint64(w.Cols()), 0, T(std)))
+}
+
+func gaussianVector(rng *rand.Rand, n int64) []float64 {
+	v := make([]float64, n)
+	for i := range v {
+		v[i] = rng.NormFloat64()
+	}
+	return v
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -6,5 +6,6 @@ import (
 
 func main() {
 	// demos.MatDemo()
+	// demos.MNISTDemo() // needs the MNIST files in data/mnist, see demos.MNISTImages
 	demos.PerceptronDemo()
 }
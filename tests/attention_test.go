@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"gonn/internal/gradcheck"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func TestScaledDotProductAttention(t *testing.T) {
+	// two keys, the query matches the first one
+	q := mat.FromValues([]float64{1, 0}).TP()
+	k := mat.FromValues([]float64{1, 0, 0, 1}).MustReshape(2, 2)
+	v := mat.FromValues([]float64{10, 20}) // [1, 2]
+
+	out, weights, err := layer.ScaledDotProductAttention(q, k, v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := math.Exp(1 / math.Sqrt(2))
+	w0, w1 := e/(e+1), 1/(e+1)
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{w0, w1}).TP(), weights, 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{10*w0 + 20*w1}), out, 1e-12))
+
+	// masking the first key leaves all the weight on the second
+	out, _, err = layer.ScaledDotProductAttention(q, k, v, layer.Mask(func(q, k int) bool { return k == 1 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{20}), out, 1e-12))
+
+	if _, _, err := layer.ScaledDotProductAttention(q, k.TP().MustSlice(mat.RS{0, 1}, mat.CS{0, 2}), v, nil); err == nil {
+		t.Error("Expected error for mismatched q and k")
+	}
+}
+
+func TestCausalAttentionIgnoresFuture(t *testing.T) {
+	const D, steps = 4, 3
+
+	mha, err := layer.NewMultiHeadAttention[float64](D, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mha.Causal = true
+
+	x := mat.RandF64(steps*D, 1)
+	before, err := mha.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before = before.Clone()
+
+	// change the last token, earlier outputs must not move
+	x.MustSlice(mat.RS{(steps - 1) * D, steps * D}, mat.CS{0, 1}).Fill(5)
+	after, err := mha.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := mat.RS{0, (steps - 1) * D}
+	logIfErr(t, expectMatNear(
+		before.MustSlice(prefix, mat.CS{0, 1}), after.MustSlice(prefix, mat.CS{0, 1}), 1e-12,
+	))
+}
+
+func TestPaddingMaskIgnoresPadding(t *testing.T) {
+	const D, steps = 4, 3
+
+	mha, err := layer.NewMultiHeadAttention[float64](D, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mha.Lengths = []int{2, 3}
+
+	x := mat.RandF64(steps*D, 2)
+	before, err := mha.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before = before.Clone()
+
+	// the padding token of the first sample must not change its real tokens
+	x.MustSlice(mat.RS{2 * D, 3 * D}, mat.CS{0, 1}).Fill(-3)
+	after, err := mha.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(
+		before.MustSlice(mat.RS{0, 2 * D}, mat.CS{0, 1}), after.MustSlice(mat.RS{0, 2 * D}, mat.CS{0, 1}), 1e-12,
+	))
+
+	mha.Lengths = []int{2}
+	if _, err := mha.Forward(x); err == nil {
+		t.Error("Expected error for Lengths not matching the batch")
+	}
+}
+
+func TestAttentionGradients(t *testing.T) {
+	const D, steps, N = 4, 3, 2
+
+	mha, err := layer.NewMultiHeadAttention[float64](D, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mha.Causal = true
+	mha.Lengths = []int{3, 2}
+	gradcheck.Expect(t, mha, mat.RandF64(steps*D, N))
+
+	ln := layer.NewLayerNorm[float64](5)
+	ln.W.Apply(func(w float64) float64 { return w + 0.3 })
+	gradcheck.Expect(t, ln, mat.RandF64(5, 3))
+
+	pe := layer.NewLearnedPE[float64](D, 4)
+	gradcheck.Expect(t, pe, mat.RandF64(steps*D, N))
+	gradcheck.Expect(t, layer.NewSinusoidalPE[float64](D), mat.RandF64(steps*D, N))
+}
+
+func TestTransformerEncoderBlockGradients(t *testing.T) {
+	const D, steps, N = 4, 3, 2
+
+	block, err := layer.NewTransformerEncoderBlock[float64](D, 2, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Params()) != 5 {
+		t.Errorf("Expected 5 learnable sublayers, found %d", len(block.Params()))
+	}
+
+	x := mat.RandF64(steps*D, N)
+	gradcheck.Expect(t, block, x)
+}
+
+func TestLayerNormNormalizes(t *testing.T) {
+	ln := layer.NewLayerNorm[float64](4)
+	out, err := ln.Forward(mat.FromValues([]float64{1, 2, 3, 4, -1, 0, 1, 6}).MustReshape(2, 4).TP())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for j := range out.Cols() {
+		mean, sq := 0.0, 0.0
+		for i := range out.Rows() {
+			mean += out.MustGet(i, j) / 4
+			sq += out.MustGet(i, j) * out.MustGet(i, j) / 4
+		}
+		logIfErr(t, expectNear(0, mean, 1e-9))
+		logIfErr(t, expectNear(1, sq, 1e-4))
+	}
+}
+
+func TestTransformerSaveLoad(t *testing.T) {
+	block, err := layer.NewTransformerEncoderBlock[float64](4, 2, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Attention.Causal = true
+	model := layer.NewSequential[float64](
+		layer.NewLearnedPE[float64](4, 5),
+		layer.NewSinusoidalPE[float64](4),
+		block,
+	)
+
+	x := mat.RandF64(3*4, 2)
+	expected, err := model.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := layer.Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := layer.Load[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := loaded[0].Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(expected, found))
+}
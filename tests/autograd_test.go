@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/autograd"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func TestAutogradMatMulSum(t *testing.T) {
+	W := autograd.NewVar(mat.ARange[float64](6).MustReshape(2, 3))
+	X := autograd.NewConst(mat.ARange[float64](6).MustReshape(3, 2))
+
+	WX, err := autograd.MatMul(W, X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	L := autograd.Sum(WX)
+
+	if err := L.Backward(); err != nil {
+		t.Fatal(err)
+	}
+
+	// dL/dW = ones[2, 2] * X^T
+	expected, err := mat.MatMul(mat.Ones[float64](2, 2), X.Value.TP())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logIfErr(t, expectMatEq(expected, W.Grad))
+
+	if X.Grad != nil {
+		t.Error("Expected constant to have no gradient")
+	}
+}
+
+func TestAutogradAccumulates(t *testing.T) {
+	x := autograd.NewVar(mat.Ones[float64](2, 2))
+
+	// L = sum(x + x) -> dL/dx = 2
+	xx, err := autograd.Add(x, x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := autograd.Sum(xx).Backward(); err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.Ones[float64](2, 2).Scale(2), x.Grad))
+
+	// second pass accumulates
+	if err := autograd.Sum(xx).Backward(); err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.Ones[float64](2, 2).Scale(4), x.Grad))
+
+	x.ZeroGrad()
+	if x.Grad != nil {
+		t.Error("Expected ZeroGrad to clear gradient")
+	}
+}
+
+func TestAutogradSliceVCat(t *testing.T) {
+	a := autograd.NewVar(mat.ARange[float64](6).MustReshape(2, 3))
+	b := autograd.NewVar(mat.Ones[float64](1, 3))
+
+	ab, err := autograd.VCat(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := autograd.Slice(ab, mat.RS{1, 3}, mat.CS{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := autograd.Sum(s).Backward(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedA := mat.FromValues([]float64{
+		0, 0, 0,
+		0, 1, 1,
+	}).MustReshape(2, 3)
+	expectedB := mat.FromValues([]float64{
+		0, 1, 1,
+	})
+
+	logIfErr(t, expectMatEq(expectedA, a.Grad))
+	logIfErr(t, expectMatEq(expectedB, b.Grad))
+}
+
+func TestAutogradBackwardRequiresScalar(t *testing.T) {
+	x := autograd.NewVar(mat.Ones[float64](2, 2))
+	if err := autograd.TP(x).Backward(); err == nil {
+		t.Error("Expected error calling Backward on non scalar variable")
+	}
+}
+
+// compares autograd against central finite differences for
+// L = sum(sigmoid(W * X^T) (.) Y - W')
+func TestAutogradNumerical(t *testing.T) {
+	Wv := mat.FromValues([]float64{
+		0.1, -0.2, 0.3,
+		0.4, 0.5, -0.6,
+	}).MustReshape(2, 3)
+	Xv := mat.FromValues([]float64{
+		1.0, 2.0, -1.0,
+		0.5, -0.5, 0.0,
+		-1.5, 1.0, 2.0,
+		0.3, 0.2, 0.1,
+	}).MustReshape(4, 3)
+	Yv := mat.ARange[float64](8).MustReshape(2, 4)
+
+	loss := func(W, X *autograd.Variable[float64]) *autograd.Variable[float64] {
+		WX, err := autograd.MatMul(W, autograd.TP(X))
+		if err != nil {
+			t.Fatal(err)
+		}
+		act, err := autograd.Apply(WX, acti.Sigmoid[float64], acti.DSigmoid[float64])
+		if err != nil {
+			t.Fatal(err)
+		}
+		prod, err := autograd.Mul(act, autograd.NewConst(Yv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sl, err := autograd.Slice(prod, mat.RS{0, 2}, mat.CS{0, 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff, err := autograd.Subtract(sl, W)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return autograd.Sum(diff)
+	}
+
+	W := autograd.NewVar(Wv)
+	X := autograd.NewVar(Xv)
+	if err := loss(W, X).Backward(); err != nil {
+		t.Fatal(err)
+	}
+
+	const eps = 1e-6
+	check := func(name string, v *mat.Mat2D[float64], grad *mat.Mat2D[float64]) {
+		for i := range v.Rows() {
+			for j := range v.Cols() {
+				orig := v.MustGet(i, j)
+
+				v.MustSet(i, j, orig+eps)
+				lp := loss(autograd.NewConst(Wv), autograd.NewConst(Xv)).Value.MustGet(0, 0)
+				v.MustSet(i, j, orig-eps)
+				lm := loss(autograd.NewConst(Wv), autograd.NewConst(Xv)).Value.MustGet(0, 0)
+				v.MustSet(i, j, orig)
+
+				numeric := (lp - lm) / (2 * eps)
+				if math.Abs(numeric-grad.MustGet(i, j)) > 1e-6 {
+					t.Errorf(
+						"%s[%d, %d]: expected gradient %f, found %f",
+						name, i, j, numeric, grad.MustGet(i, j),
+					)
+				}
+			}
+		}
+	}
+
+	check("W", Wv, W.Grad)
+	check("X", Xv, X.Grad)
+}
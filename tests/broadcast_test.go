@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"gonn/internal/autograd"
+	"gonn/internal/mat"
+	"testing"
+)
+
+func TestBroadcastDims(t *testing.T) {
+	cases := []struct {
+		a, b       [2]uint64
+		rows, cols uint64
+		ok         bool
+	}{
+		{[2]uint64{2, 3}, [2]uint64{2, 3}, 2, 3, true},
+		{[2]uint64{2, 3}, [2]uint64{1, 3}, 2, 3, true},
+		{[2]uint64{2, 3}, [2]uint64{2, 1}, 2, 3, true},
+		{[2]uint64{2, 3}, [2]uint64{1, 1}, 2, 3, true},
+		{[2]uint64{2, 1}, [2]uint64{1, 3}, 2, 3, true},
+		{[2]uint64{2, 3}, [2]uint64{3, 1}, 0, 0, false},
+		{[2]uint64{2, 3}, [2]uint64{1, 2}, 0, 0, false},
+	}
+
+	for _, c := range cases {
+		a := mat.New2DF32(c.a[0], c.a[1])
+		b := mat.New2DF32(c.b[0], c.b[1])
+
+		rows, cols, err := mat.BroadcastDims(a, b)
+		if (err == nil) != c.ok {
+			t.Errorf("BroadcastDims(%v, %v): expected ok=%t, found err=%v", c.a, c.b, c.ok, err)
+			continue
+		}
+		if c.ok && (rows != c.rows || cols != c.cols) {
+			t.Errorf(
+				"BroadcastDims(%v, %v): expected [%d, %d], found [%d, %d]",
+				c.a, c.b, c.rows, c.cols, rows, cols,
+			)
+		}
+	}
+}
+
+func TestBroadcastAdd(t *testing.T) {
+	m := mat.ARange[float32](6).MustReshape(2, 3)
+
+	row := mat.FromValues([]float32{10, 20, 30})
+	sum, err := mat.Add(m, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := mat.FromValues([]float32{
+		10, 21, 32,
+		13, 24, 35,
+	}).MustReshape(2, 3)
+	logIfErr(t, expectMatEq(expected, sum))
+
+	col := mat.FromValues([]float32{100, 200}).MustReshape(2, 1)
+	sum, err = mat.Add(col, m) // broadcast works on either side
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = mat.FromValues([]float32{
+		100, 101, 102,
+		203, 204, 205,
+	}).MustReshape(2, 3)
+	logIfErr(t, expectMatEq(expected, sum))
+
+	// outer sum of a column and a row
+	outer, err := mat.Add(col, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = mat.FromValues([]float32{
+		110, 120, 130,
+		210, 220, 230,
+	}).MustReshape(2, 3)
+	logIfErr(t, expectMatEq(expected, outer))
+}
+
+func TestBroadcastInPlace(t *testing.T) {
+	m := mat.ARange[float32](6).MustReshape(2, 3)
+
+	if err := m.Subtract(mat.FromValues([]float32{1})); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Mul(mat.FromValues([]float32{2, 1, 0})); err != nil {
+		t.Fatal(err)
+	}
+	expected := mat.FromValues([]float32{
+		-2, 0, 0,
+		4, 3, 0,
+	}).MustReshape(2, 3)
+	logIfErr(t, expectMatEq(expected, m))
+
+	// the destination of an in place op cannot grow
+	col := mat.Ones[float32](2, 1)
+	if err := col.Add(m); err == nil {
+		t.Error("Expected error broadcasting [2, 3] into [2, 1], found nil")
+	}
+
+	// transposed views broadcast like any other matrix
+	tp := mat.ARange[float32](3).MustReshape(1, 3).TP()
+	sum, err := mat.Add(tp, mat.FromValues([]float32{0, 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = mat.FromValues([]float32{
+		0, 10,
+		1, 11,
+		2, 12,
+	}).MustReshape(3, 2)
+	logIfErr(t, expectMatEq(expected, sum))
+}
+
+func TestBroadcastAutograd(t *testing.T) {
+	W := autograd.NewVar(mat.ARange[float64](6).MustReshape(2, 3))
+	b := autograd.NewVar(mat.Ones[float64](2, 1))
+	s := autograd.NewVar(mat.FromValues([]float64{2}))
+
+	Wb, err := autograd.Add(W, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaled, err := autograd.Mul(Wb, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := autograd.Sum(scaled).Backward(); err != nil {
+		t.Fatal(err)
+	}
+
+	logIfErr(t, expectMatEq(mat.Ones[float64](2, 3).Scale(2), W.Grad))
+	logIfErr(t, expectMatEq(mat.Ones[float64](2, 1).Scale(6), b.Grad))
+	// sum(W + b) = 15 + 6
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{21}), s.Grad))
+}
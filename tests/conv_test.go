@@ -0,0 +1,251 @@
+package tests
+
+import (
+	"bytes"
+	"gonn/internal/gradcheck"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func TestConvGeom(t *testing.T) {
+	g := mat.ConvGeom{
+		Channels: 1, Height: 7, Width: 5,
+		KernelH: 3, KernelW: 2,
+		StrideH: 2, PadW: 1, DilationH: 2,
+	}
+	if err := g.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// (7 + 0 - 2*2 - 1)/2 + 1 = 2, (5 + 2 - 1 - 1)/1 + 1 = 6
+	if g.OutH() != 2 || g.OutW() != 6 {
+		t.Errorf("Expected output [2, 6], found [%d, %d]", g.OutH(), g.OutW())
+	}
+
+	if _, ok := g.InputRow(0, 0, 0, 0, 0); ok {
+		t.Error("Expected tap on left padding to be out of bounds")
+	}
+	if row, ok := g.InputRow(0, 1, 2, 1, 1); !ok || row != 4*5+2 {
+		t.Errorf("Expected tap at row 22, found %d (ok=%t)", row, ok)
+	}
+
+	bad := mat.ConvGeom{Channels: 1, Height: 2, Width: 2, KernelH: 3, KernelW: 3}
+	if err := bad.Validate(); err == nil {
+		t.Error("Expected error for kernel larger than input, found nil")
+	}
+}
+
+func TestIm2ColRoundTrip(t *testing.T) {
+	g := mat.ConvGeom{
+		Channels: 2, Height: 4, Width: 4,
+		KernelH: 2, KernelW: 2,
+		StrideH: 2, StrideW: 2,
+	}
+
+	// non overlapping windows: Col2Im(Im2Col(x)) == x
+	x := mat.ARange[float64](2*16*3).MustReshape(32, 3)
+	cols, err := mat.Im2Col(x, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols.Rows() != 8 || cols.Cols() != 4*3 {
+		t.Fatalf("Expected cols [8, 12], found [%d, %d]", cols.Rows(), cols.Cols())
+	}
+
+	back, err := mat.Col2Im(cols, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(x, back))
+
+	// transposed input views are handled
+	colsTP, err := mat.Im2Col(x.TP().Clone().TP(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(cols, colsTP))
+
+	if _, err := mat.Im2Col(mat.New2DF64(31, 3), g); err == nil {
+		t.Error("Expected error for mismatched input rows, found nil")
+	}
+}
+
+// direct convolution used as a reference for Conv2D
+func naiveConv(x, W *mat.Mat2DF64, g mat.ConvGeom, outChannels uint64) *mat.Mat2DF64 {
+	g = g.Normalized()
+	OH, OW := g.OutH(), g.OutW()
+	out := mat.New2DF64(outChannels*OH*OW, uint64(x.Cols()))
+
+	for oc := range outChannels {
+		for oh := range OH {
+			for ow := range OW {
+				for n := range x.Cols() {
+					sum := W.MustGet(int64(oc), 0)
+
+					for c := range g.Channels {
+						for kh := range g.KernelH {
+							for kw := range g.KernelW {
+								ih := int64(oh*g.StrideH+kh*g.DilationH) - int64(g.PadH)
+								iw := int64(ow*g.StrideW+kw*g.DilationW) - int64(g.PadW)
+								if ih < 0 || iw < 0 || ih >= int64(g.Height) || iw >= int64(g.Width) {
+									continue
+								}
+
+								xv := x.MustGet(int64(c*g.Height*g.Width)+ih*int64(g.Width)+iw, n)
+								wv := W.MustGet(int64(oc), int64(1+c*g.KernelH*g.KernelW+kh*g.KernelW+kw))
+								sum += xv * wv
+							}
+						}
+					}
+
+					out.MustSet(int64(oc*OH*OW+oh*OW+ow), n, sum)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+func TestConv2DForward(t *testing.T) {
+	geoms := []mat.ConvGeom{
+		{Channels: 1, Height: 5, Width: 5, KernelH: 3, KernelW: 3},
+		{Channels: 2, Height: 6, Width: 5, KernelH: 3, KernelW: 2, StrideH: 2, PadH: 1, PadW: 2},
+		{Channels: 3, Height: 7, Width: 7, KernelH: 2, KernelW: 3, DilationH: 2, DilationW: 2, PadW: 1},
+	}
+
+	for _, g := range geoms {
+		conv, err := layer.NewConv2D[float64](g, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		x := mat.RandF64(g.InSize(), 3)
+		out, err := conv.Forward(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logIfErr(t, expectMatNear(naiveConv(x, conv.W, g, 4), out, 1e-12))
+	}
+
+	conv, _ := layer.NewConv2D[float64](geoms[0], 2)
+	if _, err := conv.Forward(mat.New2DF64(24, 1)); err == nil {
+		t.Error("Expected error for mismatched input, found nil")
+	}
+	if _, err := layer.NewConv2D[float64](geoms[0], 0); err == nil {
+		t.Error("Expected error for zero output channels, found nil")
+	}
+}
+
+func TestConv2DGradients(t *testing.T) {
+	g := mat.ConvGeom{
+		Channels: 2, Height: 5, Width: 4,
+		KernelH: 3, KernelW: 2,
+		StrideH: 2, PadH: 1, PadW: 1, DilationW: 2,
+	}
+	conv, err := layer.NewConv2D[float64](g, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gradcheck.Expect(t, conv, mat.RandF64(g.InSize(), 2))
+}
+
+func TestMaxPool2D(t *testing.T) {
+	g := mat.ConvGeom{Channels: 1, Height: 4, Width: 4, KernelH: 2, KernelW: 2, StrideH: 2, StrideW: 2}
+	pool, err := layer.NewMaxPool2D[float64](g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := mat.FromValues([]float64{
+		1, 2, -1, -2,
+		3, 4, -3, -4,
+		5, 0, 0, 0,
+		0, 0, 0, 9,
+	}).MustReshape(16, 1)
+
+	out, err := pool.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{4, -1, 5, 9}).MustReshape(4, 1), out))
+
+	back, err := pool.Backward(mat.FromValues([]float64{1, 2, 3, 4}).MustReshape(4, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := mat.FromValues([]float64{
+		0, 0, 2, 0,
+		0, 1, 0, 0,
+		3, 0, 0, 0,
+		0, 0, 0, 4,
+	}).MustReshape(16, 1)
+	logIfErr(t, expectMatEq(expected, back))
+
+	// padding never wins against negative inputs
+	padded, _ := layer.NewMaxPool2D[float64](mat.ConvGeom{
+		Channels: 1, Height: 2, Width: 2, KernelH: 2, KernelW: 2, PadH: 1, PadW: 1,
+	})
+	out, err = padded.Forward(mat.Ones[float64](4, 1).Scale(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.Ones[float64](9, 1).Scale(-1), out))
+}
+
+func TestPoolGradients(t *testing.T) {
+	g := mat.ConvGeom{Channels: 2, Height: 5, Width: 5, KernelH: 3, KernelW: 2, StrideH: 2, PadW: 1}
+
+	maxPool, err := layer.NewMaxPool2D[float64](g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avgPool, err := layer.NewAvgPool2D[float64](g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// distinct values so the max is never tied
+	x := mat.ARange[float64](g.InSize()*2).MustReshape(g.InSize(), 2).Apply(
+		func(v float64) float64 { return math.Sin(v * 1.7) },
+	)
+
+	gradcheck.Expect(t, maxPool, x)
+	gradcheck.Expect(t, avgPool, x)
+}
+
+func TestConv2DSaveLoad(t *testing.T) {
+	g := mat.ConvGeom{Channels: 1, Height: 6, Width: 6, KernelH: 3, KernelW: 3, PadH: 1, PadW: 1}
+	conv, _ := layer.NewConv2D[float64](g, 2)
+	pool, _ := layer.NewMaxPool2D[float64](mat.ConvGeom{
+		Channels: 2, Height: 6, Width: 6, KernelH: 2, KernelW: 2, StrideH: 2, StrideW: 2,
+	})
+	avg, _ := layer.NewAvgPool2D[float64](mat.ConvGeom{
+		Channels: 2, Height: 3, Width: 3, KernelH: 3, KernelW: 3,
+	})
+	model := layer.NewSequential[float64](conv, pool, avg)
+
+	var buf bytes.Buffer
+	if err := layer.Save[float64](&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	layers, err := layer.Load[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := mat.RandF64(36, 2)
+	expected, err := model.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := layers[0].Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(expected, found, 0))
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"gonn/internal/data"
+	"gonn/internal/mat"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const irisLikeCSV = `sepal,petal,color,species
+5.1,1.4,red,setosa
+7.0,4.7,blue,versicolor
+6.3,NA,red,virginica
+4.9,1.5,,setosa
+`
+
+func TestReadCSV(t *testing.T) {
+	d, err := data.ReadCSV[float64](strings.NewReader(irisLikeCSV), data.CSVOptions{
+		Features: []data.ColumnRef{data.ByName("sepal"), data.ByIndex(1), data.ByName("color")},
+		Labels:   []data.ColumnRef{data.ByName("species")},
+		Missing:  data.MissingMean,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedNames := []string{"sepal", "petal", "color=blue", "color=red"}
+	if !slices.Equal(d.FeatureNames, expectedNames) {
+		t.Errorf("Expected features %v, found %v", expectedNames, d.FeatureNames)
+	}
+	if !slices.Equal(d.Categories["species"], []string{"setosa", "versicolor", "virginica"}) {
+		t.Errorf("Expected sorted species categories, found %v", d.Categories["species"])
+	}
+
+	// petal mean of present values is 7.6 / 3, the missing color is the most frequent (red)
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{
+		5.1, 7.0, 6.3, 4.9,
+		1.4, 4.7, 7.6 / 3, 1.5,
+		0, 1, 0, 0,
+		1, 0, 1, 1,
+	}).MustReshape(4, 4), d.X, 1e-12))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{
+		1, 0, 0, 1,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+	}).MustReshape(3, 4), d.Y))
+
+	ds, err := d.Dataset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Len() != 4 {
+		t.Errorf("Expected 4 samples, found %d", ds.Len())
+	}
+}
+
+func TestReadCSVMissingPolicies(t *testing.T) {
+	opts := data.CSVOptions{
+		Features: []data.ColumnRef{data.ByName("petal"), data.ByName("color")},
+	}
+
+	if _, err := data.ReadCSV[float64](strings.NewReader(irisLikeCSV), opts); err == nil {
+		t.Error("Expected MissingError by default")
+	}
+
+	opts.Missing = data.MissingDrop
+	d, err := data.ReadCSV[float64](strings.NewReader(irisLikeCSV), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.X.Cols() != 2 || d.Y != nil {
+		t.Errorf("Expected 2 complete rows and no labels, found %d rows", d.X.Cols())
+	}
+
+	opts.Missing = data.MissingZero
+	d, err = data.ReadCSV[float64](strings.NewReader(irisLikeCSV), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{
+		1.4, 4.7, 0, 1.5,
+		0, 1, 0, 0,
+		1, 0, 1, 0,
+	}).MustReshape(3, 4), d.X))
+}
+
+func TestReadCSVHeaderAndColumns(t *testing.T) {
+	raw := "1;0;3\n2;1;1\n3;1;2\n"
+
+	d, err := data.ReadCSV[float32](strings.NewReader(raw), data.CSVOptions{
+		Comma:       ';',
+		Labels:      []data.ColumnRef{data.ByIndex(2)},
+		Categorical: []data.ColumnRef{data.ByIndex(2)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.X.Rows() != 2 || d.X.Cols() != 3 {
+		t.Fatalf("Expected X[2, 3] without a header, found [%d, %d]", d.X.Rows(), d.X.Cols())
+	}
+	if !slices.Equal(d.LabelNames, []string{"2=1", "2=2", "2=3"}) {
+		t.Errorf("Expected one-hot codes of column 2, found %v", d.LabelNames)
+	}
+
+	if _, err := data.ReadCSV[float32](strings.NewReader(raw), data.CSVOptions{
+		Comma:  ';',
+		Labels: []data.ColumnRef{data.ByName("label")},
+	}); err == nil {
+		t.Error("Expected error for an unknown column")
+	}
+
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte("a,b\n1,2\n3,4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, err = data.LoadCSV[float32](path, data.CSVOptions{Labels: []data.ColumnRef{data.ByName("b")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.FromValues([]float32{1, 3}), d.X))
+	logIfErr(t, expectMatEq(mat.FromValues([]float32{2, 4}), d.Y))
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"fmt"
+	"gonn/internal/data"
+	"gonn/internal/mat"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// X[2, n] with column i = [i, -i], Y[1, n] = i
+func newRangeDataset(t *testing.T, n int) *data.MatDataset[float64] {
+	t.Helper()
+	X := mat.New2DF64(2, uint64(n))
+	for i := range int64(n) {
+		X.MustSet(0, i, float64(i))
+		X.MustSet(1, i, -float64(i))
+	}
+	ds, err := data.NewMatDataset(X, mat.ARange[float64](uint64(n)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+// collects the label of every sample in an epoch, and the batch sizes
+func epochLabels(t *testing.T, dl *data.DataLoader[float64]) (labels []int, sizes []int) {
+	t.Helper()
+	it := dl.Epoch()
+	defer it.Close()
+
+	for it.Next() {
+		X, Y := it.Batch()
+		sizes = append(sizes, int(Y.Cols()))
+		for j := range Y.Cols() {
+			if X.MustGet(0, j) != Y.MustGet(0, j) || X.MustGet(1, j) != -Y.MustGet(0, j) {
+				t.Errorf("Features of sample %f not collated with its label", Y.MustGet(0, j))
+			}
+			labels = append(labels, int(Y.MustGet(0, j)))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return labels, sizes
+}
+
+func TestDataLoaderBatches(t *testing.T) {
+	dl, err := data.NewDataLoader[float64](newRangeDataset(t, 10), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl.RNG = rand.New(rand.NewSource(1))
+
+	for _, prefetch := range []int{0, 2} {
+		dl.Prefetch = prefetch
+
+		labels, sizes := epochLabels(t, dl)
+		if !slices.Equal(sizes, []int{4, 4, 2}) || dl.Batches() != 3 {
+			t.Errorf("prefetch %d: expected batches [4 4 2], found %v", prefetch, sizes)
+		}
+		slices.Sort(labels)
+		if !slices.Equal(labels, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+			t.Errorf("prefetch %d: expected every sample once, found %v", prefetch, labels)
+		}
+	}
+
+	dl.DropLast = true
+	if _, sizes := epochLabels(t, dl); !slices.Equal(sizes, []int{4, 4}) || dl.Batches() != 2 {
+		t.Errorf("Expected the remainder dropped, found %v", sizes)
+	}
+}
+
+func TestDataLoaderShuffle(t *testing.T) {
+	dl, err := data.NewDataLoader[float64](newRangeDataset(t, 20), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dl.Shuffle = false
+	ordered, _ := epochLabels(t, dl)
+	if !slices.IsSorted(ordered) {
+		t.Errorf("Expected dataset order without Shuffle, found %v", ordered)
+	}
+
+	dl.Shuffle = true
+	dl.RNG = rand.New(rand.NewSource(4))
+	first, _ := epochLabels(t, dl)
+	second, _ := epochLabels(t, dl)
+	if slices.Equal(first, second) {
+		t.Error("Expected a new order every epoch")
+	}
+
+	dl.RNG = rand.New(rand.NewSource(4))
+	if again, _ := epochLabels(t, dl); !slices.Equal(first, again) {
+		t.Error("Expected the same order from the same seed")
+	}
+}
+
+type failingDataset struct{ *data.MatDataset[float64] }
+
+func (ds failingDataset) Get(i int) (x, y *mat.Mat2DF64, err error) {
+	if i == 3 {
+		return nil, nil, fmt.Errorf("corrupt sample")
+	}
+	return ds.MatDataset.Get(i)
+}
+
+func TestDataLoaderErrorsAndClose(t *testing.T) {
+	for _, prefetch := range []int{0, 1} {
+		dl, err := data.NewDataLoader[float64](failingDataset{newRangeDataset(t, 8)}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dl.Shuffle = false
+		dl.Prefetch = prefetch
+
+		it := dl.Epoch()
+		batches := 0
+		for it.Next() {
+			batches++
+		}
+		if batches != 1 || it.Err() == nil {
+			t.Errorf("prefetch %d: expected an error on the second batch, found %d batches, err %v", prefetch, batches, it.Err())
+		}
+		it.Close()
+	}
+
+	// leaving an epoch early
+	dl, err := data.NewDataLoader[float64](newRangeDataset(t, 100), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dl.Prefetch = 4
+	it := dl.Epoch()
+	if !it.Next() {
+		t.Fatal(it.Err())
+	}
+	it.Close()
+	it.Close()
+	if it.Next() {
+		t.Error("Expected no batches after Close")
+	}
+
+	if _, err := data.NewDataLoader[float64](newRangeDataset(t, 1), 0); err == nil {
+		t.Error("Expected error for batch size 0")
+	}
+}
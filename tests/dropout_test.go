@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"testing"
+)
+
+func TestDropoutTraining(t *testing.T) {
+	dl, err := layer.NewDropout[float64](0.25, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := mat.Ones[float64](100, 100)
+	out, err := dl.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dropped := 0
+	for i := range out.Rows() {
+		for j := range out.Cols() {
+			switch v := out.MustGet(i, j); v {
+			case 0:
+				dropped++
+			default:
+				logIfErr(t, expectNear(1/0.75, v, 1e-12))
+			}
+		}
+	}
+	if dropped < 2300 || dropped > 2700 {
+		t.Errorf("Expected about 2500 of 10000 dropped, found %d", dropped)
+	}
+
+	// the gradient only flows through the kept elements
+	back, err := dl.Backward(mat.Ones[float64](100, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(out, back))
+}
+
+func TestDropoutSeeded(t *testing.T) {
+	x := mat.RandF64(10, 10)
+
+	outs := make([]*mat.Mat2DF64, 2)
+	for i := range outs {
+		dl, err := layer.NewDropout[float64](0.5, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outs[i], err = dl.Forward(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logIfErr(t, expectMatEq(outs[0], outs[1]))
+
+	if _, err := layer.NewDropout[float64](1, 0); err == nil {
+		t.Error("Expected error for rate 1")
+	}
+}
+
+func TestSetTrainingPropagates(t *testing.T) {
+	dl, err := layer.NewDropout[float64](0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bn := layer.NewBatchNorm[float64](4)
+	block, err := layer.NewTransformerEncoderBlock[float64](4, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := layer.NewDropout[float64](0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.FFN.Append(inner)
+
+	model := layer.NewSequential[float64](
+		layer.NewLL[float64](4, 4),
+		bn,
+		newSigmoidAL(),
+		layer.NewSequential[float64](dl),
+		block,
+	)
+
+	model.SetTraining(false)
+	for name, ms := range map[string]layer.ModeSetter{
+		"model": model, "BatchNorm": bn, "Dropout": dl, "block": block, "FFN Dropout": inner,
+	} {
+		if ms.IsTraining() {
+			t.Errorf("Expected %s in inference mode", name)
+		}
+	}
+
+	// at inference dropout is the identity
+	x := mat.RandF64(4, 3)
+	out, err := dl.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(x, out))
+
+	// appended layers follow the model
+	late := layer.NewBatchNorm[float64](4)
+	model.Append(late)
+	if late.IsTraining() {
+		t.Error("Expected an appended layer to follow the model into inference mode")
+	}
+
+	model.SetTraining(true)
+	if !bn.IsTraining() || !inner.IsTraining() {
+		t.Error("Expected SetTraining(true) to reach every layer")
+	}
+}
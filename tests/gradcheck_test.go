@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/gradcheck"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"math"
+	"strings"
+	"testing"
+)
+
+// a LinearLayer whose Backward returns twice the input gradient
+type doubledBackward struct {
+	*layer.LinearLayer[float64]
+}
+
+func (l doubledBackward) Backward(loss *mat.Mat2DF64) (*mat.Mat2DF64, error) {
+	dx, err := l.LinearLayer.Backward(loss)
+	if err != nil {
+		return nil, err
+	}
+	return dx.Scale(2), nil
+}
+
+// a LinearLayer reporting a weight gradient with its bias column zeroed
+type droppedBiasGrad struct {
+	*layer.LinearLayer[float64]
+}
+
+func (l droppedBiasGrad) IsLearnable() (bool, *mat.Mat2DF64) {
+	grad := l.WGrad.Clone()
+	for i := range grad.Rows() {
+		grad.MustSet(i, 0, 0)
+	}
+	return true, grad
+}
+
+func TestLayerGradients(t *testing.T) {
+	mustAL := func(name string) layer.Layer[float64] {
+		al, err := layer.NewALByName[float64](name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return al
+	}
+	dropout, err := layer.NewDropout[float64](0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropout.SetTraining(false)
+
+	// |inputs| in [0.1, 1.1) with alternating signs, both sides of the ReLU kink but away from it
+	x := func(rows uint64) *mat.Mat2DF64 {
+		x := mat.RandF64(rows, 3)
+		for i := range x.Rows() {
+			for j := range x.Cols() {
+				v := x.MustGet(i, j) + 0.1
+				if (i+j)%2 == 1 {
+					v = -v
+				}
+				x.MustSet(i, j, v)
+			}
+		}
+		return x
+	}
+
+	for name, l := range map[string]layer.Layer[float64]{
+		"LinearLayer":   layer.NewLL[float64](4, 3),
+		"sigmoid":       mustAL(acti.NameSigmoid),
+		"tanh":          mustAL(acti.NameTanh),
+		"relu":          mustAL(acti.NameReLU),
+		"lrelu":         mustAL(acti.NameLReLU),
+		"softplus":      mustAL(acti.NameSoftPlus),
+		"Dropout(eval)": dropout,
+		"Sequential": layer.NewSequential[float64](
+			layer.NewLL[float64](4, 5), mustAL(acti.NameTanh), layer.NewLL[float64](5, 2),
+		),
+	} {
+		t.Run(name, func(t *testing.T) {
+			gradcheck.Expect(t, l, x(4))
+		})
+	}
+}
+
+func TestGradcheckReportsBrokenLayers(t *testing.T) {
+	opts := gradcheck.DefaultOptions()
+
+	report, err := gradcheck.Check(layer.NewLL[float64](3, 2), mat.RandF64(3, 2), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3x2 input and 2x(1+3) weights
+	if !report.OK() || len(report.Elements) != 6+8 || report.MaxRelErr() > opts.Tol {
+		t.Errorf("Expected a passing report of 14 elements, found:\n%s", report)
+	}
+
+	report, err = gradcheck.Check(doubledBackward{layer.NewLL[float64](3, 2)}, mat.RandF64(3, 2), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range report.Failures() {
+		if e.Param != "x" {
+			t.Errorf("Expected only input gradients to fail, found %s", e)
+		}
+	}
+	if report.OK() {
+		t.Errorf("Expected the doubled input gradient to fail")
+	}
+
+	report, err = gradcheck.Check(droppedBiasGrad{layer.NewLL[float64](3, 2)}, mat.RandF64(3, 2), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := report.Failures()
+	if len(failures) != 2 {
+		t.Fatalf("Expected the 2 bias elements to fail, found:\n%s", report)
+	}
+	for _, e := range failures {
+		if e.Param != "param[0]" || e.Col != 0 || e.Analytic != 0 {
+			t.Errorf("Expected a bias element with analytic 0, found %s", e)
+		}
+	}
+	if !strings.Contains(report.String(), "2 of 14 elements") {
+		t.Errorf("Expected the report to count its failures, found:\n%s", report)
+	}
+
+	opts.Weights = false
+	report, err = gradcheck.Check(droppedBiasGrad{layer.NewLL[float64](3, 2)}, mat.RandF64(3, 2), opts)
+	if err != nil || !report.OK() || len(report.Elements) != 6 {
+		t.Errorf("Expected only the 6 passing input elements without weights, found %v:\n%s", err, report)
+	}
+
+	if _, err := gradcheck.Check(layer.NewLL[float64](3, 2), mat.RandF64(4, 2), gradcheck.DefaultOptions()); err == nil {
+		t.Errorf("Expected error for an input the layer rejects")
+	}
+}
+
+// log(1 + e^x), finite at both extremes
+func TestSoftPlus(t *testing.T) {
+	for x, expected := range map[float64]float64{
+		-800: 0,
+		0:    math.Log(2),
+		1:    math.Log(1 + math.E),
+		-2:   math.Log(1 + math.Exp(-2)),
+		800:  800,
+	} {
+		logIfErr(t, expectNear(expected, acti.SoftPlus(x), 1e-12))
+	}
+}
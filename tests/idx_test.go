@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"gonn/demos"
+	"gonn/internal/data"
+	"gonn/internal/mat"
+	"math"
+	"slices"
+	"testing"
+)
+
+const (
+	mnistImages = "testdata/mnist/images-idx3-ubyte.gz" // gzipped
+	mnistLabels = "testdata/mnist/labels-idx1-ubyte"    // raw
+)
+
+func TestReadIDXDTypes(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0x0B, 2})
+	binary.Write(&buf, binary.BigEndian, []uint32{2, 2})
+	binary.Write(&buf, binary.BigEndian, []int16{-3, 0, 7, 300})
+
+	tensor, err := data.ReadIDX[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := mat.TensorFromValues([]float64{-3, 0, 7, 300}, 2, 2)
+	if !mat.TensorEquals(expected, tensor) {
+		t.Errorf("Expected int16 values [-3 0 7 300], found %v", tensor)
+	}
+
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0x0D, 1})
+	binary.Write(&buf, binary.BigEndian, uint32(2))
+	binary.Write(&buf, binary.BigEndian, []float32{0.5, -2})
+
+	floats, err := data.ReadIDX[float32](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if floats.MustGet(0) != 0.5 || floats.MustGet(1) != -2 {
+		t.Errorf("Expected float32 values [0.5 -2], found [%f %f]", floats.MustGet(0), floats.MustGet(1))
+	}
+}
+
+func TestReadIDXErrors(t *testing.T) {
+	for name, raw := range map[string][]byte{
+		"bad magic":  {1, 0, 0x08, 1, 0, 0, 0, 1, 5},
+		"bad dtype":  {0, 0, 0x42, 1, 0, 0, 0, 1, 5},
+		"truncated":  {0, 0, 0x08, 1, 0, 0, 0, 3, 5},
+		"no dims":    {0, 0, 0x08, 2, 0, 0, 0, 3},
+		"bad header": {0, 0},
+	} {
+		if _, err := data.ReadIDX[float32](bytes.NewReader(raw)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadMNISTFixture(t *testing.T) {
+	images, err := data.LoadIDX[float32](mnistImages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(images.Shape(), []uint64{60, 8, 8}) {
+		t.Fatalf("Expected images [60 8 8], found %v", images.Shape())
+	}
+
+	ds, err := data.LoadMNIST[float32](mnistImages, mnistLabels, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.X.Rows() != 64 || ds.X.Cols() != 60 || ds.Y.Rows() != 10 {
+		t.Fatalf("Expected X[64, 60], Y[10, 60], found X[%d, %d], Y[%d, %d]",
+			ds.X.Rows(), ds.X.Cols(), ds.Y.Rows(), ds.Y.Cols())
+	}
+
+	// pixel (r, c) of image n is X[r*8 + c, n], scaled to [0, 1]
+	x, y, err := ds.Get(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := x.MustGet(3*8+4, 0); math.Abs(float64(v)-float64(images.MustGet(5, 3, 4))/255) > 1e-6 {
+		t.Errorf("Expected pixel %f, found %f", images.MustGet(5, 3, 4)/255, v)
+	}
+	if y.Sum() != 1 || y.MustGet(5%3, 0) != 1 {
+		t.Errorf("Expected one-hot label %d for image 5", 5%3)
+	}
+
+	if _, err := data.LoadMNIST[float32](mnistImages, mnistLabels, 2); err == nil {
+		t.Error("Expected error for labels outside the classes")
+	}
+}
+
+func TestTrainMNISTFixture(t *testing.T) {
+	accuracy, err := demos.TrainMNIST(mnistImages, mnistLabels, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accuracy < 0.95 {
+		t.Errorf("Expected the fixture to be learned, accuracy %f", accuracy)
+	}
+}
@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"errors"
+	"gonn/internal/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// a random well conditioned (diagonally dominant) n x n matrix
+func randSquare(rng *rand.Rand, n uint64) *mat.Mat2DF64 {
+	a := mat.Normal[float64](rng, n, n, 0, 1)
+	for i := range int64(n) {
+		a.MustSet(i, i, a.MustGet(i, i)+float64(n))
+	}
+	return a
+}
+
+// M*M^T + I, symmetric positive definite
+func randSPD(rng *rand.Rand, n uint64) *mat.Mat2DF64 {
+	m := mat.Normal[float64](rng, n, n, 0, 1)
+	return mat.MustMatMul(m, m.TP()).MustAdd(mat.Id[float64](n))
+}
+
+func expectTriangular(t *testing.T, name string, m *mat.Mat2DF64, tri mat.Triangle) {
+	t.Helper()
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			if (tri == mat.Lower && j > i || tri == mat.Upper && j < i) && m.MustGet(i, j) != 0 {
+				t.Errorf("Expected %s triangular, found %f at [%d, %d]", name, m.MustGet(i, j), i, j)
+			}
+		}
+	}
+}
+
+func TestLUReconstructs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, n := range []uint64{1, 2, 5, 8} {
+		// a transposed view goes through the same path
+		for _, a := range []*mat.Mat2DF64{mat.Normal[float64](rng, n, n, 0, 1), mat.Normal[float64](rng, n, n, 0, 1).TP()} {
+			lu, err := mat.LUDecompose(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			L, U, P := lu.L(), lu.U(), lu.P()
+			expectTriangular(t, "L", L, mat.Lower)
+			expectTriangular(t, "U", U, mat.Upper)
+			for i := range L.Rows() {
+				if L.MustGet(i, i) != 1 {
+					t.Errorf("Expected unit diagonal L, found %f at %d", L.MustGet(i, i), i)
+				}
+			}
+			logIfErr(t, expectMatNear(mat.MustMatMul(P, a), mat.MustMatMul(L, U), 1e-12))
+
+			// partial pivoting keeps |L| <= 1
+			for i := range L.Rows() {
+				for j := range i {
+					if math.Abs(L.MustGet(i, j)) > 1 {
+						t.Errorf("Expected |L| <= 1 with pivoting, found %f", L.MustGet(i, j))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestQRReconstructs(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for _, dims := range [][2]uint64{{1, 1}, {4, 4}, {7, 3}, {6, 1}} {
+		a := mat.Normal[float64](rng, dims[0], dims[1], 0, 1)
+		qr, err := mat.QRDecompose(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		Q, R, fullQ := qr.Q(), qr.R(), qr.FullQ()
+		expectTriangular(t, "R", R, mat.Upper)
+		logIfErr(t, expectMatNear(a, mat.MustMatMul(Q, R), 1e-12))
+		logIfErr(t, expectMatNear(mat.Id[float64](dims[1]), mat.MustMatMul(Q.TP(), Q), 1e-12))
+		logIfErr(t, expectMatNear(mat.Id[float64](dims[0]), mat.MustMatMul(fullQ.TP(), fullQ), 1e-12))
+		logIfErr(t, expectMatNear(Q, fullQ.MustSlice(mat.RS{0, int64(dims[0])}, mat.CS{0, int64(dims[1])}), 1e-12))
+	}
+
+	// a zero column is rank deficient but still factors
+	a := mat.FromValues([]float64{1, 0, 2, 0, 3, 0}).MustReshape(3, 2)
+	qr, err := mat.QRDecompose(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(a, mat.MustMatMul(qr.Q(), qr.R()), 1e-12))
+	if qr.IsFullRank() {
+		t.Errorf("Expected a zero column to be rank deficient")
+	}
+	if _, err := qr.Solve(mat.Ones[float64](3, 1)); !errors.Is(err, mat.ErrSingular) {
+		t.Errorf("Expected ErrSingular, found %v", err)
+	}
+}
+
+func TestQRLeastSquares(t *testing.T) {
+	// y = 1 + 2x sampled exactly, then with residuals that sum to 0 against both columns
+	A := mat.FromValues([]float64{
+		1, 0,
+		1, 1,
+		1, 2,
+		1, 3,
+	}).MustReshape(4, 2)
+	y := mat.FromValues([]float64{1, 3, 5, 7}).TP()
+
+	qr, err := mat.QRDecompose(A)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := qr.Solve(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{1, 2}).TP(), x, 1e-12))
+
+	// residual r = [1, -1, -1, 1] is orthogonal to A's columns, so the fit is unchanged
+	noisy := y.Clone().MustAdd(mat.FromValues([]float64{1, -1, -1, 1}).TP())
+	x, err = qr.Solve(noisy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{1, 2}).TP(), x, 1e-12))
+}
+
+func TestCholesky(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	for _, n := range []uint64{1, 3, 6} {
+		a := randSPD(rng, n)
+		chol, err := mat.CholeskyDecompose(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		L := chol.L()
+		expectTriangular(t, "L", L, mat.Lower)
+		logIfErr(t, expectMatNear(a, mat.MustMatMul(L, L.TP()), 1e-12))
+
+		det, err := mat.Det(a)
+		logIfErr(t, err)
+		logIfErr(t, expectNear(det, chol.Det(), 1e-9*math.Abs(det)))
+
+		b := mat.Normal[float64](rng, n, 2, 0, 1)
+		x, err := chol.Solve(b)
+		logIfErr(t, err)
+		logIfErr(t, expectMatNear(b, mat.MustMatMul(a, x), 1e-10))
+	}
+
+	notSym := mat.FromValues([]float64{2, 1, 0, 2}).MustReshape(2, 2)
+	if _, err := mat.CholeskyDecompose(notSym); !errors.Is(err, mat.ErrNotSymmetric) {
+		t.Errorf("Expected ErrNotSymmetric, found %v", err)
+	}
+	indefinite := mat.FromValues([]float64{1, 2, 2, 1}).MustReshape(2, 2)
+	if _, err := mat.CholeskyDecompose(indefinite); !errors.Is(err, mat.ErrNotPositiveDefinite) {
+		t.Errorf("Expected ErrNotPositiveDefinite, found %v", err)
+	}
+}
+
+func TestSolveInverseDet(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+
+	det, err := mat.Det(mat.FromValues([]float64{1, 2, 3, 4}).MustReshape(2, 2))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(-2, det, 1e-12))
+
+	// needs a row swap: [[0, 1], [1, 0]] has det -1
+	det, err = mat.Det(mat.FromValues([]float64{0, 1, 1, 0}).MustReshape(2, 2))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(-1, det, 0))
+
+	for _, n := range []uint64{1, 4, 7} {
+		a, c := randSquare(rng, n), randSquare(rng, n)
+
+		b := mat.Normal[float64](rng, n, 3, 0, 1)
+		x, err := mat.Solve(a, b)
+		logIfErr(t, err)
+		logIfErr(t, expectMatNear(b, mat.MustMatMul(a, x), 1e-10))
+
+		inv, err := mat.Inverse(a)
+		logIfErr(t, err)
+		logIfErr(t, expectMatNear(mat.Id[float64](n), mat.MustMatMul(a, inv), 1e-10))
+		logIfErr(t, expectMatNear(mat.Id[float64](n), mat.MustMatMul(inv, a), 1e-10))
+
+		// det(A*C) = det(A)*det(C), det(A^T) = det(A)
+		detA, _ := mat.Det(a)
+		detC, _ := mat.Det(c)
+		detAC, _ := mat.Det(mat.MustMatMul(a, c))
+		detAT, _ := mat.Det(a.TP())
+		logIfErr(t, expectNear(detA*detC, detAC, 1e-9*math.Abs(detAC)))
+		logIfErr(t, expectNear(detA, detAT, 1e-9*math.Abs(detA)))
+	}
+
+	// float32 solves through float64 internally
+	a32 := mat.FromValues([]float32{4, 1, 2, 3}).MustReshape(2, 2)
+	x32, err := mat.Solve(a32, mat.FromValues([]float32{1, 2}).TP())
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(mat.FromValues([]float32{0.1, 0.6}).TP(), x32, 1e-6))
+}
+
+func TestTriangularSolveAndCond(t *testing.T) {
+	L := mat.FromValues([]float64{2, 0, 0, 1, 3, 0, -1, 2, 4}).MustReshape(3, 3)
+	b := mat.FromValues([]float64{2, 7, 9}).TP()
+
+	x, err := mat.SolveTriangular(L, mat.Lower, b)
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(b, mat.MustMatMul(L, x), 1e-12))
+
+	// the upper half of L^T
+	x, err = mat.SolveTriangular(L.TP(), mat.Upper, b)
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(b, mat.MustMatMul(L.TP(), x), 1e-12))
+
+	L.MustSet(1, 1, 0)
+	if _, err := mat.SolveTriangular(L, mat.Lower, b); !errors.Is(err, mat.ErrSingular) {
+		t.Errorf("Expected ErrSingular for a zero diagonal, found %v", err)
+	}
+
+	cond, err := mat.Cond(mat.Id[float64](4))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(1, cond, 1e-12))
+
+	cond, err = mat.Cond(mat.FromValues([]float64{1, 0, 0, 1e-3}).MustReshape(2, 2))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(1e3, cond, 1e-9))
+
+	cond, err = mat.Cond(mat.FromValues([]float64{1, 2, 2, 4}).MustReshape(2, 2))
+	if err != nil || !math.IsInf(cond, 1) {
+		t.Errorf("Expected +Inf condition for a singular matrix, found %f, %v", cond, err)
+	}
+}
+
+func TestLinalgErrors(t *testing.T) {
+	rect := mat.Ones[float64](2, 3)
+	singular := mat.FromValues([]float64{1, 2, 3, 2, 4, 6, 0, 1, 1}).MustReshape(3, 3)
+
+	for name, err := range map[string]error{
+		"LU":       second(mat.LUDecompose(rect)),
+		"Cholesky": second(mat.CholeskyDecompose(rect)),
+		"Solve":    second(mat.Solve(rect, mat.Ones[float64](2, 1))),
+		"Inverse":  second(mat.Inverse(rect)),
+		"Det":      second(mat.Det(rect)),
+		"Cond":     second(mat.Cond(rect)),
+	} {
+		if !errors.Is(err, mat.ErrNotSquare) {
+			t.Errorf("%s: expected ErrNotSquare, found %v", name, err)
+		}
+	}
+
+	if _, err := mat.QRDecompose(rect); err == nil {
+		t.Errorf("Expected error for QR of a wide matrix")
+	}
+
+	if _, err := mat.Solve(singular, mat.Ones[float64](3, 1)); !errors.Is(err, mat.ErrSingular) {
+		t.Errorf("Expected ErrSingular from Solve, found %v", err)
+	}
+	if _, err := mat.Inverse(singular); !errors.Is(err, mat.ErrSingular) {
+		t.Errorf("Expected ErrSingular from Inverse, found %v", err)
+	}
+	if det, err := mat.Det(singular); err != nil || math.Abs(det) > 1e-12 {
+		t.Errorf("Expected det 0 for a singular matrix, found %f, %v", det, err)
+	}
+
+	if _, err := mat.Solve(mat.Id[float64](3), mat.Ones[float64](2, 1)); err == nil {
+		t.Errorf("Expected error for B with the wrong number of rows")
+	}
+}
+
+func second[V any](_ V, err error) error {
+	return err
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func TestCrossEntropyPerSample(t *testing.T) {
+	// one row of binary targets, more samples than rows
+	y := mat.FromValues([]float64{1, 0, 1, 0})
+	y_ := mat.FromValues([]float64{0.9, 0.2, 0.6, 0.5})
+
+	loss := lossfuncs.CrossEntropyLoss[float64]()
+	ce, err := loss.F(y, y_)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{
+		-math.Log(0.9), -math.Log(0.8), -math.Log(0.6), -math.Log(0.5),
+	}), ce, 1e-12))
+
+	dce, err := loss.DF(y, y_)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{-1 / 0.9, 1 / 0.8, -1 / 0.6, 1 / 0.5}), dce, 1e-12))
+}
@@ -94,6 +94,26 @@ func TestSliceAndClone(t *testing.T) {
 	logIfErr(t, expectMatEq(m2, m3))
 }
 
+func TestCopyIntoView(t *testing.T) {
+	m := mat.New2D[float32](3, 3)
+
+	// fills the view, and through it m
+	m.MustSlice(mat.SR{1, 3}, mat.SR{0, 2}).MustCopy(mat.FromValues([]float32{1, 2, 3, 4}).MustReshape(2, 2))
+	// a row broadcast down a transposed view
+	m.TP().MustSlice(mat.SR{2, 3}, mat.SR{0, 3}).MustCopy(mat.FromValues([]float32{7, 8, 9}))
+
+	expected := mat.FromValues([]float32{
+		0, 0, 7,
+		1, 2, 8,
+		3, 4, 9,
+	}).MustReshape(3, 3)
+	logIfErr(t, expectMatEq(expected, m))
+
+	if err := m.Copy(mat.New2D[float32](2, 3)); err == nil {
+		t.Error("Expected error copying [2, 3] into [3, 3], found nil")
+	}
+}
+
 func TestSlicedTranspose(t *testing.T) {
 	m1 := mat.FromValues([]float32{
 		1.0, 2.0, 3.0,
@@ -367,7 +387,7 @@ func TestMatAdd(t *testing.T) {
 	m2 := mat.Ones[float32](2, 2)
 
 	m3 := mat.New2DF32(2, 2)
-	m3, err := mat.Add(m1, mat.Ones[float32](2, 1))
+	m3, err := mat.Add(m1, mat.Ones[float32](3, 1))
 	if err == nil {
 		t.Fatal("Expected error with mismatched dims, found nil")
 	}
@@ -398,7 +418,7 @@ func TestMatAdd(t *testing.T) {
 	logIfErr(t, expectValueAt(m3, 1, 0, 4.0))
 	logIfErr(t, expectValueAt(m3, 1, 1, 4.0))
 
-	m4 := mat.New2DF32(2, 1)
+	m4 := mat.New2DF32(3, 1)
 	if err := m3.Add(m4); err == nil {
 		t.Error("Expected error when adding matrices with mismatched dims, none found")
 	}
@@ -437,7 +457,7 @@ func TestMatSubtract(t *testing.T) {
 	logIfErr(t, expectValueAt(m3, 1, 0, -0.0))
 	logIfErr(t, expectValueAt(m3, 1, 1, -0.0))
 
-	m4 := mat.New2DF32(2, 1)
+	m4 := mat.New2DF32(3, 1)
 	if err = m3.Subtract(m4); err == nil {
 		t.Error("Expected error when adding subtracting with mismatched dims, none found")
 	}
@@ -475,7 +495,7 @@ func TestMatMul(t *testing.T) {
 	logIfErr(t, expectValueAt(m3, 1, 0, 256.0))
 	logIfErr(t, expectValueAt(m3, 1, 1, 36.0*36.0))
 
-	m4 := mat.New2DF32(2, 1)
+	m4 := mat.New2DF32(3, 1)
 	if err := m3.Mul(m4); err == nil {
 		t.Error("Expected error when dotting matrices with mismatched dims, none found")
 	}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"fmt"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func expectMatNear[T mat.Float](m1, m2 *mat.Mat2D[T], tol float64) error {
+	if !mat.DimsMatch(m1, m2) {
+		return fmt.Errorf(
+			"Expected matrices of shape [%d, %d], found [%d, %d]",
+			m1.Rows(), m1.Cols(), m2.Rows(), m2.Cols(),
+		)
+	}
+
+	for i := range m1.Rows() {
+		for j := range m1.Cols() {
+			expected, found := m1.MustGet(i, j), m2.MustGet(i, j)
+			if math.Abs(float64(expected-found)) > tol {
+				return fmt.Errorf(
+					"Expected value of %f at [%d, %d], found %f",
+					expected, i, j, found,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestBlockedMatMulMatchesNaive(t *testing.T) {
+	defer mat.SetMatMulWorkers(mat.MatMulWorkers())
+
+	shapes := [][3]uint64{
+		{1, 1, 1},
+		{3, 5, 7},
+		{64, 64, 64},
+		{65, 130, 67},
+		{150, 33, 129},
+	}
+
+	for _, workers := range []int{1, 4} {
+		mat.SetMatMulWorkers(workers)
+
+		for _, s := range shapes {
+			M, K, N := s[0], s[1], s[2]
+
+			A := mat.Rand[float64](M, K)
+			B := mat.Rand[float64](K, N)
+			At := mat.Rand[float64](K, M).TP()
+			Bt := mat.Rand[float64](N, K).TP()
+
+			cases := map[string][2]*mat.Mat2DF64{
+				"A B":     {A, B},
+				"A^T B":   {At, B},
+				"A B^T":   {A, Bt},
+				"A^T B^T": {At, Bt},
+			}
+
+			for name, c := range cases {
+				expected, err := mat.NaiveMatMul(c[0], c[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				found, err := mat.MatMul(c[0], c[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := expectMatNear(expected, found, 1e-9); err != nil {
+					t.Errorf("workers=%d %v %s: %s", workers, s, name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestBlockedMatMulPropagatesNaN(t *testing.T) {
+	defer mat.SetMatMulWorkers(mat.MatMulWorkers())
+
+	// a zero row of A against NaN and Inf in B gives NaN, as in NaiveMatMul
+	A := mat.Rand[float64](70, 40)
+	for k := range A.Cols() {
+		A.MustSet(3, k, 0)
+	}
+	B := mat.Rand[float64](40, 90)
+	B.MustSet(5, 7, math.NaN())
+	B.MustSet(38, 80, math.Inf(1))
+
+	for _, workers := range []int{1, 4} {
+		mat.SetMatMulWorkers(workers)
+
+		expected, err := mat.NaiveMatMul(A, B)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err := mat.MatMul(A, B)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range expected.Rows() {
+			for j := range expected.Cols() {
+				e, f := expected.MustGet(i, j), found.MustGet(i, j)
+				if math.IsNaN(e) != math.IsNaN(f) || (!math.IsNaN(e) && math.Abs(e-f) > 1e-9) {
+					t.Fatalf("workers=%d: expected %f at [%d, %d], found %f", workers, e, i, j, f)
+				}
+			}
+		}
+		if !math.IsNaN(found.MustGet(3, 7)) || !math.IsNaN(found.MustGet(3, 80)) {
+			t.Errorf("workers=%d: expected NaN from the zero row, found %f and %f",
+				workers, found.MustGet(3, 7), found.MustGet(3, 80))
+		}
+	}
+}
+
+func TestBlockedMatMulSliced(t *testing.T) {
+	big := mat.ARange[float64](20*30).MustReshape(20, 30)
+
+	A := big.MustSlice(mat.RS{2, 9}, mat.CS{3, 14})    // [7, 11]
+	B := big.MustSlice(mat.RS{5, 16}, mat.CS{1, 5})    // [11, 4]
+	Bt := big.MustSlice(mat.RS{10, 14}, mat.CS{0, 11}) // [4, 11]
+
+	for name, c := range map[string][2]*mat.Mat2DF64{
+		"slice slice":   {A, B},
+		"slice slice^T": {A, Bt.TP()},
+		"slice^T slice": {A.TP(), A},
+	} {
+		expected, err := mat.NaiveMatMul(c[0], c[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err := mat.MatMul(c[0], c[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := expectMatNear(expected, found, 0); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+
+	if _, err := mat.MatMul(A, A); err == nil {
+		t.Error("Expected error for mismatched MatMul dims, found nil")
+	}
+}
+
+func benchmarkMatMul(
+	b *testing.B,
+	n uint64,
+	workers int,
+	matMul func(a, b *mat.Mat2DF32) (*mat.Mat2DF32, error),
+) {
+	defer mat.SetMatMulWorkers(mat.MatMulWorkers())
+	mat.SetMatMulWorkers(workers)
+
+	A := mat.RandF32(n, n)
+	B := mat.RandF32(n, n)
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := matMul(A, B); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNaiveMatMul256(b *testing.B) {
+	benchmarkMatMul(b, 256, 1, mat.NaiveMatMul[float32])
+}
+
+func BenchmarkMatMul256(b *testing.B) {
+	benchmarkMatMul(b, 256, 1, mat.MatMul[float32])
+}
+
+func BenchmarkMatMul256Parallel(b *testing.B) {
+	benchmarkMatMul(b, 256, 4, mat.MatMul[float32])
+}
+
+func BenchmarkMatMul512Parallel(b *testing.B) {
+	benchmarkMatMul(b, 512, 4, mat.MatMul[float32])
+}
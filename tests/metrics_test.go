@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/metrics"
+	"gonn/internal/train"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// one-hot targets of classes and predictions scoring 1 for the predicted class
+func newClassBatch(t *testing.T, actual, predicted []int, classes uint64) (y, y_ *mat.Mat2DF64) {
+	t.Helper()
+	y, err := lossfuncs.OneHot[float64](actual, classes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y_, err = lossfuncs.OneHot[float64](predicted, classes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return y, y_
+}
+
+// columns [from, to) of m
+func colsOf(m *mat.Mat2DF64, from, to int64) *mat.Mat2DF64 {
+	return m.MustSlice(mat.RS{0, m.Rows()}, mat.CS{from, to})
+}
+
+func TestClassificationMetrics(t *testing.T) {
+	y, y_ := newClassBatch(t, []int{0, 0, 1, 1, 2, 2, 2}, []int{0, 1, 1, 1, 2, 0, 2}, 3)
+
+	c, err := metrics.ConfusionMatrix(y, y_)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]int{{1, 1, 0}, {0, 2, 0}, {1, 0, 2}}
+	for a := range expected {
+		for p := range expected[a] {
+			if c.Counts[a][p] != expected[a][p] {
+				t.Fatalf("Expected confusion %v, found %v", expected, c.Counts)
+			}
+		}
+	}
+
+	accuracy, err := metrics.Accuracy(y, y_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(5.0/7, accuracy, 1e-12))
+
+	cases := []struct {
+		name     string
+		f        func(y, y_ *mat.Mat2DF64, avg metrics.Average) (float64, error)
+		avg      metrics.Average
+		expected float64
+	}{
+		{"precision", metrics.Precision[float64], metrics.Micro, 5.0 / 7},
+		{"precision", metrics.Precision[float64], metrics.Macro, (0.5 + 2.0/3 + 1) / 3},
+		{"precision", metrics.Precision[float64], metrics.Weighted, (0.5*2 + 2.0/3*2 + 1*3) / 7},
+		{"recall", metrics.Recall[float64], metrics.Macro, (0.5 + 1 + 2.0/3) / 3},
+		{"recall", metrics.Recall[float64], metrics.Weighted, 5.0 / 7},
+		{"f1", metrics.F1[float64], metrics.Micro, 5.0 / 7},
+		{"f1", metrics.F1[float64], metrics.Macro, 0.7},
+		{"f1", metrics.F1[float64], metrics.Weighted, 5.0 / 7},
+	}
+	for _, tc := range cases {
+		found, err := tc.f(y, y_, tc.avg)
+		if err != nil {
+			t.Errorf("%s %s: %s", tc.name, tc.avg, err)
+			continue
+		}
+		if math.Abs(found-tc.expected) > 1e-12 {
+			t.Errorf("%s %s: expected %f, found %f", tc.name, tc.avg, tc.expected, found)
+		}
+	}
+
+	if _, err := metrics.F1(y, y_, metrics.Binary); err == nil {
+		t.Errorf("Expected error for a binary average of 3 classes")
+	}
+}
+
+func TestBinaryMetrics(t *testing.T) {
+	y := mat.FromValues([]float64{1, 1, 0, 0, 1, 0})
+	scores := mat.FromValues([]float64{0.9, 0.4, 0.6, 0.2, 0.8, 0.1})
+
+	// predicted [1, 0, 1, 0, 1, 0]: TP 2, FN 1, FP 1, TN 2
+	for _, f := range []func(y, y_ *mat.Mat2DF64, avg metrics.Average) (float64, error){
+		metrics.Precision[float64], metrics.Recall[float64], metrics.F1[float64],
+	} {
+		binary, err := f(y, scores, metrics.Binary)
+		logIfErr(t, err)
+		logIfErr(t, expectNear(2.0/3, binary, 1e-12))
+	}
+
+	roc, err := metrics.ROC(y, scores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedFPR := []float64{0, 0, 0, 1.0 / 3, 1.0 / 3, 2.0 / 3, 1}
+	expectedTPR := []float64{0, 1.0 / 3, 2.0 / 3, 2.0 / 3, 1, 1, 1}
+	logIfErr(t, expectMatNear(mat.FromValues(expectedFPR), mat.FromValues(roc.FPR), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues(expectedTPR), mat.FromValues(roc.TPR), 1e-12))
+	if !math.IsInf(roc.Thresholds[0], 1) || roc.Thresholds[1] != 0.9 {
+		t.Errorf("Expected thresholds from +Inf through the scores, found %v", roc.Thresholds)
+	}
+
+	// 8 of the 9 positive/negative pairs are ranked correctly
+	auc, err := metrics.ROCAUC(y, scores)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(8.0/9, auc, 1e-12))
+
+	tied, err := metrics.ROCAUC(y, mat.Ones[float64](1, 6))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(0.5, tied, 1e-12))
+
+	if _, err := metrics.ROCAUC(mat.Ones[float64](1, 6), scores); err == nil {
+		t.Errorf("Expected error for ROC without negatives")
+	}
+
+	logLoss, err := metrics.LogLoss(mat.FromValues([]float64{1, 0}), mat.FromValues([]float64{0.8, 0.3}))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(-(math.Log(0.8)+math.Log(0.7))/2, logLoss, 1e-12))
+
+	// a confidently wrong prediction is clipped instead of infinite
+	clipped, err := metrics.LogLoss(mat.FromValues([]float64{1}), mat.FromValues([]float64{0}))
+	logIfErr(t, err)
+	logIfErr(t, expectNear(-math.Log(metrics.LogLossEps), clipped, 1e-9))
+}
+
+func TestTopKAccuracy(t *testing.T) {
+	y, _ := newClassBatch(t, []int{0, 1, 2}, []int{0, 0, 0}, 3)
+	y_ := mat.FromValues([]float64{
+		0.5, 0.1, 0.3,
+		0.3, 0.2, 0.6,
+		0.2, 0.7, 0.1,
+	}).MustReshape(3, 3)
+
+	// target ranks: 1st, 3rd, 2nd
+	for k, expected := range map[int]float64{1: 1.0 / 3, 2: 2.0 / 3, 3: 1} {
+		found, err := metrics.TopKAccuracy(y, y_, k)
+		logIfErr(t, err)
+		logIfErr(t, expectNear(expected, found, 1e-12))
+	}
+
+	if _, err := metrics.TopKAccuracy(y, y_, 4); err == nil {
+		t.Errorf("Expected error for k > classes")
+	}
+}
+
+func TestRegressionMetrics(t *testing.T) {
+	y := mat.FromValues([]float64{3, -0.5, 2, 7})
+	y_ := mat.FromValues([]float64{2.5, 0, 2, 8})
+
+	mae, err := metrics.MAE(y, y_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(0.5, mae, 1e-12))
+
+	rmse, err := metrics.RMSE(y, y_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(math.Sqrt(0.375), rmse, 1e-12))
+
+	r2, err := metrics.R2(y, y_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(0.9486081370449679, r2, 1e-12))
+
+	// outputs are scored separately and averaged, a constant exact output scores 1
+	Y := mat.FromValues([]float64{3, -0.5, 2, 7, 1, 1, 1, 1}).MustReshape(2, 4)
+	Y_ := mat.FromValues([]float64{2.5, 0, 2, 8, 1, 1, 1, 1}).MustReshape(2, 4)
+	r2, err = metrics.R2(Y, Y_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear((0.9486081370449679+1)/2, r2, 1e-12))
+
+	// targets far from zero, 1 - ssRes/ssTot from a two pass sum as the reference
+	rng := rand.New(rand.NewSource(21))
+	big := mat.Normal[float64](rng, 1, 1000, 1e6, 1)
+	big_ := mat.MustAdd(big, mat.Normal[float64](rng, 1, 1000, 0, 0.1))
+	mean := big.Sum() / 1000
+	var ssTot, ssRes float64
+	for j := range big.Cols() {
+		ssTot += (big.MustGet(0, j) - mean) * (big.MustGet(0, j) - mean)
+		ssRes += (big.MustGet(0, j) - big_.MustGet(0, j)) * (big.MustGet(0, j) - big_.MustGet(0, j))
+	}
+	r2, err = metrics.R2(big, big_)
+	logIfErr(t, err)
+	logIfErr(t, expectNear(1-ssRes/ssTot, r2, 1e-9))
+
+	streamed := metrics.NewR2[float64]()
+	for from := int64(0); from < 1000; from += 300 {
+		to := min(from+300, 1000)
+		logIfErr(t, streamed.Update(colsOf(big, from, to), colsOf(big_, from, to)))
+	}
+	logIfErr(t, expectNear(r2, streamed.Value(), 1e-9))
+
+	if _, err := metrics.MAE(y, Y); err == nil {
+		t.Errorf("Expected error for mismatched dims")
+	}
+}
+
+func TestStreamingMetrics(t *testing.T) {
+	y, y_ := newClassBatch(t, []int{0, 0, 1, 1, 2, 2, 2}, []int{0, 1, 1, 1, 2, 0, 2}, 3)
+	y_.Scale(0.9) // still the same classes, but a finite log loss
+	y_.Apply(func(x float64) float64 { return x + 0.05 })
+
+	yReg := mat.FromValues([]float64{3, -0.5, 2, 7, 1, 4, -2})
+	yReg_ := mat.FromValues([]float64{2.5, 0, 2, 8, 1.5, 3, -2})
+
+	yBin := mat.FromValues([]float64{1, 1, 0, 0, 1, 0, 1})
+	yBin_ := mat.FromValues([]float64{0.9, 0.4, 0.6, 0.2, 0.8, 0.1, 0.6})
+
+	cases := []struct {
+		metric train.Metric[float64]
+		y, y_  *mat.Mat2DF64
+		whole  func(y, y_ *mat.Mat2DF64) (float64, error)
+	}{
+		{metrics.NewAccuracy[float64](), y, y_, metrics.Accuracy[float64]},
+		{metrics.NewTopKAccuracy[float64](2), y, y_, func(y, y_ *mat.Mat2DF64) (float64, error) {
+			return metrics.TopKAccuracy(y, y_, 2)
+		}},
+		{metrics.NewLogLoss[float64](), y, y_, metrics.LogLoss[float64]},
+		{metrics.NewF1[float64](metrics.Macro), y, y_, func(y, y_ *mat.Mat2DF64) (float64, error) {
+			return metrics.F1(y, y_, metrics.Macro)
+		}},
+		{metrics.NewPrecision[float64](metrics.Weighted), y, y_, func(y, y_ *mat.Mat2DF64) (float64, error) {
+			return metrics.Precision(y, y_, metrics.Weighted)
+		}},
+		{metrics.NewRecall[float64](metrics.Binary), yBin, yBin_, func(y, y_ *mat.Mat2DF64) (float64, error) {
+			return metrics.Recall(y, y_, metrics.Binary)
+		}},
+		{metrics.NewAUC[float64](), yBin, yBin_, metrics.ROCAUC[float64]},
+		{metrics.NewMAE[float64](), yReg, yReg_, metrics.MAE[float64]},
+		{metrics.NewRMSE[float64](), yReg, yReg_, metrics.RMSE[float64]},
+		{metrics.NewR2[float64](), yReg, yReg_, metrics.R2[float64]},
+	}
+
+	for _, tc := range cases {
+		expected, err := tc.whole(tc.y, tc.y_)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// twice, Reset must forget the first pass
+		for range 2 {
+			tc.metric.Reset()
+			if !math.IsNaN(tc.metric.Value()) {
+				t.Errorf("%s: expected NaN before any batch, found %f", tc.metric.Name(), tc.metric.Value())
+			}
+			for _, cut := range [][2]int64{{0, 3}, {3, 4}, {4, 7}} {
+				logIfErr(t, tc.metric.Update(colsOf(tc.y, cut[0], cut[1]), colsOf(tc.y_, cut[0], cut[1])))
+			}
+		}
+
+		if found := tc.metric.Value(); math.Abs(found-expected) > 1e-12 {
+			t.Errorf("%s: expected %f over batches, found %f", tc.metric.Name(), expected, found)
+		}
+	}
+}
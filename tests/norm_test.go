@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"bytes"
+	"gonn/internal/gradcheck"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"testing"
+)
+
+func TestBatchNormTraining(t *testing.T) {
+	bn := layer.NewBatchNorm[float64](2)
+	x := mat.FromValues([]float64{
+		1, 2, 3, 4,
+		-2, 0, 2, 8,
+	}).MustReshape(2, 4)
+
+	if !bn.IsTraining() {
+		t.Fatal("Expected a new BatchNorm to be in training mode")
+	}
+
+	out, err := bn.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range out.Rows() {
+		mean, sq := 0.0, 0.0
+		for j := range out.Cols() {
+			mean += out.MustGet(i, j) / 4
+			sq += out.MustGet(i, j) * out.MustGet(i, j) / 4
+		}
+		logIfErr(t, expectNear(0, mean, 1e-9))
+		logIfErr(t, expectNear(1, sq, 1e-4))
+	}
+
+	// momentum 0.1 from mean 0, var 1, with unbiased batch variances 5/3 and 56/3
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{0.25, 0.2}).TP(), bn.RunningMean, 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{0.9 + 0.5/3, 0.9 + 5.6/3}).TP(), bn.RunningVar, 1e-12))
+}
+
+func TestBatchNormInference(t *testing.T) {
+	bn := layer.NewBatchNorm[float64](2)
+	bn.RunningMean = mat.FromValues([]float64{1, -1}).TP()
+	bn.RunningVar = mat.FromValues([]float64{4, 1}).TP()
+	bn.Eps = 0
+	bn.SetTraining(false)
+
+	x := mat.FromValues([]float64{3, 5, 0, 1}).MustReshape(2, 2)
+	out, err := bn.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{1, 2, 1, 2}).MustReshape(2, 2), out, 1e-12))
+
+	// inference leaves the running statistics alone
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{1, -1}).TP(), bn.RunningMean))
+
+	bn.SetTraining(true)
+	if !bn.IsTraining() {
+		t.Error("Expected SetTraining(true) to switch back to training mode")
+	}
+}
+
+func TestBatchNormGradients(t *testing.T) {
+	bn := layer.NewBatchNorm[float64](3)
+	bn.W.Apply(func(w float64) float64 { return w + 0.4 })
+	gradcheck.Expect(t, bn, mat.RandF64(3, 5))
+
+	bn.SetTraining(false)
+	bn.RunningVar.Fill(0.5)
+	gradcheck.Expect(t, bn, mat.RandF64(3, 5))
+}
+
+func TestBatchNormSaveLoad(t *testing.T) {
+	bn := layer.NewBatchNorm[float64](3)
+	if _, err := bn.Forward(mat.RandF64(3, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := layer.Save[float64](&buf, bn, layer.NewLayerNorm[float64](3)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := layer.Load[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lbn := loaded[0].(*layer.BatchNorm[float64])
+	logIfErr(t, expectMatEq(bn.RunningMean, lbn.RunningMean))
+	logIfErr(t, expectMatEq(bn.RunningVar, lbn.RunningVar))
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"fmt"
+	"gonn/internal/layer"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/optim"
+	"math"
+	"testing"
+)
+
+// learnable with a fixed gradient, so updates can be checked by hand
+type fixedParam struct {
+	W    *mat.Mat2DF64
+	Grad *mat.Mat2DF64
+}
+
+func (p *fixedParam) IsLearnable() (bool, *mat.Mat2DF64) {
+	return true, p.Grad
+}
+
+func (p *fixedParam) Learn(
+	updateWeights *(func(weights, grad *mat.Mat2DF64) (*mat.Mat2DF64, error)),
+) error {
+	w, err := (*updateWeights)(p.W.Clone(), p.Grad.Clone())
+	if err != nil {
+		return err
+	}
+	p.W = w
+	return nil
+}
+
+func newFixedParam(w, grad float64) *fixedParam {
+	return &fixedParam{
+		W:    mat.FromValues([]float64{w}),
+		Grad: mat.FromValues([]float64{grad}),
+	}
+}
+
+func expectNear(expected, found, tol float64) error {
+	if math.Abs(expected-found) > tol {
+		return fmt.Errorf("Expected %f, found %f", expected, found)
+	}
+	return nil
+}
+
+func stepN(t *testing.T, opt optim.Optimizer[float64], p *fixedParam, n int) {
+	t.Helper()
+	for range n {
+		if err := opt.Step(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSGD(t *testing.T) {
+	p := newFixedParam(1, 2)
+	stepN(t, optim.NewSGD(0.1), p, 2)
+	logIfErr(t, expectNear(0.6, p.W.MustGet(0, 0), 1e-12))
+
+	// v1 = 2, v2 = 0.5*2 + 2 = 3 -> w = 1 - 0.1*2 - 0.1*3
+	p = newFixedParam(1, 2)
+	stepN(t, optim.NewMomentum(0.1, 0.5), p, 2)
+	logIfErr(t, expectNear(0.5, p.W.MustGet(0, 0), 1e-12))
+
+	// g + mu*v: 2 + 0.5*2 = 3, 2 + 0.5*3 = 3.5 -> w = 1 - 0.3 - 0.35
+	p = newFixedParam(1, 2)
+	stepN(t, optim.NewNesterov(0.1, 0.5), p, 2)
+	logIfErr(t, expectNear(0.35, p.W.MustGet(0, 0), 1e-12))
+
+	// L2: g = 2 + 0.5*1 = 2.5
+	p = newFixedParam(1, 2)
+	sgd := optim.NewSGD(0.1)
+	sgd.WeightDecay = 0.5
+	stepN(t, sgd, p, 1)
+	logIfErr(t, expectNear(0.75, p.W.MustGet(0, 0), 1e-12))
+}
+
+func TestRMSProp(t *testing.T) {
+	p := newFixedParam(1, 2)
+	rms := optim.NewRMSProp(0.01)
+	rms.Eps = 0
+	stepN(t, rms, p, 1)
+
+	// s = 0.1 * 4 -> w = 1 - 0.01 * 2 / sqrt(0.4)
+	logIfErr(t, expectNear(1-0.01*2/math.Sqrt(0.4), p.W.MustGet(0, 0), 1e-12))
+}
+
+func TestAdam(t *testing.T) {
+	// bias correction makes every step of a constant gradient ~ Alpha * sign(g)
+	p := newFixedParam(1, 2)
+	stepN(t, optim.NewAdam(0.01), p, 3)
+	logIfErr(t, expectNear(0.97, p.W.MustGet(0, 0), 1e-6))
+
+	p = newFixedParam(1, -3)
+	stepN(t, optim.NewAdam(0.01), p, 1)
+	logIfErr(t, expectNear(1.01, p.W.MustGet(0, 0), 1e-6))
+
+	// AdamW applies decay outside of the moment estimates
+	p = newFixedParam(1, 2)
+	stepN(t, optim.NewAdamW(0.01, 0.5), p, 1)
+	logIfErr(t, expectNear(1-0.01*(1+0.5), p.W.MustGet(0, 0), 1e-6))
+}
+
+func TestOptimizerStatePerParam(t *testing.T) {
+	opt := optim.NewMomentum(0.1, 0.5)
+	a, b := newFixedParam(1, 2), newFixedParam(1, 2)
+
+	stepN(t, opt, a, 1)
+	if err := opt.Step(a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	// a has momentum built up, b has taken a single plain step
+	logIfErr(t, expectNear(0.5, a.W.MustGet(0, 0), 1e-12))
+	logIfErr(t, expectNear(0.8, b.W.MustGet(0, 0), 1e-12))
+
+	opt.SetLR(0.2)
+	if opt.LR() != 0.2 {
+		t.Errorf("Expected LR 0.2, found %f", opt.LR())
+	}
+}
+
+func TestOptimizersTrainLinearModel(t *testing.T) {
+	// y = 2x - 1
+	X := mat.FromValues([]float64{-1, -0.5, 0, 0.5, 1})
+	y := mat.FromValues([]float64{-3, -2, -1, 0, 1})
+
+	optimizers := map[string]func() optim.Optimizer[float64]{
+		"SGD":      func() optim.Optimizer[float64] { return optim.NewSGD(0.1) },
+		"Momentum": func() optim.Optimizer[float64] { return optim.NewMomentum(0.05, 0.9) },
+		"Nesterov": func() optim.Optimizer[float64] { return optim.NewNesterov(0.05, 0.9) },
+		"RMSProp":  func() optim.Optimizer[float64] { return optim.NewRMSProp(0.01) },
+		"Adam":     func() optim.Optimizer[float64] { return optim.NewAdam(0.05) },
+		"AdamW":    func() optim.Optimizer[float64] { return optim.NewAdamW(0.05, 0.0001) },
+	}
+
+	for name, newOpt := range optimizers {
+		model := layer.NewSequential[float64](layer.NewLL[float64](1, 1))
+		opt := newOpt()
+
+		var mse float64
+		for range 500 {
+			y_, err := model.Forward(X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			se, _ := lossfuncs.SquaredError(y, y_)
+			mse = se.Sum() / float64(y_.Cols())
+
+			dse, _ := lossfuncs.DSquaredError(y, y_)
+			if _, err := model.Backward(dse); err != nil {
+				t.Fatal(err)
+			}
+			if err := opt.Step(model.Params()...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if mse > 1e-3 {
+			t.Errorf("%s: expected MSE to converge below 1e-3, found %f", name, mse)
+		}
+	}
+}
+
+func TestOptimizersStepContainers(t *testing.T) {
+	// two same shaped children, one nested, so shared state would show
+	newModel := func() *layer.Sequential[float64] {
+		l1, l2 := layer.NewLL[float64](2, 2), layer.NewLL[float64](2, 2)
+		l1.W = mat.ARange[float64](6).MustReshape(2, 3).Scale(0.1)
+		l2.W = mat.ARange[float64](6).MustReshape(2, 3).Scale(-0.2)
+		return layer.NewSequential[float64](l1, newSigmoidAL(), layer.NewSequential[float64](l2))
+	}
+	X := mat.FromValues([]float64{1, -1, 0.5, 2, 0, -0.5}).MustReshape(2, 3)
+	y := mat.FromValues([]float64{0, 1, 1, 0, 1, 0}).MustReshape(2, 3)
+
+	for name, newOpt := range map[string]func() optim.Optimizer[float64]{
+		"Momentum": func() optim.Optimizer[float64] { return optim.NewMomentum(0.05, 0.9) },
+		"RMSProp":  func() optim.Optimizer[float64] { return optim.NewRMSProp(0.01) },
+		"Adam":     func() optim.Optimizer[float64] { return optim.NewAdam(0.05) },
+	} {
+		whole, flat := newModel(), newModel()
+		wholeOpt, flatOpt := newOpt(), newOpt()
+
+		for range 5 {
+			for _, run := range []struct {
+				model *layer.Sequential[float64]
+				step  func() error
+			}{
+				{whole, func() error { return wholeOpt.Step(whole) }},
+				{flat, func() error { return flatOpt.Step(flat.Params()...) }},
+			} {
+				y_, err := run.model.Forward(X)
+				if err != nil {
+					t.Fatal(err)
+				}
+				dse, _ := lossfuncs.DSquaredError(y, y_)
+				if _, err := run.model.Backward(dse); err != nil {
+					t.Fatal(err)
+				}
+				if err := run.step(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		wholeParams, flatParams := whole.Params(), flat.Params()
+		for i := range flatParams {
+			w1 := wholeParams[i].(*layer.LinearLayer[float64]).W
+			w2 := flatParams[i].(*layer.LinearLayer[float64]).W
+			if err := expectMatNear(w2, w1, 1e-12); err != nil {
+				t.Errorf("%s: Step(model) differs from Step(model.Params()...) at param %d, %s", name, i, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/optim"
+	"gonn/internal/weightinit"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSeedReproducible(t *testing.T) {
+	mat.Seed(3)
+	a := mat.RandF32(4, 5)
+	mat.Seed(3)
+	b := mat.RandF32(4, 5)
+	logIfErr(t, expectMatEq(a, b))
+
+	// injected sources are independent of the default
+	x := mat.Normal[float64](rand.New(rand.NewSource(9)), 3, 3, 0, 1)
+	mat.Rand[float64](10, 10)
+	y := mat.Normal[float64](rand.New(rand.NewSource(9)), 3, 3, 0, 1)
+	logIfErr(t, expectMatEq(x, y))
+}
+
+func TestGenerators(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	mean, std, _ := moments(mat.Normal[float64](rng, 100, 100, 2, 3))
+	logIfErr(t, expectNear(2, mean, 0.1))
+	logIfErr(t, expectNear(3, std, 0.1))
+
+	_, _, maxAbs := moments(mat.TruncatedNormal[float64](rng, 100, 100, 0, 1.5))
+	if maxAbs > 3 {
+		t.Errorf("Expected TruncatedNormal within 2 std (3), found %f", maxAbs)
+	}
+
+	u := mat.Uniform[float32](rng, 100, 100, -1, 1)
+	for i := range u.Rows() {
+		for j := range u.Cols() {
+			if v := u.MustGet(i, j); v < -1 || v >= 1 {
+				t.Fatalf("Expected Uniform within [-1, 1), found %f", v)
+			}
+		}
+	}
+
+	b := mat.Bernoulli[float64](rng, 100, 100, 0.3)
+	logIfErr(t, expectNear(0.3, b.Sum()/10000, 0.02))
+
+	perm := mat.Perm(rng, 10)
+	sorted := slices.Clone(perm)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("Expected a permutation of [0, 10), found %v", perm)
+	}
+}
+
+func TestSeededTrainingReproducible(t *testing.T) {
+	X := mat.FromValues([]float64{0, 1, 0, 1, 0, 0, 1, 1}).MustReshape(2, 4)
+	y := mat.FromValues([]float64{0, 1, 1, 0})
+
+	train := func(seed int64) *mat.Mat2DF64 {
+		rng := rand.New(rand.NewSource(seed))
+
+		dropout, err := layer.NewDropout[float64](0.2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropout.RNG = rng
+
+		model := layer.NewSequential[float64](
+			layer.NewLL[float64](2, 4),
+			newSigmoidAL(),
+			dropout,
+			layer.NewLL[float64](4, 1),
+			newSigmoidAL(),
+		)
+		model.Init(weightinit.XavierUniform[float64], weightinit.Zeros[float64], rng)
+
+		opt := optim.NewAdam[float64](0.05)
+		for range 50 {
+			y_, err := model.Forward(X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dse, err := lossfuncs.DSquaredError(y, y_)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := model.Backward(dse); err != nil {
+				t.Fatal(err)
+			}
+			if err := opt.Step(model.Params()...); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		model.SetTraining(false)
+		out, err := model.Forward(X)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	a, b := train(5), train(5)
+	logIfErr(t, expectMatEq(a, b))
+
+	if c := train(6); mat.Equals(a, c) {
+		t.Error("Expected a different seed to give a different model")
+	}
+	if math.IsNaN(a.Sum()) {
+		t.Error("Expected finite outputs")
+	}
+}
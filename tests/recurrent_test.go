@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"bytes"
+	"gonn/internal/gradcheck"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"testing"
+)
+
+func TestRecurrentGradients(t *testing.T) {
+	const steps, I, H, N = 4, 3, 2, 2
+
+	rnn := layer.NewRNN[float64](I, H)
+	lstm := layer.NewLSTM[float64](I, H)
+	gru := layer.NewGRU[float64](I, H)
+
+	for name, tc := range map[string]struct {
+		l layer.Layer[float64]
+		W *mat.Mat2DF64
+	}{
+		"RNN":  {rnn, rnn.W},
+		"LSTM": {lstm, lstm.W},
+		"GRU":  {gru, gru.W},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.W.Apply(func(w float64) float64 { return w - 0.5 })
+			x := mat.RandF64(steps*I, N).Apply(func(v float64) float64 { return 2*v - 1 })
+			gradcheck.Expect(t, tc.l, x)
+		})
+	}
+}
+
+func TestBidirectionalGradients(t *testing.T) {
+	bi, err := layer.NewBidirectional[float64](
+		layer.NewLSTM[float64](3, 2),
+		layer.NewGRU[float64](3, 4),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bi.OSize() != 6 {
+		t.Errorf("Expected OSize 6, found %d", bi.OSize())
+	}
+
+	x := mat.RandF64(5*3, 2)
+	gradcheck.Expect(t, bi, x)
+}
+
+func TestBidirectionalReversesBackward(t *testing.T) {
+	fwd, bwd := layer.NewRNN[float64](2, 3), layer.NewRNN[float64](2, 3)
+	bi, err := layer.NewBidirectional[float64](fwd, bwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xs := []*mat.Mat2DF64{mat.RandF64(2, 1), mat.RandF64(2, 1), mat.RandF64(2, 1)}
+	out, err := bi.ForwardSeq(xs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rev, err := bwd.ForwardSeq([]*mat.Mat2DF64{xs[2], xs[1], xs[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step := range xs {
+		found := out[step].MustSlice(mat.RS{3, 6}, mat.CS{0, 1})
+		logIfErr(t, expectMatNear(rev[len(xs)-1-step], found, 1e-12))
+	}
+
+	if _, err := layer.NewBidirectional[float64](fwd, layer.NewRNN[float64](3, 3)); err == nil {
+		t.Error("Expected error for mismatched input sizes")
+	}
+}
+
+func TestTruncatedBPTT(t *testing.T) {
+	const steps, I, H = 4, 2, 3
+
+	gru := layer.NewGRU[float64](I, H)
+	x := mat.RandF64(steps*I, 1)
+
+	// loss only at the last step
+	loss := mat.New2DF64(steps*H, 1)
+	loss.MustSlice(mat.RS{(steps - 1) * H, steps * H}, mat.CS{0, 1}).Fill(1)
+
+	gradAt := func(tbptt int) *mat.Mat2DF64 {
+		gru.TBPTT = tbptt
+		if _, err := gru.Forward(x); err != nil {
+			t.Fatal(err)
+		}
+		dx, err := gru.Backward(loss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dx
+	}
+
+	full, truncated := gradAt(0), gradAt(2)
+
+	for i := range int64(steps * I) {
+		step := i / I
+		if step < 2 && truncated.MustGet(i, 0) != 0 {
+			t.Errorf("Expected no gradient before the last chunk, found dx[%d] = %f", i, truncated.MustGet(i, 0))
+		}
+		if step >= 2 && truncated.MustGet(i, 0) != full.MustGet(i, 0) {
+			t.Errorf("Expected dx[%d] = %f within the last chunk, found %f", i, full.MustGet(i, 0), truncated.MustGet(i, 0))
+		}
+	}
+	if full.MustGet(0, 0) == 0 {
+		t.Error("Expected full BPTT to reach the first step")
+	}
+}
+
+func TestStatefulCarriesState(t *testing.T) {
+	const I, H, N = 2, 3, 2
+
+	lstm := layer.NewLSTM[float64](I, H)
+	x := mat.RandF64(4*I, N)
+
+	whole, err := lstm.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	whole = whole.Clone()
+
+	lstm.Stateful = true
+	first, err := lstm.Forward(x.MustSlice(mat.RS{0, 2 * I}, mat.CS{0, N}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(whole.MustSlice(mat.RS{0, 2 * H}, mat.CS{0, N}), first, 1e-12))
+
+	second, err := lstm.Forward(x.MustSlice(mat.RS{2 * I, 4 * I}, mat.CS{0, N}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(whole.MustSlice(mat.RS{2 * H, 4 * H}, mat.CS{0, N}), second, 1e-12))
+
+	if _, err := lstm.Forward(mat.RandF64(I, N+1)); err == nil {
+		t.Error("Expected error when the batch size changes while stateful")
+	}
+
+	lstm.ResetState()
+	fresh, err := lstm.Forward(x.MustSlice(mat.RS{0, 2 * I}, mat.CS{0, N}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(first, fresh, 1e-12))
+}
+
+func TestRecurrentErrors(t *testing.T) {
+	rnn := layer.NewRNN[float64](3, 2)
+
+	if _, err := rnn.Forward(mat.RandF64(4, 1)); err == nil {
+		t.Error("Expected error for input rows not a multiple of ISize")
+	}
+	if _, err := rnn.ForwardSeq(nil); err == nil {
+		t.Error("Expected error for empty sequence")
+	}
+	if _, err := layer.NewGRU[float64](3, 2).Backward(mat.RandF64(2, 1)); err == nil {
+		t.Error("Expected error for Backward before Forward")
+	}
+}
+
+func TestRecurrentSaveLoad(t *testing.T) {
+	lstm := layer.NewLSTM[float64](3, 4)
+	lstm.TBPTT = 5
+	bi, err := layer.NewBidirectional[float64](lstm, layer.NewGRU[float64](3, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := layer.NewSequential[float64](bi, layer.NewRNN[float64](6, 2))
+
+	x := mat.RandF64(3*3, 2)
+	expected, err := model.Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := layer.Save(&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := layer.Load[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := loaded[0].Forward(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(expected, found))
+
+	loadedBi := loaded[0].(*layer.Sequential[float64]).Layers[0].(*layer.Bidirectional[float64])
+	if tbptt := loadedBi.Fwd.(*layer.LSTM[float64]).TBPTT; tbptt != 5 {
+		t.Errorf("Expected TBPTT 5 after load, found %d", tbptt)
+	}
+}
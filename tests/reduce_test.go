@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"gonn/internal/mat"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func expectShape[T mat.Float](t *testing.T, name string, m *mat.Mat2D[T], rows, cols int64) {
+	t.Helper()
+	if m.Rows() != rows || m.Cols() != cols {
+		t.Errorf("Expected %s with shape [%d, %d], found [%d, %d]", name, rows, cols, m.Rows(), m.Cols())
+	}
+}
+
+func TestReduceKnown(t *testing.T) {
+	// [[1, 2, 3],
+	//  [4, 6, 8]]
+	a := mat.FromValues([]float64{1, 2, 3, 4, 6, 8}).MustReshape(2, 3)
+
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{5, 8, 11}), a.MustSumAxis(mat.AxisRows, true)))
+	logIfErr(t, expectMatEq(a.SumRows(), a.MustSumAxis(mat.AxisRows, true)))
+	logIfErr(t, expectMatEq(a.SumCols(), a.MustSumAxis(mat.AxisCols, true)))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{24}), a.MustSumAxis(mat.AxisAll, false)))
+
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{2.5, 4, 5.5}), a.MustMean(mat.AxisRows, true)))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{2, 6}).TP(), a.MustMean(mat.AxisCols, true)))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{4}), a.MustMean(mat.AxisAll, true)))
+
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{4, 6, 8}), a.MustMax(mat.AxisRows, true)))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{1, 4}).TP(), a.MustMin(mat.AxisCols, true)))
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{8}), a.MustMax(mat.AxisAll, true)))
+
+	// population variance
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{2.25, 4, 6.25}), a.MustVar(mat.AxisRows, true), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{2.0 / 3, 8.0 / 3}).TP(), a.MustVar(mat.AxisCols, true), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{1.5, 2, 2.5}), a.MustStd(mat.AxisRows, true), 1e-12))
+
+	// keeps accuracy far from zero
+	shifted := mat.FromValues([]float64{1e9 + 1, 1e9 + 2, 1e9 + 3})
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{2.0 / 3}), shifted.MustVar(mat.AxisCols, true), 1e-6))
+
+	if idx := a.MustArgMax(mat.AxisRows); !slices.Equal(idx, []int{1, 1, 1}) {
+		t.Errorf("Expected ArgMax(AxisRows) [1 1 1], found %v", idx)
+	}
+	if idx := a.MustArgMin(mat.AxisCols); !slices.Equal(idx, []int{0, 0}) {
+		t.Errorf("Expected ArgMin(AxisCols) [0 0], found %v", idx)
+	}
+	if idx := a.MustArgMax(mat.AxisAll); !slices.Equal(idx, []int{5}) {
+		t.Errorf("Expected ArgMax(AxisAll) [5], found %v", idx)
+	}
+
+	// first index on ties
+	b := mat.FromValues([]float64{3, 1, 3, 1})
+	if idx := b.MustArgMax(mat.AxisCols); !slices.Equal(idx, []int{0}) {
+		t.Errorf("Expected ArgMax [0], found %v", idx)
+	}
+	if idx := b.MustArgMin(mat.AxisCols); !slices.Equal(idx, []int{1}) {
+		t.Errorf("Expected ArgMin [1], found %v", idx)
+	}
+
+	// NaNs propagate, the Arg reductions point at the first one
+	c := mat.FromValues([]float64{3, math.NaN(), 5, math.NaN()})
+	if v := c.MustMax(mat.AxisAll, false).MustGet(0, 0); !math.IsNaN(v) {
+		t.Errorf("Expected Max NaN, found %f", v)
+	}
+	if v := c.MustMin(mat.AxisAll, false).MustGet(0, 0); !math.IsNaN(v) {
+		t.Errorf("Expected Min NaN, found %f", v)
+	}
+	if idx := c.MustArgMax(mat.AxisCols); !slices.Equal(idx, []int{1}) {
+		t.Errorf("Expected ArgMax [1], found %v", idx)
+	}
+	if idx := c.MustArgMin(mat.AxisAll); !slices.Equal(idx, []int{1}) {
+		t.Errorf("Expected ArgMin [1], found %v", idx)
+	}
+}
+
+func TestReduceKeepDims(t *testing.T) {
+	a := mat.Ones[float64](3, 5)
+
+	for _, f := range []struct {
+		name   string
+		reduce func(mat.Axis, bool) *mat.Mat2DF64
+	}{
+		{"SumAxis", a.MustSumAxis},
+		{"Mean", a.MustMean},
+		{"Max", a.MustMax},
+		{"Min", a.MustMin},
+		{"Var", a.MustVar},
+		{"Std", a.MustStd},
+		{"LogSumExp", a.MustLogSumExp},
+		{"Norm(L2)", func(axis mat.Axis, keepDims bool) *mat.Mat2DF64 { return a.MustNorm(mat.L2, axis, keepDims) }},
+	} {
+		expectShape(t, f.name+"(AxisRows, true)", f.reduce(mat.AxisRows, true), 1, 5)
+		expectShape(t, f.name+"(AxisRows, false)", f.reduce(mat.AxisRows, false), 5, 1)
+		expectShape(t, f.name+"(AxisCols, true)", f.reduce(mat.AxisCols, true), 3, 1)
+		expectShape(t, f.name+"(AxisCols, false)", f.reduce(mat.AxisCols, false), 3, 1)
+		expectShape(t, f.name+"(AxisAll, true)", f.reduce(mat.AxisAll, true), 1, 1)
+		expectShape(t, f.name+"(AxisAll, false)", f.reduce(mat.AxisAll, false), 1, 1)
+	}
+
+	// a kept dimension broadcasts back over the matrix
+	rng := rand.New(rand.NewSource(11))
+	x := mat.Normal[float64](rng, 4, 6, 3, 2)
+	centered := mat.MustSubtract(x, x.MustMean(mat.AxisCols, true))
+	logIfErr(t, expectMatNear(mat.New2DF64(4, 1), centered.MustMean(mat.AxisCols, true), 1e-12))
+}
+
+func TestReduceViews(t *testing.T) {
+	rng := rand.New(rand.NewSource(12))
+	base := mat.Normal[float64](rng, 6, 7, 0, 1)
+
+	for name, view := range map[string]*mat.Mat2DF64{
+		"transposed":        base.TP(),
+		"sliced":            base.MustSlice(mat.RS{1, 5}, mat.CS{2, 6}),
+		"sliced transposed": base.TP().MustSlice(mat.RS{0, 3}, mat.CS{1, 6}),
+		"transposed slice":  base.MustSlice(mat.RS{2, 6}, mat.CS{0, 5}).TP(),
+	} {
+		dense := view.Clone()
+		for _, axis := range []mat.Axis{mat.AxisRows, mat.AxisCols, mat.AxisAll} {
+			for _, keepDims := range []bool{true, false} {
+				for reduction, pair := range map[string][2]*mat.Mat2DF64{
+					"SumAxis":   {view.MustSumAxis(axis, keepDims), dense.MustSumAxis(axis, keepDims)},
+					"Mean":      {view.MustMean(axis, keepDims), dense.MustMean(axis, keepDims)},
+					"Max":       {view.MustMax(axis, keepDims), dense.MustMax(axis, keepDims)},
+					"Min":       {view.MustMin(axis, keepDims), dense.MustMin(axis, keepDims)},
+					"Var":       {view.MustVar(axis, keepDims), dense.MustVar(axis, keepDims)},
+					"LogSumExp": {view.MustLogSumExp(axis, keepDims), dense.MustLogSumExp(axis, keepDims)},
+					"L1":        {view.MustNorm(mat.L1, axis, keepDims), dense.MustNorm(mat.L1, axis, keepDims)},
+					"L2":        {view.MustNorm(mat.L2, axis, keepDims), dense.MustNorm(mat.L2, axis, keepDims)},
+					"Inf":       {view.MustNorm(mat.Inf, axis, keepDims), dense.MustNorm(mat.Inf, axis, keepDims)},
+				} {
+					if err := expectMatNear(pair[1], pair[0], 1e-12); err != nil {
+						t.Errorf("%s %s(%s, %t): %s", name, reduction, axis, keepDims, err)
+					}
+				}
+			}
+			if !slices.Equal(dense.MustArgMax(axis), view.MustArgMax(axis)) || !slices.Equal(dense.MustArgMin(axis), view.MustArgMin(axis)) {
+				t.Errorf("%s: expected the ArgMax / ArgMin(%s) of the dense copy", name, axis)
+			}
+		}
+	}
+
+	// the transpose swaps the axes
+	logIfErr(t, expectMatEq(base.MustMean(mat.AxisCols, false), base.TP().MustMean(mat.AxisRows, false)))
+	if !slices.Equal(base.MustArgMax(mat.AxisRows), base.TP().MustArgMax(mat.AxisCols)) {
+		t.Errorf("Expected ArgMax(AxisRows) of m to match ArgMax(AxisCols) of its transpose")
+	}
+}
+
+func TestNorms(t *testing.T) {
+	// [[ 1, -2],
+	//  [-3,  4]]
+	a := mat.FromValues([]float64{1, -2, -3, 4}).MustReshape(2, 2)
+
+	// vector norms per lane
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{4, 6}), a.MustNorm(mat.L1, mat.AxisRows, true), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{math.Sqrt(5), 5}).TP(), a.MustNorm(mat.L2, mat.AxisCols, true), 1e-12))
+	logIfErr(t, expectMatNear(a.MustNorm(mat.L2, mat.AxisCols, true), a.MustNorm(mat.Frobenius, mat.AxisCols, true), 0))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{3, 4}), a.MustNorm(mat.Inf, mat.AxisRows, true), 1e-12))
+
+	// matrix norms over AxisAll
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{6}), a.MustNorm(mat.L1, mat.AxisAll, true), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{7}), a.MustNorm(mat.Inf, mat.AxisAll, true), 1e-12))
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{math.Sqrt(30)}), a.MustNorm(mat.Frobenius, mat.AxisAll, true), 1e-12))
+
+	// spectral norm of [[1, -2], [-3, 4]]: sqrt(15 + sqrt(221))
+	logIfErr(t, expectMatNear(
+		mat.FromValues([]float64{math.Sqrt(15 + math.Sqrt(221))}), a.MustNorm(mat.L2, mat.AxisAll, true), 1e-12,
+	))
+
+	// the 1-norm of A is the Inf norm of A^T
+	rng := rand.New(rand.NewSource(13))
+	b := mat.Normal[float64](rng, 5, 3, 0, 1)
+	logIfErr(t, expectMatNear(b.MustNorm(mat.L1, mat.AxisAll, true), b.TP().MustNorm(mat.Inf, mat.AxisAll, true), 1e-12))
+
+	// Frobenius never overflows on large entries
+	big := mat.FromValues([]float64{3e200, 4e200})
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{5}), big.MustNorm(mat.Frobenius, mat.AxisAll, true).Scale(1e-200), 1e-12))
+
+	for _, kind := range []mat.Norm{mat.L1, mat.L2, mat.Frobenius, mat.Inf} {
+		logIfErr(t, expectMatEq(mat.FromValues([]float64{0}), mat.New2DF64(0, 0).MustNorm(kind, mat.AxisAll, true)))
+	}
+}
+
+func TestLogSumExp(t *testing.T) {
+	a := mat.FromValues([]float64{0, math.Log(3), 1, 2}).MustReshape(2, 2)
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{math.Log(4), math.Log(math.E + math.E*math.E)}).TP(),
+		a.MustLogSumExp(mat.AxisCols, true), 1e-12))
+
+	// shifted by the max, no overflow or underflow
+	huge := mat.FromValues([]float64{1000, 1000, -1000, -1000}).MustReshape(2, 2)
+	logIfErr(t, expectMatNear(
+		mat.FromValues([]float64{1000 + math.Ln2, -1000 + math.Ln2}).TP(), huge.MustLogSumExp(mat.AxisCols, true), 1e-12,
+	))
+
+	// ±Inf lanes, and the empty sum
+	inf := mat.FromValues([]float64{math.Inf(-1), math.Inf(-1), math.Inf(1), 0}).MustReshape(2, 2)
+	lse := inf.MustLogSumExp(mat.AxisCols, false)
+	if !math.IsInf(lse.MustGet(0, 0), -1) || !math.IsInf(lse.MustGet(1, 0), 1) {
+		t.Errorf("Expected [-Inf, +Inf], found %v", lse)
+	}
+	if v := mat.New2DF64(0, 0).MustLogSumExp(mat.AxisAll, true).MustGet(0, 0); !math.IsInf(v, -1) {
+		t.Errorf("Expected -Inf for an empty LogSumExp, found %f", v)
+	}
+
+	// log softmax sums to one
+	rng := rand.New(rand.NewSource(14))
+	x := mat.Normal[float64](rng, 4, 5, 0, 10)
+	logSoftmax := mat.MustSubtract(x, x.MustLogSumExp(mat.AxisRows, true))
+	sums := mat.New2DF64(1, 5)
+	for i := range logSoftmax.Rows() {
+		for j := range logSoftmax.Cols() {
+			sums.MustSet(0, j, sums.MustGet(0, j)+math.Exp(logSoftmax.MustGet(i, j)))
+		}
+	}
+	logIfErr(t, expectMatNear(mat.Ones[float64](1, 5), sums, 1e-12))
+}
+
+func TestReduceErrors(t *testing.T) {
+	a := mat.Ones[float64](2, 3)
+	badAxis := mat.Axis(7)
+
+	for name, reduce := range map[string]func(mat.Axis, bool) (*mat.Mat2DF64, error){
+		"SumAxis":   a.SumAxis,
+		"Mean":      a.Mean,
+		"Max":       a.Max,
+		"Min":       a.Min,
+		"Var":       a.Var,
+		"Std":       a.Std,
+		"LogSumExp": a.LogSumExp,
+	} {
+		if res, err := reduce(badAxis, true); err == nil {
+			t.Errorf("Expected %s to fail on %s, found %v", name, badAxis, res)
+		}
+	}
+	if _, err := a.ArgMax(badAxis); err == nil {
+		t.Errorf("Expected ArgMax to fail on %s", badAxis)
+	}
+	if _, err := a.ArgMin(badAxis); err == nil {
+		t.Errorf("Expected ArgMin to fail on %s", badAxis)
+	}
+	if _, err := a.Norm(mat.L2, badAxis, true); err == nil {
+		t.Errorf("Expected Norm to fail on %s", badAxis)
+	}
+	for _, axis := range []mat.Axis{mat.AxisRows, mat.AxisAll} {
+		if _, err := a.Norm(mat.Norm(9), axis, true); err == nil {
+			t.Errorf("Expected Norm(9) to fail on %s", axis)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"gonn/internal/optim"
+	"math"
+	"testing"
+)
+
+func expectSchedule(t *testing.T, name string, s optim.Schedule, expected map[int]float64) {
+	t.Helper()
+	for step, lr := range expected {
+		if err := expectNear(lr, s.At(step), 1e-12); err != nil {
+			t.Errorf("%s at step %d: %s", name, step, err)
+		}
+	}
+}
+
+func TestStepDecay(t *testing.T) {
+	expectSchedule(t, "StepDecay", optim.NewStepDecay(1, 0.5, 10), map[int]float64{
+		0: 1, 9: 1, 10: 0.5, 19: 0.5, 20: 0.25, 35: 0.125,
+	})
+}
+
+func TestExponentialDecay(t *testing.T) {
+	expectSchedule(t, "ExponentialDecay", optim.NewExponentialDecay(2, 0.9), map[int]float64{
+		0: 2, 1: 1.8, 2: 1.62, 10: 2 * math.Pow(0.9, 10),
+	})
+}
+
+func TestCosineAnnealing(t *testing.T) {
+	expectSchedule(t, "Cosine", optim.NewCosineAnnealing(1, 0, 10, 1), map[int]float64{
+		0: 1, 5: 0.5, 10: 1, 15: 0.5, 20: 1,
+	})
+
+	// cycles of 4, 8, 16 starting at steps 0, 4, 12
+	expectSchedule(t, "CosineWarmRestarts", optim.NewCosineAnnealing(1, 0.1, 4, 2), map[int]float64{
+		0: 1, 2: 0.55, 4: 1, 8: 0.55, 12: 1, 20: 0.55,
+	})
+}
+
+func TestLinearWarmup(t *testing.T) {
+	after := optim.NewStepDecay(1, 0.1, 5)
+	expectSchedule(t, "Warmup", optim.NewLinearWarmup(4, 0, after), map[int]float64{
+		0: 0, 1: 0.25, 2: 0.5, 3: 0.75, 4: 1, 8: 1, 9: 0.1,
+	})
+}
+
+func TestOneCycle(t *testing.T) {
+	s := optim.NewOneCycle(1, 100)
+	initial := 1.0 / 25
+	final := initial / 1e4
+
+	expectSchedule(t, "OneCycle", s, map[int]float64{
+		0:   initial,
+		15:  (1 + initial) / 2,
+		30:  1,
+		65:  (1 + final) / 2,
+		100: final,
+		200: final,
+	})
+
+	// monotonic up then down
+	for step := 1; step < 100; step++ {
+		prev, curr := s.At(step-1), s.At(step)
+		if step <= 30 && curr < prev {
+			t.Errorf("Expected OneCycle to increase at step %d", step)
+		}
+		if step > 30 && curr > prev {
+			t.Errorf("Expected OneCycle to decrease at step %d", step)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	opt := optim.NewSGD(1.0)
+	sched := optim.NewScheduler[float64](opt, optim.NewExponentialDecay(0.5, 0.5))
+
+	logIfErr(t, expectNear(0.5, opt.LR(), 1e-12))
+
+	sched.Step()
+	sched.Step()
+	logIfErr(t, expectNear(0.125, opt.LR(), 1e-12))
+
+	if sched.StepCount() != 2 {
+		t.Errorf("Expected step count 2, found %d", sched.StepCount())
+	}
+}
+
+func TestReduceOnPlateau(t *testing.T) {
+	opt := optim.NewAdam(1.0)
+	plateau, err := optim.NewReduceOnPlateau[float64](opt, 0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plateau.Min = 0.3
+
+	losses := []float64{1.0, 0.9, 0.95, 0.9, 0.91, 0.8, 0.8, 0.8, 0.8, 0.8, 0.8, 0.8, 0.8}
+	expectedLRs := []float64{1, 1, 1, 1, 0.5, 0.5, 0.5, 0.5, 0.3, 0.3, 0.3, 0.3, 0.3}
+
+	for i, loss := range losses {
+		plateau.Step(loss)
+		if err := expectNear(expectedLRs[i], opt.LR(), 1e-12); err != nil {
+			t.Errorf("After loss[%d] = %f: %s", i, loss, err)
+		}
+	}
+
+	// negative losses, e.g. a log-likelihood term, still improve downwards
+	opt = optim.NewAdam(1.0)
+	plateau, err = optim.NewReduceOnPlateau[float64](opt, 0.5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, loss := range []float64{-1, -2, -3, -4, -5} {
+		if plateau.Step(loss) {
+			t.Errorf("Expected loss[%d] = %f to count as an improvement, the LR was reduced", i, loss)
+		}
+	}
+	// -4.9999 is worse than -5, not an improvement within the threshold
+	plateau.Step(-4.9999)
+	if !plateau.Step(-4.9999) {
+		t.Errorf("Expected a reduction after Patience steps without improvement")
+	}
+
+	if _, err := optim.NewReduceOnPlateau[float64](opt, 1.5, 2); err == nil {
+		t.Error("Expected error for factor outside (0, 1), found nil")
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"strings"
+	"testing"
+)
+
+func newSigmoidAL() *layer.ActivationLayer[float64] {
+	return layer.NewAL(
+		acti.NewAF[float64](acti.Sigmoid),
+		acti.NewAF[float64](acti.DSigmoid),
+	)
+}
+
+func TestSequentialForwardBackward(t *testing.T) {
+	l1 := layer.NewLL[float64](3, 2)
+	a1 := newSigmoidAL()
+	l2 := layer.NewLL[float64](2, 1)
+
+	model := layer.NewSequential[float64](l1, a1, l2)
+	X := mat.ARange[float64](6).MustReshape(3, 2)
+
+	out, err := model.Forward(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chaining manually must give the same result
+	h, _ := l1.Forward(X)
+	h, _ = a1.Forward(h)
+	expected, _ := l2.Forward(h)
+	logIfErr(t, expectMatEq(expected, out))
+
+	back, err := model.Backward(mat.Ones[float64](1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Rows() != 3 || back.Cols() != 2 {
+		t.Errorf("Expected input gradient of shape [3, 2], found [%d, %d]", back.Rows(), back.Cols())
+	}
+
+	for i, p := range model.Params() {
+		if _, grad := p.IsLearnable(); grad == nil {
+			t.Errorf("Expected gradient for param %d after Backward", i)
+		}
+	}
+}
+
+func TestSequentialParams(t *testing.T) {
+	inner := layer.NewSequential[float64](layer.NewLL[float64](2, 2), newSigmoidAL())
+	model := layer.NewSequential[float64](
+		layer.NewLL[float64](2, 2),
+		newSigmoidAL(),
+		inner,
+	)
+
+	if n := len(model.Params()); n != 2 {
+		t.Errorf("Expected 2 learnable params, found %d", n)
+	}
+
+	learnable, _ := model.IsLearnable()
+	if !learnable {
+		t.Error("Expected model to be learnable")
+	}
+
+	frozen := layer.NewSequential[float64](newSigmoidAL())
+	if learnable, _ := frozen.IsLearnable(); learnable {
+		t.Error("Expected model of activations to be unlearnable")
+	}
+}
+
+func TestSequentialLearn(t *testing.T) {
+	l1 := layer.NewLL[float64](2, 1)
+	model := layer.NewSequential[float64](l1, newSigmoidAL())
+
+	if _, err := model.Forward(mat.Ones[float64](2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.Backward(mat.Ones[float64](1, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	zero := func(weights, grad *mat.Mat2DF64) (*mat.Mat2DF64, error) {
+		return weights.Fill(0), nil
+	}
+	if err := model.Learn(&zero); err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.New2DF64(1, 3), l1.W))
+}
+
+func TestSequentialErrors(t *testing.T) {
+	model := layer.NewSequential[float64](
+		layer.NewLL[float64](2, 2),
+		layer.NewLL[float64](3, 1),
+	)
+
+	_, err := model.Forward(mat.Ones[float64](2, 4))
+	if err == nil {
+		t.Fatal("Expected error forwarding mismatched layers, found nil")
+	}
+	if !strings.Contains(err.Error(), "layer[2 of 2]") || !strings.Contains(err.Error(), "input[2, 4]") {
+		t.Errorf("Expected error to report layer index and shape, found: %s", err)
+	}
+
+	if _, err := layer.NewSequential[float64]().Forward(mat.Ones[float64](2, 4)); err == nil {
+		t.Error("Expected error forwarding empty model, found nil")
+	}
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"bytes"
+	"gonn/demos"
+	"gonn/internal/acti"
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"path/filepath"
+	"testing"
+)
+
+func TestMat2DRoundTrip(t *testing.T) {
+	m := mat.ARange[float32](12).MustReshape(3, 4)
+
+	for name, src := range map[string]*mat.Mat2DF32{
+		"plain":      m,
+		"transposed": m.TP(),
+		"sliced":     m.MustSlice(mat.RS{1, 3}, mat.CS{1, 3}),
+		"sliced^T":   m.MustSlice(mat.RS{0, 2}, mat.CS{1, 4}).TP(),
+	} {
+		var buf bytes.Buffer
+		n, err := src.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("%s: WriteTo reported %d bytes, wrote %d", name, n, buf.Len())
+		}
+
+		loaded, err := mat.ReadMat2D[float32](&buf)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		logIfErr(t, expectMatEq(src, loaded))
+	}
+}
+
+func TestMat2DReadConvertsDType(t *testing.T) {
+	m := mat.FromValues([]float32{0.5, -1.25, 3})
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := mat.ReadMat2D[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.FromValues([]float64{0.5, -1.25, 3}), loaded))
+}
+
+func TestMat2DReadCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := mat.Ones[float64](2, 2).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)-10] ^= 0xff
+	if _, err := mat.ReadMat2D[float64](bytes.NewReader(flipped)); err == nil {
+		t.Error("Expected checksum error, found nil")
+	}
+
+	badMagic := bytes.Clone(data)
+	badMagic[0] = 'X'
+	if _, err := mat.ReadMat2D[float64](bytes.NewReader(badMagic)); err == nil {
+		t.Error("Expected bad magic error, found nil")
+	}
+
+	future := bytes.Clone(data)
+	future[4] = 0xff
+	if _, err := mat.ReadMat2D[float64](bytes.NewReader(future)); err == nil {
+		t.Error("Expected unsupported version error, found nil")
+	}
+
+	if _, err := mat.ReadMat2D[float64](bytes.NewReader(data[:len(data)-6])); err == nil {
+		t.Error("Expected error reading truncated data, found nil")
+	}
+}
+
+func newSavableModel(t *testing.T) *layer.Sequential[float64] {
+	sig1, err := layer.NewALByName[float64](acti.NameSigmoid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relu, err := layer.NewALByName[float64](acti.NameReLU)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return layer.NewSequential[float64](
+		layer.NewLL[float64](3, 4),
+		sig1,
+		layer.NewSequential[float64](layer.NewLL[float64](4, 2), relu),
+	)
+}
+
+func TestModelSaveLoad(t *testing.T) {
+	model := newSavableModel(t)
+
+	var buf bytes.Buffer
+	if err := layer.Save[float64](&buf, model.Layers...); err != nil {
+		t.Fatal(err)
+	}
+
+	layers, err := layer.Load[float64](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := layer.NewSequential(layers...)
+
+	X := mat.ARange[float64](6).MustReshape(3, 2)
+	expected, err := model.Forward(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := loaded.Forward(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(expected, found))
+
+	if len(loaded.Params()) != 2 {
+		t.Errorf("Expected 2 learnable params after load, found %d", len(loaded.Params()))
+	}
+}
+
+func TestXORModelSaveLoad(t *testing.T) {
+	model := demos.NewXORModel()
+
+	var buf bytes.Buffer
+	if err := layer.Save[float32](&buf, model); err != nil {
+		t.Fatal(err)
+	}
+	layers, err := layer.Load[float32](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 {
+		t.Fatalf("Expected 1 top level layer, found %d", len(layers))
+	}
+
+	X := mat.FromValues([]float32{0, 1, 0, 1, 0, 0, 1, 1}).MustReshape(2, 4)
+	expected, err := model.Forward(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := layers[0].Forward(X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(expected, found))
+}
+
+func TestModelSaveLoadFile(t *testing.T) {
+	model := newSavableModel(t)
+	path := filepath.Join(t.TempDir(), "model.gonn")
+
+	if err := layer.SaveFile[float64](path, model); err != nil {
+		t.Fatal(err)
+	}
+
+	// float64 weights loaded into a float32 model
+	layers, err := layer.LoadFile[float32](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 {
+		t.Fatalf("Expected 1 top level layer, found %d", len(layers))
+	}
+	if _, ok := layers[0].(*layer.Sequential[float32]); !ok {
+		t.Errorf("Expected *Sequential, found %T", layers[0])
+	}
+}
+
+func TestModelSaveErrors(t *testing.T) {
+	var buf bytes.Buffer
+	custom := layer.NewSequential[float64](newSigmoidAL())
+	if err := layer.Save[float64](&buf, custom); err == nil {
+		t.Error("Expected error saving activation without a name, found nil")
+	}
+
+	buf.Reset()
+	if err := layer.Save[float64](&buf, layer.NewLL[float64](2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[len(data)-12] ^= 0x01
+	if _, err := layer.Load[float64](bytes.NewReader(data)); err == nil {
+		t.Error("Expected checksum error loading corrupt model, found nil")
+	}
+
+	if _, err := layer.NewALByName[float64]("not-an-activation"); err == nil {
+		t.Error("Expected error for unknown activation, found nil")
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"gonn/internal/acti"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"math"
+	"testing"
+)
+
+func TestSoftmaxColumns(t *testing.T) {
+	x := mat.FromValues([]float64{
+		1, 0, 1000,
+		2, 0, 1000,
+		3, 0, -1000,
+	}).MustReshape(3, 3)
+
+	p := acti.Softmax(x)
+
+	e := math.Exp(1.0) + math.Exp(2.0) + math.Exp(3.0)
+	expected := mat.FromValues([]float64{
+		math.Exp(1) / e, 1.0 / 3, 0.5,
+		math.Exp(2) / e, 1.0 / 3, 0.5,
+		math.Exp(3) / e, 1.0 / 3, 0,
+	}).MustReshape(3, 3)
+	logIfErr(t, expectMatNear(expected, p, 1e-12))
+
+	sums := p.SumRows()
+	logIfErr(t, expectMatNear(mat.Ones[float64](1, 3), sums, 1e-12))
+
+	logp := acti.LogSoftmax(x)
+	if v := logp.MustGet(2, 2); math.IsNaN(v) || math.IsInf(v, 0) || v > -1999 {
+		t.Errorf("Expected finite log softmax of about -2000, found %f", v)
+	}
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	logits := mat.FromValues([]float32{
+		2, 100, 0,
+		1, -100, 0,
+		0.1, 0, 0,
+	}).MustReshape(3, 3)
+	labels := []int{0, 0, 2}
+
+	y, err := lossfuncs.OneHot[float32](labels, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ce, err := lossfuncs.SoftmaxCrossEntropy(y, logits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ceIdx, err := lossfuncs.SoftmaxCrossEntropyIdx(labels, logits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(ce, ceIdx, 1e-6))
+
+	expected := mat.FromValues([]float32{
+		float32(-math.Log(math.Exp(2) / (math.Exp(2) + math.Exp(1) + math.Exp(0.1)))),
+		0,
+		float32(math.Log(3)),
+	})
+	logIfErr(t, expectMatNear(expected, ce, 1e-5))
+
+	dce, err := lossfuncs.DSoftmaxCrossEntropy(y, logits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dceIdx, err := lossfuncs.DSoftmaxCrossEntropyIdx(labels, logits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(dce, dceIdx, 1e-6))
+
+	// softmax - y, columns sum to zero
+	logIfErr(t, expectMatNear(mat.New2DF32(1, 3), dce.SumRows(), 1e-6))
+}
+
+func TestSoftmaxCrossEntropyGradient(t *testing.T) {
+	logits := mat.FromValues([]float64{
+		0.3, -1.2,
+		1.5, 0.4,
+		-0.7, 2.2,
+	}).MustReshape(3, 2)
+	y := mat.FromValues([]float64{
+		0.2, 0,
+		0.5, 1,
+		0.3, 0,
+	}).MustReshape(3, 2)
+
+	grad, err := lossfuncs.DSoftmaxCrossEntropy(y, logits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const eps = 1e-6
+	for i := range logits.Rows() {
+		for j := range logits.Cols() {
+			orig := logits.MustGet(i, j)
+
+			logits.MustSet(i, j, orig+eps)
+			lp, _ := lossfuncs.SoftmaxCrossEntropy(y, logits)
+			logits.MustSet(i, j, orig-eps)
+			lm, _ := lossfuncs.SoftmaxCrossEntropy(y, logits)
+			logits.MustSet(i, j, orig)
+
+			numeric := (lp.Sum() - lm.Sum()) / (2 * eps)
+			if err := expectNear(numeric, grad.MustGet(i, j), 1e-6); err != nil {
+				t.Errorf("dlogits[%d, %d]: %s", i, j, err)
+			}
+		}
+	}
+}
+
+func TestSoftmaxCrossEntropyErrors(t *testing.T) {
+	logits := mat.New2DF32(3, 2)
+
+	if _, err := lossfuncs.SoftmaxCrossEntropy(mat.New2DF32(2, 2), logits); err == nil {
+		t.Error("Expected mismatched dims error, found nil")
+	}
+	if _, err := lossfuncs.SoftmaxCrossEntropyIdx([]int{0}, logits); err == nil {
+		t.Error("Expected label count error, found nil")
+	}
+	if _, err := lossfuncs.DSoftmaxCrossEntropyIdx([]int{0, 3}, logits); err == nil {
+		t.Error("Expected label range error, found nil")
+	}
+	if _, err := lossfuncs.OneHot[float32]([]int{-1}, 3); err == nil {
+		t.Error("Expected one-hot range error, found nil")
+	}
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"cmp"
+	"errors"
+	"gonn/internal/mat"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// U * Sigma * V^T
+func reconstructSVD[T mat.Float](dec *mat.SVD[T]) *mat.Mat2D[T] {
+	return mat.MustMatMul(mat.MustMatMul(dec.U, dec.Sigma()), dec.V.TP())
+}
+
+func expectOrthonormalCols(t *testing.T, name string, m *mat.Mat2DF64, tol float64) {
+	t.Helper()
+	if err := expectMatNear(mat.Id[float64](uint64(m.Cols())), mat.MustMatMul(m.TP(), m), tol); err != nil {
+		t.Errorf("Expected %s with orthonormal columns, %s", name, err)
+	}
+}
+
+func TestSVDKnown(t *testing.T) {
+	// singular values are the absolute diagonal, sorted
+	a := mat.FromValues([]float64{
+		2, 0, 0,
+		0, -5, 0,
+		0, 0, 3,
+	}).MustReshape(3, 3)
+	dec, err := mat.ThinSVD(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{5, 3, 2}), mat.FromValues(dec.S), 1e-12))
+	logIfErr(t, expectMatNear(a, reconstructSVD(dec), 1e-12))
+
+	// [[3, 0], [4, 5]] has singular values sqrt(45) and sqrt(5)
+	dec, err = mat.ThinSVD(mat.FromValues([]float64{3, 0, 4, 5}).MustReshape(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{math.Sqrt(45), math.Sqrt(5)}), mat.FromValues(dec.S), 1e-12))
+
+	// rank one outer product u * v^T: one singular value |u| * |v|
+	u := mat.FromValues([]float64{1, 2, 2}).TP()
+	v := mat.FromValues([]float64{3, 4})
+	dec, err = mat.FullSVD(mat.MustMatMul(u, v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{15, 0}), mat.FromValues(dec.S), 1e-12))
+	logIfErr(t, expectMatNear(mat.MustMatMul(u, v), reconstructSVD(dec), 1e-12))
+	expectOrthonormalCols(t, "U", dec.U, 1e-12)
+	expectOrthonormalCols(t, "V", dec.V, 1e-12)
+}
+
+func TestSVDProperties(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	low := mat.MustMatMul(mat.Normal[float64](rng, 6, 2, 0, 1), mat.Normal[float64](rng, 2, 4, 0, 1))
+	for name, a := range map[string]*mat.Mat2DF64{
+		"square":     mat.Normal[float64](rng, 5, 5, 0, 1),
+		"tall":       mat.Normal[float64](rng, 7, 3, 0, 1),
+		"wide":       mat.Normal[float64](rng, 2, 6, 0, 1),
+		"transposed": mat.Normal[float64](rng, 6, 4, 0, 1).TP(),
+		"rank 2":     low,
+		"zero":       mat.New2DF64(3, 2),
+	} {
+		m, n := uint64(a.Rows()), uint64(a.Cols())
+		k := min(m, n)
+
+		thin, err := mat.ThinSVD(a)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		full, err := mat.FullSVD(a)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if thin.U.Rows() != int64(m) || thin.U.Cols() != int64(k) || thin.V.Rows() != int64(n) || thin.V.Cols() != int64(k) {
+			t.Errorf("%s: expected thin U[%d, %d] V[%d, %d], found U%v V%v", name, m, k, n, k, thin.U, thin.V)
+		}
+		if full.U.Cols() != int64(m) || full.V.Cols() != int64(n) || len(full.S) != int(k) {
+			t.Errorf("%s: expected full U[%d, %d] V[%d, %d] and %d values", name, m, m, n, n, k)
+		}
+
+		for _, dec := range []*mat.SVD[float64]{thin, full} {
+			logIfErr(t, expectMatNear(a, reconstructSVD(dec), 1e-12))
+			expectOrthonormalCols(t, name+" U", dec.U, 1e-12)
+			expectOrthonormalCols(t, name+" V", dec.V, 1e-12)
+			if !slices.IsSortedFunc(dec.S, func(x, y float64) int { return cmp.Compare(y, x) }) {
+				t.Errorf("%s: expected descending singular values, found %v", name, dec.S)
+			}
+			for _, s := range dec.S {
+				if s < 0 {
+					t.Errorf("%s: negative singular value %f", name, s)
+				}
+			}
+		}
+		logIfErr(t, expectMatNear(mat.FromValues(thin.S), mat.FromValues(full.S), 1e-12))
+	}
+
+	// float32 goes through float64
+	a32 := mat.Normal[float32](rng, 4, 3, 0, 1)
+	dec32, err := mat.ThinSVD(a32)
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(a32, reconstructSVD(dec32), 1e-5))
+}
+
+func TestSymEig(t *testing.T) {
+	// [[2, 1], [1, 2]]: 3 along (1, 1), 1 along (1, -1)
+	eig, err := mat.SymEig(mat.FromValues([]float64{2, 1, 1, 2}).MustReshape(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{3, 1}), mat.FromValues(eig.Values), 1e-12))
+	r := 1 / math.Sqrt2
+	v0 := eig.Vectors.MustSlice(mat.RS{0, 2}, mat.CS{0, 1})
+	if math.Abs(math.Abs(v0.MustGet(0, 0))-r) > 1e-12 || v0.MustGet(0, 0) != v0.MustGet(1, 0) {
+		t.Errorf("Expected the first eigenvector along (1, 1), found %v", v0)
+	}
+
+	rng := rand.New(rand.NewSource(6))
+	for _, n := range []uint64{1, 3, 8} {
+		m := mat.Normal[float64](rng, n, n, 0, 1)
+		a := mat.MustAdd(m, m.TP()) // symmetric, indefinite
+
+		eig, err := mat.SymEig(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectOrthonormalCols(t, "Vectors", eig.Vectors, 1e-12)
+
+		D := mat.New2DF64(n, n)
+		for i, v := range eig.Values {
+			D.MustSet(int64(i), int64(i), v)
+		}
+		logIfErr(t, expectMatNear(a, mat.MustMatMul(mat.MustMatMul(eig.Vectors, D), eig.Vectors.TP()), 1e-11))
+
+		// singular values of a symmetric matrix are its absolute eigenvalues
+		dec, err := mat.ThinSVD(a)
+		logIfErr(t, err)
+		abs := make([]float64, n)
+		for i, v := range eig.Values {
+			abs[i] = math.Abs(v)
+		}
+		slices.SortFunc(abs, func(x, y float64) int { return cmp.Compare(y, x) })
+		logIfErr(t, expectMatNear(mat.FromValues(abs), mat.FromValues(dec.S), 1e-11))
+	}
+
+	if _, err := mat.SymEig(mat.FromValues([]float64{1, 2, 3, 4}).MustReshape(2, 2)); !errors.Is(err, mat.ErrNotSymmetric) {
+		t.Errorf("Expected ErrNotSymmetric, found %v", err)
+	}
+	if _, err := mat.SymEig(mat.Ones[float64](2, 3)); !errors.Is(err, mat.ErrNotSquare) {
+		t.Errorf("Expected ErrNotSquare, found %v", err)
+	}
+}
+
+func TestPinvAndRank(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+
+	low := mat.MustMatMul(mat.Normal[float64](rng, 5, 2, 0, 1), mat.Normal[float64](rng, 2, 4, 0, 1))
+	for _, tc := range []struct {
+		a    *mat.Mat2DF64
+		rank int
+	}{
+		{mat.Normal[float64](rng, 4, 4, 0, 1), 4},
+		{mat.Normal[float64](rng, 6, 3, 0, 1), 3},
+		{mat.Normal[float64](rng, 2, 5, 0, 1), 2},
+		{low, 2},
+		{mat.New2DF64(3, 3), 0},
+	} {
+		rank, err := mat.Rank(tc.a)
+		logIfErr(t, err)
+		if rank != tc.rank {
+			t.Errorf("Expected rank %d, found %d", tc.rank, rank)
+		}
+
+		// the Moore-Penrose conditions
+		P, err := mat.Pinv(tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AP, PA := mat.MustMatMul(tc.a, P), mat.MustMatMul(P, tc.a)
+		logIfErr(t, expectMatNear(tc.a, mat.MustMatMul(AP, tc.a), 1e-10))
+		logIfErr(t, expectMatNear(P, mat.MustMatMul(PA, P), 1e-10))
+		logIfErr(t, expectMatNear(AP, AP.TP(), 1e-10))
+		logIfErr(t, expectMatNear(PA, PA.TP(), 1e-10))
+	}
+
+	// equals the inverse of an invertible matrix
+	a := randSquare(rng, 4)
+	inv, err := mat.Inverse(a)
+	logIfErr(t, err)
+	P, err := mat.Pinv(a)
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(inv, P, 1e-12))
+
+	// and gives the least squares solution of a tall system
+	A := mat.Normal[float64](rng, 8, 3, 0, 1)
+	b := mat.Normal[float64](rng, 8, 1, 0, 1)
+	qr, err := mat.QRDecompose(A)
+	logIfErr(t, err)
+	expected, err := qr.Solve(b)
+	logIfErr(t, err)
+	P, err = mat.Pinv(A)
+	logIfErr(t, err)
+	logIfErr(t, expectMatNear(expected, mat.MustMatMul(P, b), 1e-12))
+}
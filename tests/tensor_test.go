@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"gonn/internal/mat"
+	"slices"
+	"testing"
+)
+
+func arangeTensor(shape ...uint64) *mat.TensorF32 {
+	size := uint64(1)
+	for _, dim := range shape {
+		size *= dim
+	}
+
+	values := make([]float32, size)
+	for i := range values {
+		values[i] = float32(i)
+	}
+
+	ts, err := mat.TensorFromValues(values, shape...)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
+func TestTensorGetSet(t *testing.T) {
+	ts := arangeTensor(2, 3, 4)
+
+	if ts.Rank() != 3 || ts.Size() != 24 {
+		t.Fatalf("Expected rank 3 size 24, found rank %d size %d", ts.Rank(), ts.Size())
+	}
+	if v := ts.MustGet(1, 2, 3); v != 23 {
+		t.Errorf("Expected 23 at [1, 2, 3], found %f", v)
+	}
+	if v := ts.MustGet(-1, 0, -1); v != 15 {
+		t.Errorf("Expected 15 at [-1, 0, -1], found %f", v)
+	}
+
+	ts.MustSet(100, 0, 1, 2)
+	if v := ts.MustGet(0, 1, 2); v != 100 {
+		t.Errorf("Expected 100 at [0, 1, 2], found %f", v)
+	}
+
+	if _, err := ts.Get(2, 0, 0); err == nil {
+		t.Error("Expected out of bounds error, found nil")
+	}
+	if _, err := ts.Get(0, 0); err == nil {
+		t.Error("Expected rank mismatch error, found nil")
+	}
+	if _, err := mat.TensorFromValues([]float32{1, 2, 3}, 2, 2); err == nil {
+		t.Error("Expected size mismatch error, found nil")
+	}
+}
+
+func TestTensorPermute(t *testing.T) {
+	ts := arangeTensor(2, 3, 4)
+	p := ts.MustPermute(2, 0, 1)
+
+	if !slices.Equal(p.Shape(), []uint64{4, 2, 3}) {
+		t.Fatalf("Expected shape [4 2 3], found %v", p.Shape())
+	}
+	for i := range int64(2) {
+		for j := range int64(3) {
+			for k := range int64(4) {
+				if ts.MustGet(i, j, k) != p.MustGet(k, i, j) {
+					t.Fatalf("Permuted value mismatch at [%d, %d, %d]", i, j, k)
+				}
+			}
+		}
+	}
+
+	// views share memory
+	p.MustSet(-1, 3, 1, 2)
+	if ts.MustGet(1, 2, 3) != -1 {
+		t.Error("Expected permuted view to share values")
+	}
+
+	if _, err := ts.Permute(0, 0, 1); err == nil {
+		t.Error("Expected invalid permutation error, found nil")
+	}
+}
+
+func TestTensorReshape(t *testing.T) {
+	ts := arangeTensor(2, 3, 4)
+
+	r := ts.MustReshape(6, 4)
+	if v := r.MustGet(5, 3); v != 23 {
+		t.Errorf("Expected 23, found %f", v)
+	}
+	r.MustSet(-5, 0, 0)
+	if ts.MustGet(0, 0, 0) != -5 {
+		t.Error("Expected reshape of contiguous tensor to be a view")
+	}
+
+	// non contiguous reshape copies
+	p := ts.MustPermute(1, 0, 2)
+	pr := p.MustReshape(24)
+	expected := []float32{-5, 1, 2, 3, 12, 13, 14, 15}
+	for i, e := range expected {
+		if v := pr.MustGet(int64(i)); v != e {
+			t.Errorf("Expected %f at %d, found %f", e, i, v)
+		}
+	}
+
+	if _, err := ts.Reshape(5, 5); err == nil {
+		t.Error("Expected invalid reshape error, found nil")
+	}
+}
+
+func TestTensorSliceExpand(t *testing.T) {
+	ts := arangeTensor(2, 3, 4)
+
+	s := ts.MustSlice(mat.SR{1, 2}, mat.SR{0, 3}, mat.SR{1, 3})
+	if !slices.Equal(s.Shape(), []uint64{1, 3, 2}) {
+		t.Fatalf("Expected shape [1 3 2], found %v", s.Shape())
+	}
+	if v := s.MustGet(0, 2, 1); v != 22 {
+		t.Errorf("Expected 22, found %f", v)
+	}
+	if s.IsContiguous() {
+		t.Error("Expected sliced view to be non contiguous")
+	}
+
+	col := arangeTensor(3, 1)
+	e := col.MustExpand(2, 3, 4)
+	if !slices.Equal(e.Shape(), []uint64{2, 3, 4}) {
+		t.Fatalf("Expected shape [2 3 4], found %v", e.Shape())
+	}
+	if v := e.MustGet(1, 2, 3); v != 2 {
+		t.Errorf("Expected 2, found %f", v)
+	}
+
+	if _, err := col.Expand(2, 4, 4); err == nil {
+		t.Error("Expected invalid expand error, found nil")
+	}
+	if _, err := ts.Slice(mat.SR{0, 3}); err == nil {
+		t.Error("Expected out of bounds slice error, found nil")
+	}
+}
+
+func TestTensorMat2DInterop(t *testing.T) {
+	m := mat.ARange[float32](6).MustReshape(2, 3)
+
+	for name, src := range map[string]*mat.Mat2DF32{
+		"plain":      m,
+		"transposed": m.TP(),
+		"sliced":     m.MustSlice(mat.RS{0, 2}, mat.CS{1, 3}),
+	} {
+		ts := mat.TensorFromMat2D(src)
+		back := ts.MustToMat2D()
+		logIfErr(t, expectMatEq(src, back))
+
+		for i := range src.Rows() {
+			for j := range src.Cols() {
+				if src.MustGet(i, j) != ts.MustGet(i, j) {
+					t.Errorf("%s: mismatch at [%d, %d]", name, i, j)
+				}
+			}
+		}
+	}
+
+	// permuting back into a Mat2D yields a transposed view sharing values
+	tp := mat.TensorFromMat2D(m).MustPermute(1, 0).MustToMat2D()
+	logIfErr(t, expectMatEq(m.TP(), tp))
+	tp.MustSet(0, 1, -1)
+	if m.MustGet(1, 0) != -1 {
+		t.Error("Expected ToMat2D of a permuted tensor to share values")
+	}
+
+	if _, err := arangeTensor(2, 2, 2).ToMat2D(); err == nil {
+		t.Error("Expected rank error converting rank 3 tensor, found nil")
+	}
+
+	row := arangeTensor(4).MustToMat2D()
+	logIfErr(t, expectMatEq(mat.ARange[float32](4), row))
+}
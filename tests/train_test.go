@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonn/internal/data"
+	"gonn/internal/layer"
+	"gonn/internal/lossfuncs"
+	"gonn/internal/mat"
+	"gonn/internal/optim"
+	"gonn/internal/train"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// X[1, n] evenly spaced in [-1, 1], Y = 2X + 1
+func newLineDataset(t *testing.T, n int) *data.MatDataset[float64] {
+	t.Helper()
+	X := mat.New2DF64(1, uint64(n))
+	Y := mat.New2DF64(1, uint64(n))
+	for j := range int64(n) {
+		x := -1 + 2*float64(j)/float64(n-1)
+		X.MustSet(0, j, x)
+		Y.MustSet(0, j, 2*x+1)
+	}
+	ds, err := data.NewMatDataset(X, Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+func newLineTrainer(t *testing.T, model layer.Layer[float64]) *train.Trainer[float64] {
+	t.Helper()
+	trainer, err := train.NewTrainer(model, lossfuncs.SquaredErrorLoss[float64](), optim.NewSGD[float64](0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainer.BatchSize = 4
+	trainer.RNG = rand.New(rand.NewSource(1))
+	return trainer
+}
+
+// counts the samples it saw, and whether the model was training meanwhile
+type countMetric struct {
+	model   layer.ModeSetter
+	n       int
+	inModes []bool
+}
+
+func (m *countMetric) Name() string { return "count" }
+func (m *countMetric) Reset()       { m.n = 0 }
+func (m *countMetric) Value() float64 {
+	return float64(m.n)
+}
+func (m *countMetric) Update(y, y_ *mat.Mat2DF64) error {
+	m.n += int(y.Cols())
+	m.inModes = append(m.inModes, m.model.IsTraining())
+	return nil
+}
+
+func TestTrainerFitsLine(t *testing.T) {
+	ll := layer.NewLL[float64](1, 1)
+	trainer := newLineTrainer(t, ll)
+
+	history, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	losses := history.Series("loss")
+	if len(history) != 50 || len(losses) != 50 {
+		t.Fatalf("Expected 50 epochs of logs, found %d", len(losses))
+	}
+	if losses[49] > 1e-6 || losses[49] >= losses[0] {
+		t.Errorf("Expected the loss to decrease to ~0, found %f -> %f", losses[0], losses[49])
+	}
+	logIfErr(t, expectMatNear(mat.FromValues([]float64{1, 2}).MustReshape(1, 2), ll.W, 1e-3))
+
+	logs, err := trainer.Evaluate(context.Background(), newLineDataset(t, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectNear(0, logs["loss"], 1e-6))
+}
+
+func TestTrainerCallbackOrder(t *testing.T) {
+	trainer := newLineTrainer(t, layer.NewLL[float64](1, 1))
+
+	var events []string
+	trainer.Callbacks = []train.Callback{train.CallbackFuncs{
+		EpochStart: func(epoch int) error {
+			events = append(events, fmt.Sprintf("E%d", epoch))
+			return nil
+		},
+		BatchStart: func(epoch, batch int) error {
+			events = append(events, fmt.Sprintf("b%d", batch))
+			return nil
+		},
+		BatchEnd: func(epoch, batch int, logs train.Logs) error {
+			if _, ok := logs["loss"]; !ok {
+				t.Errorf("Batch logs without loss: %v", logs)
+			}
+			events = append(events, fmt.Sprintf("/b%d", batch))
+			return nil
+		},
+		EpochEnd: func(epoch int, logs train.Logs) error {
+			events = append(events, fmt.Sprintf("/E%d", epoch))
+			return nil
+		},
+	}}
+
+	if _, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "E0 b0 /b0 b1 /b1 b2 /b2 /E0 E1 b0 /b0 b1 /b1 b2 /b2 /E1"
+	if found := strings.Join(events, " "); found != expected {
+		t.Errorf("Expected events %q, found %q", expected, found)
+	}
+}
+
+func TestTrainerValidationSplit(t *testing.T) {
+	model := layer.NewSequential[float64](layer.NewLL[float64](1, 1))
+	trainer := newLineTrainer(t, model)
+	trainer.ValidationSplit = 0.3
+
+	metric := &countMetric{model: model}
+	trainer.Metrics = []train.Metric[float64]{metric}
+
+	model.SetTraining(false)
+	history, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for epoch, logs := range history {
+		if logs["count"] != 7 || logs["val_count"] != 3 {
+			t.Errorf("Epoch %d: expected 7 training and 3 validation samples, found %v", epoch, logs)
+		}
+		if _, ok := logs["val_loss"]; !ok {
+			t.Errorf("Epoch %d: missing val_loss in %v", epoch, logs)
+		}
+	}
+
+	// each epoch: 2 training batches in training mode, 1 validation batch in inference
+	expectedModes := []bool{true, true, false, true, true, false, true, true, false}
+	if !slices.Equal(metric.inModes, expectedModes) {
+		t.Errorf("Expected modes %v, found %v", expectedModes, metric.inModes)
+	}
+	if model.IsTraining() {
+		t.Errorf("Expected Fit to restore the inference mode")
+	}
+}
+
+func TestTrainerStopAndCancel(t *testing.T) {
+	trainer := newLineTrainer(t, layer.NewLL[float64](1, 1))
+	trainer.Callbacks = []train.Callback{train.CallbackFuncs{
+		EpochEnd: func(epoch int, logs train.Logs) error {
+			if epoch == 1 {
+				return train.ErrStopTraining
+			}
+			return nil
+		},
+	}}
+
+	history, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 5)
+	if err != nil || len(history) != 2 {
+		t.Errorf("Expected to stop cleanly after 2 epochs, found %d epochs, err %v", len(history), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := 0
+	trainer.Callbacks = []train.Callback{train.CallbackFuncs{
+		BatchEnd: func(epoch, batch int, logs train.Logs) error {
+			batches++
+			if epoch == 1 && batch == 0 {
+				cancel()
+			}
+			return nil
+		},
+	}}
+
+	history, err = trainer.Fit(ctx, newLineDataset(t, 10), 5)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, found %v", err)
+	}
+	if len(history) != 1 || batches != 4 {
+		t.Errorf("Expected to stop after 1 epoch and 4 batches, found %d epochs, %d batches", len(history), batches)
+	}
+}
+
+func TestTrainerErrors(t *testing.T) {
+	if _, err := train.NewTrainer[float64](nil, lossfuncs.SquaredErrorLoss[float64](), optim.NewSGD[float64](0.1)); err == nil {
+		t.Errorf("Expected error for a nil model")
+	}
+	if _, err := train.NewTrainer(layer.NewLL[float64](1, 1), lossfuncs.Loss[float64]{}, optim.NewSGD[float64](0.1)); err == nil {
+		t.Errorf("Expected error for a loss without functions")
+	}
+
+	// model expects 2 features, data has 1
+	trainer := newLineTrainer(t, layer.NewLL[float64](2, 1))
+	if _, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 1); err == nil {
+		t.Errorf("Expected error for mismatched model and data")
+	}
+
+	trainer = newLineTrainer(t, layer.NewLL[float64](1, 1))
+	trainer.ValidationSplit = 0.01
+	if _, err := trainer.Fit(context.Background(), newLineDataset(t, 10), 1); err == nil {
+		t.Errorf("Expected error for a split with an empty validation set")
+	}
+}
+
+func TestDataSplit(t *testing.T) {
+	ds := newRangeDataset(t, 10)
+	trainSet, valSet, err := data.Split[float64](ds, 0.25, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trainSet.Len() != 7 || valSet.Len() != 3 {
+		t.Fatalf("Expected a 7/3 split, found %d/%d", trainSet.Len(), valSet.Len())
+	}
+
+	seen := append(slices.Clone(trainSet.Indexes), valSet.Indexes...)
+	slices.Sort(seen)
+	if !slices.Equal(seen, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("Expected disjoint subsets covering the dataset, found %v", seen)
+	}
+
+	_, y, err := valSet.Get(0)
+	if err != nil || y.MustGet(0, 0) != float64(valSet.Indexes[0]) {
+		t.Errorf("Expected subset sample 0 to be dataset sample %d", valSet.Indexes[0])
+	}
+
+	for _, fraction := range []float64{0, 1, -0.5} {
+		if _, _, err := data.Split[float64](ds, fraction, nil); err == nil {
+			t.Errorf("Expected error for fraction %v", fraction)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"gonn/internal/layer"
+	"gonn/internal/mat"
+	"gonn/internal/weightinit"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func moments(m *mat.Mat2DF64) (mean, std, maxAbs float64) {
+	n := float64(m.Rows() * m.Cols())
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			v := m.MustGet(i, j)
+			mean += v / n
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+	}
+	for i := range m.Rows() {
+		for j := range m.Cols() {
+			d := m.MustGet(i, j) - mean
+			std += d * d / n
+		}
+	}
+	return mean, math.Sqrt(std), maxAbs
+}
+
+func TestInitializerScales(t *testing.T) {
+	const fanOut, fanIn = 200, 300
+
+	for name, tc := range map[string]struct {
+		init  weightinit.Initializer[float64]
+		std   float64
+		limit float64 // 0 for unbounded
+	}{
+		"XavierUniform": {weightinit.XavierUniform[float64], math.Sqrt(2.0 / 500), math.Sqrt(6.0 / 500)},
+		"XavierNormal":  {weightinit.XavierNormal[float64], math.Sqrt(2.0 / 500), 0},
+		"HeUniform":     {weightinit.HeUniform[float64], math.Sqrt(2.0 / fanIn), math.Sqrt(6.0 / fanIn)},
+		"HeNormal":      {weightinit.HeNormal[float64], math.Sqrt(2.0 / fanIn), 0},
+		"LeCunUniform":  {weightinit.LeCunUniform[float64], math.Sqrt(1.0 / fanIn), math.Sqrt(3.0 / fanIn)},
+		"LeCunNormal":   {weightinit.LeCunNormal[float64], math.Sqrt(1.0 / fanIn), 0},
+	} {
+		w := mat.New2DF64(fanOut, fanIn)
+		tc.init(w, weightinit.FansOf(w), nil)
+
+		mean, std, maxAbs := moments(w)
+		if math.Abs(mean) > 0.05*tc.std {
+			t.Errorf("%s: expected mean near 0, found %f", name, mean)
+		}
+		if math.Abs(std-tc.std) > 0.05*tc.std {
+			t.Errorf("%s: expected std %f, found %f", name, tc.std, std)
+		}
+		if tc.limit > 0 && maxAbs > tc.limit {
+			t.Errorf("%s: expected values within ±%f, found %f", name, tc.limit, maxAbs)
+		}
+	}
+}
+
+func TestOrthogonal(t *testing.T) {
+	for _, shape := range [][2]uint64{{4, 4}, {3, 6}, {6, 3}} {
+		w := mat.New2DF64(shape[0], shape[1])
+		weightinit.Orthogonal[float64](2)(w, weightinit.FansOf(w), nil)
+
+		// the short side is orthogonal with norm gain
+		gram := mat.MustMatMul(w, w.TP())
+		if shape[0] > shape[1] {
+			gram = mat.MustMatMul(w.TP(), w)
+		}
+		expected := mat.Id[float64](uint64(gram.Rows())).Scale(4)
+		logIfErr(t, expectMatNear(expected, gram, 1e-9))
+	}
+}
+
+func TestNewLLWithInit(t *testing.T) {
+	ll := layer.NewLLWithInit(50, 40, weightinit.HeNormal[float64], weightinit.Constant(0.5), nil)
+
+	if ll.W.Rows() != 40 || ll.W.Cols() != 51 {
+		t.Fatalf("Expected W[40, 51], found [%d, %d]", ll.W.Rows(), ll.W.Cols())
+	}
+	logIfErr(t, expectMatEq(
+		mat.Ones[float64](40, 1).Scale(0.5),
+		ll.W.MustSlice(mat.RS{0, 40}, mat.CS{0, 1}),
+	))
+
+	_, std, _ := moments(ll.W.MustSlice(mat.RS{0, 40}, mat.CS{1, 51}))
+	if math.Abs(std-math.Sqrt(2.0/50)) > 0.1*math.Sqrt(2.0/50) {
+		t.Errorf("Expected He std %f for fanIn 50, found %f", math.Sqrt(2.0/50), std)
+	}
+
+	// fanIn = C*KH*KW = 36, fanOut = OC*KH*KW = 144
+	cl, err := layer.NewConv2DWithInit(
+		mat.ConvGeom{Channels: 4, Height: 4, Width: 4, KernelH: 3, KernelW: 3},
+		16, weightinit.XavierUniform[float64], weightinit.Zeros[float64], nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logIfErr(t, expectMatEq(mat.New2DF64(16, 1), cl.W.MustSlice(mat.RS{0, 16}, mat.CS{0, 1})))
+
+	_, std, maxAbs := moments(cl.W.MustSlice(mat.RS{0, 16}, mat.CS{1, 37}))
+	if limit := math.Sqrt(6.0 / 180); maxAbs > limit || math.Abs(std-limit/math.Sqrt(3)) > 0.1*limit/math.Sqrt(3) {
+		t.Errorf("Expected Xavier within ±%f, std %f, found max %f, std %f", limit, limit/math.Sqrt(3), maxAbs, std)
+	}
+}
+
+func TestInitPerBlock(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// every block is its own layer: std sqrt(2 / (fanIn + fanOut)) with fanOut the block's rows
+	mha, err := layer.NewMultiHeadAttention[float64](64, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mha.Init(weightinit.XavierNormal[float64], weightinit.Zeros[float64], rng)
+	for k := range int64(4) {
+		_, std, _ := moments(mha.W.MustSlice(mat.RS{k * 64, (k + 1) * 64}, mat.CS{1, 65}))
+		if expected := math.Sqrt(2.0 / 128); math.Abs(std-expected) > 0.1*expected {
+			t.Errorf("MultiHeadAttention projection %d: expected std %f, found %f", k, expected, std)
+		}
+	}
+
+	lstm := layer.NewLSTM[float64](30, 20)
+	lstm.Init(weightinit.XavierNormal[float64], weightinit.Zeros[float64], rng)
+	for g := range lstm.W.Rows() / 20 {
+		_, std, _ := moments(lstm.W.MustSlice(mat.RS{g * 20, (g + 1) * 20}, mat.CS{1, 51}))
+		if expected := math.Sqrt(2.0 / 70); math.Abs(std-expected) > 0.1*expected {
+			t.Errorf("LSTM gate %d: expected std %f, found %f", g, expected, std)
+		}
+	}
+}